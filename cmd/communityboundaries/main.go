@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/interactions/calgaryopendata"
+	"github.com/jeffadavidson/development-bot/objects/community"
+	"github.com/jeffadavidson/development-bot/utilities/exit"
+)
+
+func main() {
+	codes := flag.String("codes", "", "The community codes to copy boundaries for, such as KIL,RIC, every community without any")
+	output := flag.String("output", "objects/community/boundaries/communities.geojson", "The boundaries file to write")
+	baseURL := flag.String("base-url", calgaryopendata.DefaultBaseURL, "The Socrata site the Community District Boundaries dataset is on")
+	flag.Parse()
+
+	calgaryopendata.SetBaseURL(*baseURL)
+	var wanted []string
+	for _, code := range strings.Split(*codes, ",") {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			wanted = append(wanted, code)
+		}
+	}
+
+	ctx := context.Background()
+	version, err := calgaryopendata.GetDatasetUpdated(ctx, calgaryopendata.CommunityBoundariesDataset)
+	if err != nil {
+		exit.ExitError(err)
+	}
+	geojson, err := calgaryopendata.GetCommunityBoundaries(ctx, wanted)
+	if err != nil {
+		exit.ExitError(err)
+	}
+	content, err := community.BuildBoundaries(geojson, wanted, community.Source{
+		Title:   "Community District Boundaries",
+		Dataset: calgaryopendata.DatasetURL(calgaryopendata.CommunityBoundariesDataset),
+		Version: &version,
+	})
+	if err != nil {
+		exit.ExitError(err)
+	}
+
+	if err := os.WriteFile(*output, content, 0644); err != nil {
+		exit.ExitError(err)
+	}
+	copied := "every community"
+	if len(wanted) > 0 {
+		copied = strings.Join(wanted, ", ")
+	}
+	fmt.Printf("Wrote the boundaries of %s from %s, version %s, to %s\n", copied,
+		calgaryopendata.DatasetURL(calgaryopendata.CommunityBoundariesDataset), version.Format("2006-01-02"), *output)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/fakesocrata"
+	"github.com/jeffadavidson/development-bot/utilities/exit"
+)
+
+func main() {
+	scenarioPath := flag.String("scenario", "interactions/fakesocrata/testdata/lifecycle.json", "The scenario of datasets and daily changes to serve")
+	address := flag.String("address", ":8090", "The address to serve on")
+	keepDates := flag.Bool("keep-dates", false, "Serve the scenario's dates as written instead of moving day 0 to today")
+	flag.Parse()
+
+	scenario, err := fakesocrata.LoadScenario(*scenarioPath)
+	if err != nil {
+		exit.ExitError(err)
+	}
+	// Moved to today so the rows fall inside the bot's 3 month window
+	if !*keepDates {
+		scenario = scenario.MoveTo(time.Now())
+	}
+
+	fake := fakesocrata.New(scenario)
+	server := &http.Server{Addr: *address, Handler: fake, ReadHeaderTimeout: 10 * time.Second}
+
+	fmt.Printf("Serving %s on %s, day 0 is %s\n", *scenarioPath, *address, fake.Now().Format("2006-01-02"))
+	fmt.Printf("Set open-data base-url to http://localhost%s and POST /advance to move to the next day\n", *address)
+	if err := server.ListenAndServe(); err != nil {
+		exit.ExitError(err)
+	}
+}
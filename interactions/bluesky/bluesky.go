@@ -0,0 +1,125 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+)
+
+// Account - a Bluesky account, signed in with an app password
+type Account struct {
+	ServiceURL  string
+	Handle      string
+	AppPassword string
+}
+
+// Session - a signed in session used to create posts
+type Session struct {
+	serviceURL string
+	AccessJwt  string `json:"accessJwt"`
+	DID        string `json:"did"`
+}
+
+// post - an app.bsky.feed.post record
+type post struct {
+	Type      string  `json:"$type"`
+	Text      string  `json:"text"`
+	CreatedAt string  `json:"createdAt"`
+	Facets    []facet `json:"facets,omitempty"`
+}
+
+// facet - marks a byte range of the post text as rich text, such as a link
+type facet struct {
+	Index    facetIndex     `json:"index"`
+	Features []facetFeature `json:"features"`
+}
+
+type facetIndex struct {
+	ByteStart int `json:"byteStart"`
+	ByteEnd   int `json:"byteEnd"`
+}
+
+type facetFeature struct {
+	Type string `json:"$type"`
+	URI  string `json:"uri"`
+}
+
+// createRecordRequest - the body of a com.atproto.repo.createRecord request
+type createRecordRequest struct {
+	Repo       string `json:"repo"`
+	Collection string `json:"collection"`
+	Record     post   `json:"record"`
+}
+
+// SignIn - Creates a session for the account
+func SignIn(account Account) (*Session, error) {
+	body, err := json.Marshal(map[string]string{"identifier": account.Handle, "password": account.AppPassword})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Bluesky sign in: %v", err)
+	}
+
+	serviceURL := strings.TrimSuffix(account.ServiceURL, "/")
+	response, err := simplehttp.SimplePost(serviceURL+"/xrpc/com.atproto.server.createSession", map[string]string{"Content-Type": "application/json"}, body)
+	if err != nil {
+		return nil, fmt.Errorf("error signing in to Bluesky. Error: %s", err.Error())
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("error signing in to Bluesky. Http Status %d", response.StatusCode)
+	}
+
+	session := Session{serviceURL: serviceURL}
+	if err := json.Unmarshal(response.Body, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse Bluesky session: %v", err)
+	}
+	if session.AccessJwt == "" || session.DID == "" {
+		return nil, fmt.Errorf("Bluesky sign in did not return a session")
+	}
+
+	return &session, nil
+}
+
+// Post - Creates a post, making every occurrence of the link clickable
+func (s *Session) Post(text string, link string, createdAt time.Time) error {
+	record := post{
+		Type:      "app.bsky.feed.post",
+		Text:      text,
+		CreatedAt: createdAt.UTC().Format(time.RFC3339),
+	}
+	// Bluesky does not detect links itself, facets index them by UTF-8 byte offsets
+	if link != "" {
+		for offset := 0; ; {
+			index := strings.Index(text[offset:], link)
+			if index < 0 {
+				break
+			}
+			start := offset + index
+			record.Facets = append(record.Facets, facet{
+				Index:    facetIndex{ByteStart: start, ByteEnd: start + len(link)},
+				Features: []facetFeature{{Type: "app.bsky.richtext.facet#link", URI: link}},
+			})
+			offset = start + len(link)
+		}
+	}
+
+	body, err := json.Marshal(createRecordRequest{Repo: s.DID, Collection: "app.bsky.feed.post", Record: record})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Bluesky post: %v", err)
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + s.AccessJwt,
+		"Content-Type":  "application/json",
+	}
+	response, err := simplehttp.SimplePost(s.serviceURL+"/xrpc/com.atproto.repo.createRecord", headers, body)
+	if err != nil {
+		return fmt.Errorf("error creating Bluesky post. Error: %s", err.Error())
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("error creating Bluesky post. Http Status %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakePDS - a fake Bluesky server that accepts one account and records created posts
+func newFakePDS(t *testing.T) (*httptest.Server, *[]createRecordRequest) {
+	var created []createRecordRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /xrpc/com.atproto.server.createSession", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["identifier"] != "killarney.example.com" || body["password"] != "app-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"accessJwt": "jwt-123", "did": "did:plc:abc123"}`))
+	})
+	mux.HandleFunc("POST /xrpc/com.atproto.repo.createRecord", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer jwt-123", r.Header.Get("Authorization"))
+		var body createRecordRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		created = append(created, body)
+		w.Write([]byte(`{"uri": "at://did:plc:abc123/app.bsky.feed.post/1"}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, &created
+}
+
+func Test_SignInAndPost(t *testing.T) {
+	ts, created := newFakePDS(t)
+
+	session, err := SignIn(Account{ServiceURL: ts.URL, Handle: "killarney.example.com", AppPassword: "app-password"})
+	require.NoError(t, err)
+
+	link := "https://developmentmap.calgary.ca/?find=LOC2026-0023"
+	text := "New rezoning LOC2026-0023 at 3214 29 ST SW: DC → H-GO\n\n" + link
+	require.NoError(t, session.Post(text, link, time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC)))
+
+	require.Len(t, *created, 1)
+	request := (*created)[0]
+	assert.Equal(t, "did:plc:abc123", request.Repo)
+	assert.Equal(t, "app.bsky.feed.post", request.Collection)
+	assert.Equal(t, text, request.Record.Text)
+	assert.Equal(t, "2026-03-28T12:00:00Z", request.Record.CreatedAt)
+
+	// The arrow is three bytes, so the link starts after the byte offset of its rune position
+	require.Len(t, request.Record.Facets, 1)
+	facet := request.Record.Facets[0]
+	assert.Equal(t, link, text[facet.Index.ByteStart:facet.Index.ByteEnd])
+	assert.Equal(t, "app.bsky.richtext.facet#link", facet.Features[0].Type)
+}
+
+func Test_SignIn_BadPassword(t *testing.T) {
+	ts, _ := newFakePDS(t)
+
+	_, err := SignIn(Account{ServiceURL: ts.URL, Handle: "killarney.example.com", AppPassword: "wrong"})
+	assert.ErrorContains(t, err, "Http Status 401")
+}
@@ -1,25 +1,64 @@
 package calgaryopendata
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
-	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/metrics"
 	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
 )
 
-func GetDevelopmentPermits() ([]byte, error) {
+// DefaultBaseURL - the City of Calgary's Socrata site
+const DefaultBaseURL = "https://data.calgary.ca"
+
+// Dataset identifiers on the Socrata site
+const (
+	DevelopmentPermitsDataset   = "6933-unw5"
+	RezoningApplicationsDataset = "33vi-ew4s"
+	// CommunityBoundariesDataset - Community District Boundaries, the outline of every community district
+	CommunityBoundariesDataset = "surr-xmvs"
+)
+
+// baseURL - the Socrata site datasets are fetched from
+var baseURL = DefaultBaseURL
+
+var (
+	fetchedRows   = metrics.Default.NewCounter("devbot_fetched_rows_total", "Rows fetched from Calgary Open Data.", "dataset")
+	fetchFailures = metrics.Default.NewCounter("devbot_fetch_failures_total", "Failed fetches from Calgary Open Data.", "dataset")
+	fetchDuration = metrics.Default.NewHistogram("devbot_fetch_duration_seconds", "Time taken to fetch a dataset from Calgary Open Data.", metrics.DefaultBuckets, "dataset")
+)
+
+// SetBaseURL - Fetches datasets from another Socrata site, such as a local fake for tests and demos
+func SetBaseURL(url string) {
+	baseURL = strings.TrimRight(url, "/")
+}
+
+// Host - Returns the host datasets are fetched from, such as data.calgary.ca
+func Host() string {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// GetDevelopmentPermits - Fetches the last 3 months of development permits matching the where condition, such as a bounding box
+// With updatedSince only rows changed at or after that Socrata :updated_at are fetched
+func GetDevelopmentPermits(ctx context.Context, where string, updatedSince string) ([]byte, error) {
 	var developmentPermits []byte
 
 	// Build URL
-	baseUrl := "https://data.calgary.ca/resource/6933-unw5.json"
-	// Only look back 3 months for recent activity
-	threeMonthsAgo := time.Now().AddDate(0, -3, 0).Format("2006-01-02T15:04:05.000")
-	query := fmt.Sprintf("$query=SELECT * WHERE applieddate > '%s' AND latitude BETWEEN '%f' AND '%f' AND longitude BETWEEN '%f' AND '%f' ORDER BY applieddate DESC", threeMonthsAgo, config.Config.Neighborhood.BoundingBox.SouthLatitude, config.Config.Neighborhood.BoundingBox.NorthLatitude, config.Config.Neighborhood.BoundingBox.EastLongitude, config.Config.Neighborhood.BoundingBox.WestLongitude)
-	url := fmt.Sprintf("%s?%s", baseUrl, query)
+	url := fmt.Sprintf("%s?%s", resourceURL(DevelopmentPermitsDataset), windowQuery(where, updatedSince))
 
-	response, err := simplehttp.SimpleGet(url, make(map[string]string))
+	started := time.Now()
+	response, err := simplehttp.SimpleGetContext(ctx, url, make(map[string]string))
+	recordFetch("development-permit", started, response, err)
 	if err != nil {
 		return developmentPermits, fmt.Errorf("error getting development permits from Calgary Open Data. Error: %s", err.Error())
 	}
@@ -32,25 +71,124 @@ func GetDevelopmentPermits() ([]byte, error) {
 	return developmentPermits, nil
 }
 
-func GetRezoningApplications() ([]byte, error) {
-	var developmentPermits []byte
+// GetRezoningApplications - Fetches the last 3 months of rezoning applications matching the where condition, such as a bounding box
+// With updatedSince only rows changed at or after that Socrata :updated_at are fetched
+func GetRezoningApplications(ctx context.Context, where string, updatedSince string) ([]byte, error) {
+	var rezoningApplications []byte
 
 	// Build URL
-	baseUrl := "https://data.calgary.ca/resource/33vi-ew4s.json"
-	// Only look back 3 months for recent activity
-	threeMonthsAgo := time.Now().AddDate(0, -3, 0).Format("2006-01-02T15:04:05.000")
-	query := fmt.Sprintf("$query=SELECT * WHERE applieddate > '%s' AND latitude BETWEEN '%f' AND '%f' AND longitude BETWEEN '%f' AND '%f' ORDER BY applieddate DESC", threeMonthsAgo, config.Config.Neighborhood.BoundingBox.SouthLatitude, config.Config.Neighborhood.BoundingBox.NorthLatitude, config.Config.Neighborhood.BoundingBox.EastLongitude, config.Config.Neighborhood.BoundingBox.WestLongitude)
-	url := fmt.Sprintf("%s?%s", baseUrl, query)
+	url := fmt.Sprintf("%s?%s", resourceURL(RezoningApplicationsDataset), windowQuery(where, updatedSince))
 
-	response, err := simplehttp.SimpleGet(url, make(map[string]string))
+	started := time.Now()
+	response, err := simplehttp.SimpleGetContext(ctx, url, make(map[string]string))
+	recordFetch("rezoning-application", started, response, err)
 	if err != nil {
-		return developmentPermits, fmt.Errorf("error getting development permits from Calgary Open Data. Error: %s", err.Error())
+		return rezoningApplications, fmt.Errorf("error getting rezoning applications from Calgary Open Data. Error: %s", err.Error())
 	}
 	if response.StatusCode != http.StatusOK {
-		return developmentPermits, fmt.Errorf("error getting development permits from Calgary Open Data. Http Status %d", response.StatusCode)
+		return rezoningApplications, fmt.Errorf("error getting rezoning applications from Calgary Open Data. Http Status %d", response.StatusCode)
 	}
 
-	developmentPermits = response.Body
+	rezoningApplications = response.Body
 
-	return developmentPermits, nil
+	return rezoningApplications, nil
+}
+
+// GetCommunityBoundaries - Fetches the boundaries of the community districts with the codes, such as KIL, or of every one without codes,
+// as a GeoJSON feature collection
+func GetCommunityBoundaries(ctx context.Context, codes []string) ([]byte, error) {
+	// Left unencoded like the other queries, the request escapes it. The limit is above the number of communities in the city
+	boundariesURL := fmt.Sprintf("%s/resource/%s.geojson?$order=comm_code&$limit=5000", baseURL, CommunityBoundariesDataset)
+	if len(codes) > 0 {
+		quoted := make([]string, 0, len(codes))
+		for _, code := range codes {
+			quoted = append(quoted, "'"+strings.ReplaceAll(code, "'", "''")+"'")
+		}
+		boundariesURL += fmt.Sprintf("&$where=comm_code IN (%s)", strings.Join(quoted, ", "))
+	}
+
+	response, err := simplehttp.SimpleGetContext(ctx, boundariesURL, make(map[string]string))
+	if err != nil {
+		return nil, fmt.Errorf("error getting community boundaries from Calgary Open Data. Error: %s", err.Error())
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error getting community boundaries from Calgary Open Data. Http Status %d", response.StatusCode)
+	}
+	return response.Body, nil
+}
+
+// GetDatasetUpdated - Returns when a dataset's rows were last changed, which identifies the version of a copy of it
+func GetDatasetUpdated(ctx context.Context, dataset string) (time.Time, error) {
+	response, err := simplehttp.SimpleGetContext(ctx, fmt.Sprintf("%s/api/views/%s.json", baseURL, dataset), make(map[string]string))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting dataset %s from Calgary Open Data. Error: %s", dataset, err.Error())
+	}
+	if response.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("error getting dataset %s from Calgary Open Data. Http Status %d", dataset, response.StatusCode)
+	}
+
+	var view struct {
+		RowsUpdatedAt int64 `json:"rowsUpdatedAt"`
+	}
+	if err := json.Unmarshal(response.Body, &view); err != nil || view.RowsUpdatedAt == 0 {
+		return time.Time{}, fmt.Errorf("dataset %s does not say when its rows were updated", dataset)
+	}
+	return time.Unix(view.RowsUpdatedAt, 0).UTC(), nil
+}
+
+// DatasetURL - Returns the page describing a dataset on the Socrata site
+func DatasetURL(dataset string) string {
+	return fmt.Sprintf("%s/d/%s", baseURL, dataset)
+}
+
+// resourceURL - the JSON endpoint of a dataset
+func resourceURL(dataset string) string {
+	return fmt.Sprintf("%s/resource/%s.json", baseURL, dataset)
+}
+
+// windowQuery - selects the last 3 months of rows matching the where condition, with their :updated_at so the next run can fetch only changes
+func windowQuery(where string, updatedSince string) string {
+	// Only look back 3 months for recent activity, applieddate is a floating timestamp in city time
+	// From the start of the day, so every query on the same day is the same and a recording's queries can be replayed
+	now := citytime.Now()
+	threeMonthsAgo := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, -3, 0).Format("2006-01-02T15:04:05.000")
+	condition := fmt.Sprintf("applieddate > '%s' AND %s", threeMonthsAgo, where)
+	// At or after, so rows updated in the same instant as the last run's latest are not missed
+	if updatedSince != "" {
+		condition += fmt.Sprintf(" AND :updated_at >= '%s'", strings.ReplaceAll(updatedSince, "'", "''"))
+	}
+	return fmt.Sprintf("$query=SELECT *, :updated_at WHERE %s ORDER BY applieddate DESC", condition)
+}
+
+// LatestUpdate - Returns the latest :updated_at of the fetched rows, empty when there are none
+func LatestUpdate(body []byte) string {
+	var rows []struct {
+		UpdatedAt string `json:":updated_at"`
+	}
+	if json.Unmarshal(body, &rows) != nil {
+		return ""
+	}
+
+	latest := ""
+	for _, row := range rows {
+		// Socrata writes them as ISO 8601 in UTC, so they order as text
+		if row.UpdatedAt > latest {
+			latest = row.UpdatedAt
+		}
+	}
+	return latest
+}
+
+// recordFetch - records the latency of a fetch and how many rows it returned
+func recordFetch(dataset string, started time.Time, response *simplehttp.SimpleHttpResponse, err error) {
+	fetchDuration.Observe(time.Since(started).Seconds(), dataset)
+	if err != nil || response.StatusCode != http.StatusOK {
+		fetchFailures.Inc(dataset)
+		return
+	}
+
+	var rows []json.RawMessage
+	if json.Unmarshal(response.Body, &rows) == nil {
+		fetchedRows.Add(float64(len(rows)), dataset)
+	}
 }
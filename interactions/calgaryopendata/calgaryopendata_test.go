@@ -0,0 +1,96 @@
+package calgaryopendata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WindowQuery(t *testing.T) {
+	where := "latitude BETWEEN '51.022361' AND '51.038912'"
+
+	full := windowQuery(where, "")
+	assert.True(t, strings.HasPrefix(full, "$query=SELECT *, :updated_at WHERE applieddate > '"))
+	assert.Contains(t, full, " AND "+where+" ORDER BY applieddate DESC")
+	assert.NotContains(t, full, ":updated_at >=")
+
+	incremental := windowQuery(where, "2026-04-01T06:00:00.000Z")
+	assert.Contains(t, incremental, where+" AND :updated_at >= '2026-04-01T06:00:00.000Z' ORDER BY")
+}
+
+func Test_WindowQuery_SameAllDay(t *testing.T) {
+	t.Cleanup(citytime.Unfreeze)
+	citytime.Freeze(time.Date(2026, 3, 29, 0, 5, 0, 0, citytime.Location()))
+	morning := windowQuery("1=1", "")
+	citytime.Freeze(time.Date(2026, 3, 29, 23, 55, 0, 0, citytime.Location()))
+	assert.Equal(t, morning, windowQuery("1=1", ""))
+	assert.Contains(t, morning, "applieddate > '2025-12-29T00:00:00.000'")
+}
+
+func Test_LatestUpdate(t *testing.T) {
+	body := []byte(`[
+		{"permitnum": "DP2026-00867", ":updated_at": "2026-03-30T06:12:00.000Z"},
+		{"permitnum": "DP2026-01738", ":updated_at": "2026-04-01T06:00:00.000Z"},
+		{"permitnum": "DP2026-01757"}
+	]`)
+	assert.Equal(t, "2026-04-01T06:00:00.000Z", LatestUpdate(body))
+	assert.Equal(t, "", LatestUpdate([]byte(`[]`)))
+	assert.Equal(t, "", LatestUpdate([]byte(`not json`)))
+}
+
+func Test_GetCommunityBoundaries(t *testing.T) {
+	t.Cleanup(func() { SetBaseURL(DefaultBaseURL) })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/views/surr-xmvs.json":
+			w.Write([]byte(`{"id": "surr-xmvs", "name": "Community District Boundaries", "rowsUpdatedAt": 1772470800}`))
+		case "/resource/surr-xmvs.geojson":
+			if r.URL.Query().Has("$where") {
+				assert.Equal(t, "comm_code IN ('KIL', 'O''B')", r.URL.Query().Get("$where"))
+			}
+			assert.Equal(t, "5000", r.URL.Query().Get("$limit"))
+			w.Write([]byte(`{"type": "FeatureCollection", "features": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	SetBaseURL(server.URL)
+
+	version, err := GetDatasetUpdated(context.Background(), CommunityBoundariesDataset)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC), version)
+
+	boundaries, err := GetCommunityBoundaries(context.Background(), []string{"KIL", "O'B"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, string(boundaries))
+	assert.Equal(t, server.URL+"/d/surr-xmvs", DatasetURL(CommunityBoundariesDataset))
+
+	_, err = GetCommunityBoundaries(context.Background(), nil)
+	require.NoError(t, err)
+
+	_, err = GetDatasetUpdated(context.Background(), "missing")
+	assert.ErrorContains(t, err, "Http Status 404")
+}
+
+func Test_GetDatasets_ErrorsNameTheDataset(t *testing.T) {
+	t.Cleanup(func() { SetBaseURL(DefaultBaseURL) })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	SetBaseURL(server.URL)
+
+	_, err := GetDevelopmentPermits(context.Background(), "1=1", "")
+	assert.EqualError(t, err, "error getting development permits from Calgary Open Data. Http Status 404")
+	_, err = GetRezoningApplications(context.Background(), "1=1", "")
+	assert.EqualError(t, err, "error getting rezoning applications from Calgary Open Data. Http Status 404")
+}
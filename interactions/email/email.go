@@ -0,0 +1,106 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// Message - an email with plain text and HTML versions of the same content
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Server - an SMTP server to send mail through, authenticating when a username is set
+type Server struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// Send - Sends a message through the SMTP server, upgrading to TLS when the server supports it
+func Send(server Server, message Message) error {
+	from, err := mail.ParseAddress(message.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address %q: %v", message.From, err)
+	}
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid to address %q: %v", message.To, err)
+	}
+
+	body, err := message.Bytes()
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if server.Username != "" {
+		auth = smtp.PlainAuth("", server.Username, server.Password, server.Host)
+	}
+
+	address := net.JoinHostPort(server.Host, strconv.Itoa(server.Port))
+	if err := smtp.SendMail(address, auth, from.Address, []string{to.Address}, body); err != nil {
+		return fmt.Errorf("error sending email to %s. Error: %s", to.Address, err.Error())
+	}
+
+	return nil
+}
+
+// Bytes - Builds the message as a multipart/alternative MIME document
+func (m Message) Bytes() ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", m.From)
+	fmt.Fprintf(&message, "To: %s\r\n", m.To)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	// Clients show the last alternative they understand, so the HTML version goes last
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %v", err)
+		}
+
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to build email: %v", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to build email: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %v", err)
+	}
+
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
@@ -0,0 +1,156 @@
+package email
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedMail - what the fake SMTP server was sent
+type receivedMail struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// startFakeSMTPServer - runs a minimal SMTP server that accepts a single message without TLS or authentication
+func startFakeSMTPServer(t *testing.T) (Server, <-chan receivedMail) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan receivedMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 localhost fake SMTP")
+
+		var message receivedMail
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				message.from = strings.Trim(strings.TrimSpace(line)[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				message.recipients = append(message.recipients, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				message.data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				received <- message
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return Server{Host: host, Port: portNumber}, received
+}
+
+func testMessage() Message {
+	return Message{
+		From:    "Development Bot <bot@example.com>",
+		To:      "resident@example.com",
+		Subject: "Killarney digest – 2 new",
+		Text:    "New development permit DP2026-01776",
+		HTML:    "<p>New development permit <strong>DP2026-01776</strong></p>",
+	}
+}
+
+func Test_Send_DeliversMultipartMessage(t *testing.T) {
+	server, received := startFakeSMTPServer(t)
+
+	require.NoError(t, Send(server, testMessage()))
+	message := <-received
+
+	assert.Equal(t, "bot@example.com", message.from)
+	assert.Equal(t, []string{"resident@example.com"}, message.recipients)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(message.data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Killarney digest – 2 new", subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var parts []string
+	var contentTypes []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		parts = append(parts, string(content))
+	}
+
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, contentTypes)
+	assert.Equal(t, "New development permit DP2026-01776", parts[0])
+	assert.Equal(t, "<p>New development permit <strong>DP2026-01776</strong></p>", parts[1])
+}
+
+func Test_Send_InvalidAddress(t *testing.T) {
+	message := testMessage()
+	message.To = "not an address"
+
+	err := Send(Server{Host: "127.0.0.1", Port: 25}, message)
+	assert.ErrorContains(t, err, "invalid to address")
+}
+
+func Test_Send_UnreachableServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	portNumber, _ := strconv.Atoi(port)
+
+	err = Send(Server{Host: "127.0.0.1", Port: portNumber}, testMessage())
+	assert.ErrorContains(t, err, "error sending email to resident@example.com")
+}
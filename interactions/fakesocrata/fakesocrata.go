@@ -0,0 +1,264 @@
+package fakesocrata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+)
+
+// updatedAtLayout - how Socrata writes :updated_at, in UTC
+const updatedAtLayout = "2006-01-02T15:04:05.000Z"
+
+// floatingTimestamp - dataset values that are Socrata floating timestamps, such as applieddate
+var floatingTimestamp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}$`)
+
+// Row - one dataset row as Socrata writes it in JSON
+type Row map[string]any
+
+// Scenario - the rows a fake server starts with and how they change over a sequence of days
+type Scenario struct {
+	// Start - the time of day 0, each following day is 24 hours later
+	Start time.Time `json:"start"`
+	// Key - the column days match rows by, permitnum when empty
+	Key string `json:"key,omitempty"`
+	// Datasets - the rows of each dataset on day 0, by dataset identifier such as 6933-unw5
+	Datasets map[string][]Row `json:"datasets"`
+	Days     []Day            `json:"days,omitempty"`
+}
+
+// Day - the changes made to the datasets at the start of a day
+type Day struct {
+	// Upsert - rows to add, or the columns to change on rows with the same key, a null removes a column
+	Upsert map[string][]Row `json:"upsert,omitempty"`
+	// Delete - the keys of rows to remove
+	Delete map[string][]string `json:"delete,omitempty"`
+}
+
+// Server - a fake Socrata site serving a scenario's datasets, moved on a day at a time with Advance
+type Server struct {
+	mux      *http.ServeMux
+	lock     sync.Mutex
+	scenario Scenario
+	day      int
+	rows     map[string][]Row
+	// created - how many rows each dataset has had, for their :id
+	created map[string]int
+}
+
+// LoadScenario - Reads a scenario from a JSON file
+func LoadScenario(path string) (Scenario, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("failed to read scenario: %v", err)
+	}
+	var scenario Scenario
+	if err := json.Unmarshal(content, &scenario); err != nil {
+		return Scenario{}, fmt.Errorf("failed to parse scenario %s: %v", path, err)
+	}
+	return scenario, nil
+}
+
+// MoveTo - Returns the scenario moved to start on another day, with every floating timestamp moved by the same number of days
+// so a scenario written once still falls inside the bot's 3 month window when demonstrated later
+func (s Scenario) MoveTo(start time.Time) Scenario {
+	days := int(start.Sub(s.Start).Hours() / 24)
+	moved := Scenario{Start: s.Start.AddDate(0, 0, days), Key: s.Key, Datasets: moveDatasets(s.Datasets, days)}
+	for _, day := range s.Days {
+		moved.Days = append(moved.Days, Day{Upsert: moveDatasets(day.Upsert, days), Delete: day.Delete})
+	}
+	return moved
+}
+
+// moveDatasets - copies rows with their floating timestamps moved by a number of days
+func moveDatasets(datasets map[string][]Row, days int) map[string][]Row {
+	if datasets == nil {
+		return nil
+	}
+	moved := make(map[string][]Row, len(datasets))
+	for dataset, rows := range datasets {
+		for _, row := range rows {
+			copied := Row{}
+			for column, value := range row {
+				if text, ok := value.(string); ok && floatingTimestamp.MatchString(text) {
+					if parsed, err := time.Parse("2006-01-02T15:04:05.000", text); err == nil {
+						value = parsed.AddDate(0, 0, days).Format("2006-01-02T15:04:05.000")
+					}
+				}
+				copied[column] = value
+			}
+			moved[dataset] = append(moved[dataset], copied)
+		}
+	}
+	return moved
+}
+
+// New - Creates a server on day 0 of the scenario, rows are stamped with the :updated_at of the day they last changed
+func New(scenario Scenario) *Server {
+	if scenario.Key == "" {
+		scenario.Key = "permitnum"
+	}
+	server := &Server{mux: http.NewServeMux(), scenario: scenario, rows: make(map[string][]Row), created: make(map[string]int)}
+	for dataset, rows := range scenario.Datasets {
+		// Datasets with no rows still exist
+		server.rows[dataset] = []Row{}
+		for _, row := range rows {
+			server.upsert(dataset, row)
+		}
+	}
+
+	server.mux.HandleFunc("GET /resource/{dataset}", server.serveResource)
+	server.mux.HandleFunc("POST /advance", server.serveAdvance)
+	return server
+}
+
+// ServeHTTP - serves datasets at /resource/<dataset>.json and moves to the next day on POST /advance
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Day - Returns the current day, 0 until the first Advance
+func (s *Server) Day() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.day
+}
+
+// Now - Returns the time of the current day
+func (s *Server) Now() time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.now()
+}
+
+// now - the time of the current day, the lock must be held
+func (s *Server) now() time.Time {
+	return s.scenario.Start.Add(time.Duration(s.day) * 24 * time.Hour)
+}
+
+// Advance - Moves to the next day and applies its changes, false when the scenario has no more days
+func (s *Server) Advance() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.day >= len(s.scenario.Days) {
+		return false
+	}
+
+	changes := s.scenario.Days[s.day]
+	s.day++
+	for dataset, keys := range changes.Delete {
+		kept := []Row{}
+		for _, row := range s.rows[dataset] {
+			if !containsKey(keys, row[s.scenario.Key]) {
+				kept = append(kept, row)
+			}
+		}
+		s.rows[dataset] = kept
+	}
+	for dataset, rows := range changes.Upsert {
+		for _, row := range rows {
+			s.upsert(dataset, row)
+		}
+	}
+	return true
+}
+
+// upsert - adds a row or changes the columns of the row with the same key, the lock must be held
+func (s *Server) upsert(dataset string, changes Row) {
+	stamp := s.now().UTC().Format(updatedAtLayout)
+	key, _ := changes[s.scenario.Key].(string)
+	for _, row := range s.rows[dataset] {
+		if key != "" && containsKey([]string{key}, row[s.scenario.Key]) {
+			for column, value := range changes {
+				if value == nil {
+					delete(row, column)
+				} else {
+					row[column] = value
+				}
+			}
+			row[":updated_at"] = stamp
+			return
+		}
+	}
+
+	s.created[dataset]++
+	row := Row{":id": fmt.Sprintf("row-%d", s.created[dataset]), ":created_at": stamp, ":updated_at": stamp}
+	for column, value := range changes {
+		if value != nil {
+			row[column] = value
+		}
+	}
+	s.rows[dataset] = append(s.rows[dataset], row)
+}
+
+// containsKey - true if the value is one of the keys
+func containsKey(keys []string, value any) bool {
+	for _, key := range keys {
+		if value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// serveResource - answers a query with $query, or with $select, $where, $order, $limit and $offset
+func (s *Server) serveResource(w http.ResponseWriter, r *http.Request) {
+	dataset := strings.TrimSuffix(r.PathValue("dataset"), ".json")
+	soql := soqlFromParameters(r.URL.Query())
+	parsed, err := parseQuery(soql)
+	if err != nil {
+		webserver.WriteJSON(w, http.StatusBadRequest, map[string]any{"error": true, "code": "query.compiler.malformed", "message": err.Error()})
+		return
+	}
+
+	s.lock.Lock()
+	rows, found := s.rows[dataset]
+	var results []Row
+	if found {
+		results = parsed.run(rows)
+	}
+	s.lock.Unlock()
+	if !found {
+		webserver.WriteJSON(w, http.StatusNotFound, map[string]any{"error": true, "code": "not_found", "message": fmt.Sprintf("dataset %s not found", dataset)})
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, results)
+}
+
+// serveAdvance - moves to the next day, 409 Conflict when the scenario has no more days
+func (s *Server) serveAdvance(w http.ResponseWriter, r *http.Request) {
+	if !s.Advance() {
+		webserver.WriteJSON(w, http.StatusConflict, map[string]any{"error": true, "message": "the scenario has no more days"})
+		return
+	}
+	webserver.WriteJSON(w, http.StatusOK, map[string]any{"day": s.Day(), "time": s.Now().Format(time.RFC3339)})
+}
+
+// soqlFromParameters - the query in $query, or one built from the separate clause parameters
+func soqlFromParameters(parameters url.Values) string {
+	if soql := parameters.Get("$query"); soql != "" {
+		return soql
+	}
+
+	selected := parameters.Get("$select")
+	if selected == "" {
+		selected = "*"
+	}
+	soql := "SELECT " + selected
+	for _, clause := range []struct{ parameter, keyword string }{
+		{"$where", "WHERE"}, {"$order", "ORDER BY"}, {"$limit", "LIMIT"}, {"$offset", "OFFSET"},
+	} {
+		if value := parameters.Get(clause.parameter); value != "" {
+			soql += " " + clause.keyword + " " + value
+		}
+	}
+	return soql
+}
@@ -0,0 +1,116 @@
+package fakesocrata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// get - queries the server, returning the status and decoded rows
+func get(t *testing.T, server *httptest.Server, path string, parameters url.Values) (int, []Row) {
+	response, err := http.Get(server.URL + path + "?" + parameters.Encode())
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	var rows []Row
+	if response.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&rows))
+	}
+	return response.StatusCode, rows
+}
+
+func Test_LoadScenario(t *testing.T) {
+	scenario, err := LoadScenario("testdata/lifecycle.json")
+	require.NoError(t, err)
+	assert.Len(t, scenario.Datasets["6933-unw5"], 2)
+	assert.Len(t, scenario.Datasets["33vi-ew4s"], 1)
+	assert.Len(t, scenario.Days, 2)
+
+	_, err = LoadScenario("testdata/missing.json")
+	assert.Error(t, err)
+}
+
+func Test_Server_AdvancesThroughDays(t *testing.T) {
+	scenario, err := LoadScenario("testdata/lifecycle.json")
+	require.NoError(t, err)
+	fake := New(scenario)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	query := url.Values{"$query": {"SELECT permitnum, statuscurrent, :updated_at WHERE permitnum = 'LOC2026-0031'"}}
+	status, rows := get(t, server, "/resource/33vi-ew4s.json", query)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []Row{{"permitnum": "LOC2026-0031", "statuscurrent": "Submitted", ":updated_at": "2026-04-01T14:00:00.000Z"}}, rows)
+
+	for day, expected := range []string{"Under Review", "Approved"} {
+		response, err := http.Post(server.URL+"/advance", "", nil)
+		require.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, day+1, fake.Day())
+
+		_, rows = get(t, server, "/resource/33vi-ew4s.json", query)
+		require.Len(t, rows, 1)
+		assert.Equal(t, expected, rows[0]["statuscurrent"])
+		assert.Equal(t, fake.Now().UTC().Format(updatedAtLayout), rows[0][":updated_at"])
+	}
+
+	// Unchanged rows keep the :updated_at of day 0
+	_, rows = get(t, server, "/resource/6933-unw5.json", url.Values{"$where": {":updated_at < '2026-04-02T00:00:00.000Z'"}, "$select": {"permitnum"}})
+	assert.Equal(t, []Row{{"permitnum": "DP2026-01900"}}, rows)
+
+	response, err := http.Post(server.URL+"/advance", "", nil)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, 2, fake.Day())
+}
+
+func Test_Server_DeletesRows(t *testing.T) {
+	fake := New(Scenario{
+		Start:    time.Date(2026, 4, 1, 14, 0, 0, 0, time.UTC),
+		Datasets: map[string][]Row{"6933-unw5": {{"permitnum": "DP2026-00001"}, {"permitnum": "DP2026-00002"}}},
+		Days:     []Day{{Delete: map[string][]string{"6933-unw5": {"DP2026-00001"}}, Upsert: map[string][]Row{"6933-unw5": {{"permitnum": "DP2026-00003"}}}}},
+	})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	require.True(t, fake.Advance())
+	_, rows := get(t, server, "/resource/6933-unw5.json", url.Values{"$query": {"SELECT permitnum, :id"}})
+	assert.Equal(t, []Row{{"permitnum": "DP2026-00002", ":id": "row-2"}, {"permitnum": "DP2026-00003", ":id": "row-3"}}, rows)
+}
+
+func Test_Server_Errors(t *testing.T) {
+	server := httptest.NewServer(New(Scenario{Datasets: map[string][]Row{"6933-unw5": {}}}))
+	defer server.Close()
+
+	status, rows := get(t, server, "/resource/6933-unw5.json", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, rows)
+
+	status, _ = get(t, server, "/resource/zzzz-zzzz.json", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = get(t, server, "/resource/6933-unw5.json", url.Values{"$query": {"SELECT * WHERE"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func Test_Scenario_MoveTo(t *testing.T) {
+	scenario, err := LoadScenario("testdata/lifecycle.json")
+	require.NoError(t, err)
+
+	moved := scenario.MoveTo(scenario.Start.AddDate(0, 0, 30).Add(3 * time.Hour))
+	assert.True(t, scenario.Start.AddDate(0, 0, 30).Equal(moved.Start))
+	assert.Equal(t, "2026-04-29T10:15:00.000", moved.Datasets["33vi-ew4s"][0]["applieddate"])
+	assert.Equal(t, "2026-05-03T14:30:00.000", moved.Days[1].Upsert["33vi-ew4s"][0]["completeddate"])
+	assert.Equal(t, "51.02919670018869", moved.Datasets["33vi-ew4s"][0]["latitude"])
+
+	// The original is left as it was
+	assert.Equal(t, "2026-03-30T10:15:00.000", scenario.Datasets["33vi-ew4s"][0]["applieddate"])
+}
@@ -0,0 +1,562 @@
+package fakesocrata
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultLimit - the rows a query returns without a LIMIT, as on Socrata
+const DefaultLimit = 1000
+
+// query - a parsed SoQL query
+type query struct {
+	// columns - the selected columns, * selects every column except the system fields that start with a colon
+	columns []string
+	where   expression
+	order   []ordering
+	limit   int
+	offset  int
+}
+
+// ordering - one column of an ORDER BY
+type ordering struct {
+	column     string
+	descending bool
+}
+
+// expression - a WHERE condition, rows with a missing or null column never match a comparison on it
+type expression interface {
+	matches(row Row) bool
+}
+
+type andExpression struct{ left, right expression }
+type orExpression struct{ left, right expression }
+type notExpression struct{ inner expression }
+
+// comparison - column op value, with op one of = != <> < <= > >=
+type comparison struct {
+	column   string
+	operator string
+	value    literal
+}
+
+type betweenExpression struct {
+	column    string
+	low, high literal
+}
+
+type inExpression struct {
+	column string
+	values []literal
+}
+
+type nullExpression struct {
+	column string
+	isNull bool
+}
+
+// literal - a quoted string or a number, strings are compared as text and numbers as numbers as on Socrata
+type literal struct {
+	text     string
+	number   float64
+	isNumber bool
+}
+
+func (e andExpression) matches(row Row) bool { return e.left.matches(row) && e.right.matches(row) }
+func (e orExpression) matches(row Row) bool  { return e.left.matches(row) || e.right.matches(row) }
+func (e notExpression) matches(row Row) bool { return !e.inner.matches(row) }
+
+func (e comparison) matches(row Row) bool {
+	order, ok := compareValue(row[e.column], e.value)
+	if !ok {
+		return false
+	}
+	switch e.operator {
+	case "=":
+		return order == 0
+	case "!=", "<>":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+func (e betweenExpression) matches(row Row) bool {
+	low, ok := compareValue(row[e.column], e.low)
+	if !ok {
+		return false
+	}
+	high, _ := compareValue(row[e.column], e.high)
+	return low >= 0 && high <= 0
+}
+
+func (e inExpression) matches(row Row) bool {
+	for _, value := range e.values {
+		if order, ok := compareValue(row[e.column], value); ok && order == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (e nullExpression) matches(row Row) bool {
+	return (row[e.column] == nil) == e.isNull
+}
+
+// compareValue - orders a row's value against a literal, false when the value is null or cannot be compared
+func compareValue(value any, against literal) (int, bool) {
+	if against.isNumber {
+		var number float64
+		switch typed := value.(type) {
+		case float64:
+			number = typed
+		case string:
+			parsed, err := strconv.ParseFloat(typed, 64)
+			if err != nil {
+				return 0, false
+			}
+			number = parsed
+		default:
+			return 0, false
+		}
+		switch {
+		case number < against.number:
+			return -1, true
+		case number > against.number:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(text, against.text), true
+}
+
+// parseQuery - Parses the SoQL the bot sends: SELECT with * or columns, WHERE with comparisons, BETWEEN, IN, IS NULL, AND, OR, NOT
+// and parentheses, ORDER BY, LIMIT and OFFSET
+func parseQuery(soql string) (query, error) {
+	tokens, err := tokenize(soql)
+	if err != nil {
+		return query{}, err
+	}
+	p := &parser{tokens: tokens}
+	parsed := query{limit: DefaultLimit}
+
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return query{}, err
+	}
+	for {
+		column := p.next()
+		if column.kind != identifierToken && column.text != "*" {
+			return query{}, fmt.Errorf("expected a column to select, got %q", column.text)
+		}
+		parsed.columns = append(parsed.columns, column.text)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if p.acceptKeyword("WHERE") {
+		if parsed.where, err = p.parseOr(); err != nil {
+			return query{}, err
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return query{}, err
+		}
+		for {
+			column := p.next()
+			if column.kind != identifierToken {
+				return query{}, fmt.Errorf("expected a column to order by, got %q", column.text)
+			}
+			order := ordering{column: column.text}
+			if p.acceptKeyword("DESC") {
+				order.descending = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			parsed.order = append(parsed.order, order)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if parsed.limit, err = p.parseCount("LIMIT"); err != nil {
+			return query{}, err
+		}
+	}
+	if p.acceptKeyword("OFFSET") {
+		if parsed.offset, err = p.parseCount("OFFSET"); err != nil {
+			return query{}, err
+		}
+	}
+	if extra := p.next(); extra.kind != endToken {
+		return query{}, fmt.Errorf("unexpected %q", extra.text)
+	}
+
+	return parsed, nil
+}
+
+// run - Applies the query to a dataset's rows, which are in :id order
+func (q query) run(rows []Row) []Row {
+	var selected []Row
+	for _, row := range rows {
+		if q.where == nil || q.where.matches(row) {
+			selected = append(selected, row)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		for _, order := range q.order {
+			compared := compareRows(selected[i][order.column], selected[j][order.column])
+			if compared == 0 {
+				continue
+			}
+			if order.descending {
+				return compared > 0
+			}
+			return compared < 0
+		}
+		return false
+	})
+
+	if q.offset >= len(selected) {
+		return []Row{}
+	}
+	selected = selected[q.offset:]
+	if q.limit < len(selected) {
+		selected = selected[:q.limit]
+	}
+
+	results := make([]Row, 0, len(selected))
+	for _, row := range selected {
+		results = append(results, q.project(row))
+	}
+	return results
+}
+
+// project - keeps the selected columns of a row, leaving out nulls as Socrata does
+func (q query) project(row Row) Row {
+	projected := Row{}
+	for _, column := range q.columns {
+		if column == "*" {
+			for name, value := range row {
+				if !strings.HasPrefix(name, ":") && value != nil {
+					projected[name] = value
+				}
+			}
+			continue
+		}
+		if value := row[column]; value != nil {
+			projected[column] = value
+		}
+	}
+	return projected
+}
+
+// compareRows - orders two values of a column, numbers as numbers, anything else as text and nulls last
+func compareRows(a any, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	aNumber, aIsNumber := a.(float64)
+	bNumber, bIsNumber := b.(float64)
+	if aIsNumber && bIsNumber {
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// Token kinds
+const (
+	endToken = iota
+	identifierToken
+	stringToken
+	numberToken
+	symbolToken
+)
+
+// token - one word, value or symbol of a query
+type token struct {
+	kind int
+	text string
+}
+
+// tokenize - splits a query into tokens, identifiers may start with a colon for system fields such as :updated_at
+func tokenize(soql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(soql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			// Quotes inside strings are doubled
+			var text strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string in %q", soql)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						text.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: stringToken, text: text.String()})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_' || r == ':':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: identifierToken, text: string(runes[start:i])})
+		case strings.ContainsRune("<>!", r) && i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')):
+			tokens = append(tokens, token{kind: symbolToken, text: string(runes[i : i+2])})
+			i += 2
+		case strings.ContainsRune("*,()=<>", r):
+			tokens = append(tokens, token{kind: symbolToken, text: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", string(r), soql)
+		}
+	}
+	return append(tokens, token{kind: endToken}), nil
+}
+
+// parser - reads a query's tokens in order
+type parser struct {
+	tokens   []token
+	position int
+}
+
+// peek - the next token, without reading it
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+// next - reads the next token, the end token repeats once reached
+func (p *parser) next() token {
+	current := p.tokens[p.position]
+	if current.kind != endToken {
+		p.position++
+	}
+	return current
+}
+
+// acceptKeyword - reads the next token if it is the keyword, ignoring case
+func (p *parser) acceptKeyword(keyword string) bool {
+	if next := p.peek(); next.kind == identifierToken && strings.EqualFold(next.text, keyword) {
+		p.position++
+		return true
+	}
+	return false
+}
+
+// expectKeyword - reads the keyword or fails
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return fmt.Errorf("expected %s, got %q", keyword, p.peek().text)
+	}
+	return nil
+}
+
+// acceptSymbol - reads the next token if it is the symbol
+func (p *parser) acceptSymbol(symbol string) bool {
+	if next := p.peek(); next.kind == symbolToken && next.text == symbol {
+		p.position++
+		return true
+	}
+	return false
+}
+
+// parseCount - reads the whole number after LIMIT or OFFSET
+func (p *parser) parseCount(clause string) (int, error) {
+	count, err := strconv.Atoi(p.next().text)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("%s must be a whole number", clause)
+	}
+	return count, nil
+}
+
+// parseOr - condition OR condition ..., OR binds loosest
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd - condition AND condition ...
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left, right}
+	}
+	return left, nil
+}
+
+// parseNot - NOT condition, a bracketed condition or a single predicate
+func (p *parser) parseNot() (expression, error) {
+	if p.acceptKeyword("NOT") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{inner}, nil
+	}
+	if p.acceptSymbol("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptSymbol(")") {
+			return nil, fmt.Errorf("expected ), got %q", p.peek().text)
+		}
+		return inner, nil
+	}
+	return p.parsePredicate()
+}
+
+// parsePredicate - a column compared to a value, BETWEEN two values, IN a list or IS NULL
+func (p *parser) parsePredicate() (expression, error) {
+	column := p.next()
+	if column.kind != identifierToken {
+		return nil, fmt.Errorf("expected a column, got %q", column.text)
+	}
+
+	switch {
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return betweenExpression{column: column.text, low: low, high: high}, nil
+	case p.acceptKeyword("IS"):
+		isNull := !p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return nullExpression{column: column.text, isNull: isNull}, nil
+	case p.acceptKeyword("NOT"):
+		if err := p.expectKeyword("IN"); err != nil {
+			return nil, err
+		}
+		in, err := p.parseIn(column.text)
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{in}, nil
+	case p.acceptKeyword("IN"):
+		return p.parseIn(column.text)
+	}
+
+	operator := p.next()
+	if operator.kind != symbolToken || !strings.Contains(" = != <> < <= > >= ", " "+operator.text+" ") {
+		return nil, fmt.Errorf("expected a comparison after %s, got %q", column.text, operator.text)
+	}
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{column: column.text, operator: operator.text, value: value}, nil
+}
+
+// parseIn - the bracketed list of values after IN
+func (p *parser) parseIn(column string) (expression, error) {
+	if !p.acceptSymbol("(") {
+		return nil, fmt.Errorf("expected ( after IN, got %q", p.peek().text)
+	}
+	in := inExpression{column: column}
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		in.values = append(in.values, value)
+		if p.acceptSymbol(")") {
+			return in, nil
+		}
+		if !p.acceptSymbol(",") {
+			return nil, fmt.Errorf("expected , or ) in IN list, got %q", p.peek().text)
+		}
+	}
+}
+
+// parseLiteral - a quoted string or a number
+func (p *parser) parseLiteral() (literal, error) {
+	value := p.next()
+	switch value.kind {
+	case stringToken:
+		return literal{text: value.text}, nil
+	case numberToken:
+		number, err := strconv.ParseFloat(value.text, 64)
+		if err != nil {
+			return literal{}, fmt.Errorf("invalid number %q", value.text)
+		}
+		return literal{text: value.text, number: number, isNumber: true}, nil
+	}
+	return literal{}, fmt.Errorf("expected a value, got %q", value.text)
+}
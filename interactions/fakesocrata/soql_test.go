@@ -0,0 +1,95 @@
+package fakesocrata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// permitRows - development permit rows in :id order
+var permitRows = []Row{
+	{":id": "row-1", ":updated_at": "2026-04-01T14:00:00.000Z", "permitnum": "DP2026-00001", "applieddate": "2026-03-20T00:00:00.000", "communitycode": "KIL", "latitude": "51.03", "longitude": "-114.13", "locationcount": "2"},
+	{":id": "row-2", ":updated_at": "2026-04-02T14:00:00.000Z", "permitnum": "DP2026-00002", "applieddate": "2026-03-28T00:00:00.000", "communitycode": "RIC", "latitude": "51.026", "longitude": "-114.128", "locationcount": "10"},
+	{":id": "row-3", ":updated_at": "2026-04-01T14:00:00.000Z", "permitnum": "DP2026-00003", "applieddate": "2025-11-01T00:00:00.000", "communitycode": "DNC", "latitude": "51.045", "longitude": "-114.072", "decision": nil},
+}
+
+// permitNums - the permit numbers of rows, in order
+func permitNums(rows []Row) []string {
+	nums := []string{}
+	for _, row := range rows {
+		nums = append(nums, row["permitnum"].(string))
+	}
+	return nums
+}
+
+func Test_Query_TheBotsWindowQuery(t *testing.T) {
+	parsed, err := parseQuery("SELECT *, :updated_at WHERE applieddate > '2026-01-01T08:00:00.000' AND " +
+		"((latitude BETWEEN '51.022361' AND '51.038912' AND longitude BETWEEN '-114.117927' AND '-114.142638') OR communitycode IN ('RIC', 'O''BRIEN')) " +
+		"AND :updated_at >= '2026-04-02T00:00:00.000Z' ORDER BY applieddate DESC")
+	require.NoError(t, err)
+
+	results := parsed.run(permitRows)
+	assert.Equal(t, []string{"DP2026-00002"}, permitNums(results))
+	assert.Equal(t, "2026-04-02T14:00:00.000Z", results[0][":updated_at"])
+	assert.NotContains(t, results[0], ":id")
+}
+
+func Test_Query_SelectStarLeavesOutSystemFieldsAndNulls(t *testing.T) {
+	parsed, err := parseQuery("SELECT * WHERE permitnum = 'DP2026-00003'")
+	require.NoError(t, err)
+
+	results := parsed.run(permitRows)
+	require.Len(t, results, 1)
+	assert.NotContains(t, results[0], ":updated_at")
+	assert.NotContains(t, results[0], "decision")
+}
+
+func Test_Query_OrderLimitOffset(t *testing.T) {
+	parsed, err := parseQuery("SELECT permitnum ORDER BY applieddate ASC LIMIT 2 OFFSET 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DP2026-00001", "DP2026-00002"}, permitNums(parsed.run(permitRows)))
+	assert.Equal(t, []Row{{"permitnum": "DP2026-00001"}, {"permitnum": "DP2026-00002"}}, parsed.run(permitRows))
+
+	parsed, err = parseQuery("SELECT * OFFSET 5")
+	require.NoError(t, err)
+	assert.Empty(t, parsed.run(permitRows))
+}
+
+func Test_Query_NumbersCompareAsNumbers(t *testing.T) {
+	// As text '10' sorts before '2'
+	parsed, err := parseQuery("SELECT * WHERE locationcount > 2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DP2026-00002"}, permitNums(parsed.run(permitRows)))
+
+	parsed, err = parseQuery("SELECT * WHERE locationcount > '2'")
+	require.NoError(t, err)
+	assert.Empty(t, parsed.run(permitRows))
+}
+
+func Test_Query_NotAndNull(t *testing.T) {
+	parsed, err := parseQuery("select * where not communitycode in ('KIL', 'RIC') or locationcount is null order by permitnum desc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DP2026-00003"}, permitNums(parsed.run(permitRows)))
+
+	parsed, err = parseQuery("SELECT * WHERE communitycode NOT IN ('KIL') AND locationcount IS NOT NULL")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DP2026-00002"}, permitNums(parsed.run(permitRows)))
+}
+
+func Test_Query_Malformed(t *testing.T) {
+	for _, soql := range []string{
+		"",
+		"SELECT",
+		"SELECT * WHERE",
+		"SELECT * WHERE applieddate > '2026-01-01",
+		"SELECT * WHERE latitude BETWEEN '1'",
+		"SELECT * WHERE (permitnum = 'DP2026-00001'",
+		"SELECT * WHERE permitnum LIKE 'DP%'",
+		"SELECT * LIMIT -1",
+		"SELECT * GROUP BY permitnum",
+	} {
+		_, err := parseQuery(soql)
+		assert.Error(t, err, soql)
+	}
+}
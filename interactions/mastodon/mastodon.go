@@ -0,0 +1,51 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+)
+
+// URLLength - Mastodon counts every link as this many characters, however long it is
+const URLLength = 23
+
+// Account - a Mastodon account on an instance, authorized with an access token that has the write:statuses scope
+type Account struct {
+	InstanceURL string
+	AccessToken string
+}
+
+// status - the body of a post status request
+type status struct {
+	Status     string `json:"status"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// PostStatus - Posts a status, the idempotency key stops a retried request from posting it twice
+func PostStatus(account Account, text string, visibility string, idempotencyKey string) error {
+	body, err := json.Marshal(status{Status: text, Visibility: visibility})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Mastodon status: %v", err)
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + account.AccessToken,
+		"Content-Type":  "application/json",
+	}
+	if idempotencyKey != "" {
+		headers["Idempotency-Key"] = idempotencyKey
+	}
+
+	uri := strings.TrimSuffix(account.InstanceURL, "/") + "/api/v1/statuses"
+	response, err := simplehttp.SimplePost(uri, headers, body)
+	if err != nil {
+		return fmt.Errorf("error posting Mastodon status. Error: %s", err.Error())
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("error posting Mastodon status. Http Status %d", response.StatusCode)
+	}
+
+	return nil
+}
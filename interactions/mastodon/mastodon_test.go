@@ -0,0 +1,40 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PostStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/statuses", r.URL.Path)
+		assert.Equal(t, "Bearer token-123", r.Header.Get("Authorization"))
+		assert.Equal(t, "LOC2026-0023-CREATE", r.Header.Get("Idempotency-Key"))
+
+		var body status
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "New rezoning LOC2026-0023", body.Status)
+		assert.Equal(t, "unlisted", body.Visibility)
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer ts.Close()
+
+	err := PostStatus(Account{InstanceURL: ts.URL + "/", AccessToken: "token-123"}, "New rezoning LOC2026-0023", "unlisted", "LOC2026-0023-CREATE")
+	assert.NoError(t, err)
+}
+
+func Test_PostStatus_Unauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	err := PostStatus(Account{InstanceURL: ts.URL, AccessToken: "expired"}, "Hello", "", "")
+	assert.ErrorContains(t, err, "Http Status 401")
+}
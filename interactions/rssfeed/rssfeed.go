@@ -1,10 +1,13 @@
 package rssfeed
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
 	"github.com/jeffadavidson/development-bot/utilities/fileio"
 )
 
@@ -16,12 +19,21 @@ type RSS struct {
 }
 
 type Channel struct {
-	Title         string `xml:"title"`
-	Link          string `xml:"link"`
-	Description   string `xml:"description"`
-	Language      string `xml:"language"`
-	LastBuildDate string `xml:"lastBuildDate"`
-	Items         []Item `xml:"item"`
+	Title string `xml:"title"`
+	// AtomLinks - in the Atom namespace and ahead of Link, which matches a link in any namespace, so loading keeps them apart
+	AtomLinks     []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Language      string     `xml:"language"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Items         []Item     `xml:"item"`
+}
+
+// AtomLink represents an atom:link element, used to advertise the feed's own URL and its WebSub hub
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
 }
 
 type Item struct {
@@ -58,7 +70,7 @@ func CreateRSSFeed(title, description, link string) *RSS {
 			Link:          link,
 			Description:   description,
 			Language:      "en-us",
-			LastBuildDate: time.Now().Format(time.RFC1123Z),
+			LastBuildDate: citytime.Now().Format(time.RFC1123Z),
 			Items:         []Item{},
 		},
 	}
@@ -81,7 +93,7 @@ func (rss *RSS) AddItem(title, description, link, guid string, pubDate time.Time
 
 	// Add to beginning of slice (newest first)
 	rss.Channel.Items = append([]Item{item}, rss.Channel.Items...)
-	rss.Channel.LastBuildDate = time.Now().Format(time.RFC1123Z)
+	rss.Channel.LastBuildDate = citytime.Now().Format(time.RFC1123Z)
 }
 
 // ToXML converts the RSS feed to XML bytes
@@ -159,7 +171,7 @@ func (rss *RSS) UpdateItem(title, description, link, guid string, pubDate time.T
 			item.Author = newItem.Author
 			item.Source = newItem.Source
 			item.Comments = newItem.Comments
-			rss.Channel.LastBuildDate = time.Now().Format(time.RFC1123Z)
+			rss.Channel.LastBuildDate = citytime.Now().Format(time.RFC1123Z)
 			return true
 		}
 		return false
@@ -170,6 +182,16 @@ func (rss *RSS) UpdateItem(title, description, link, guid string, pubDate time.T
 	}
 }
 
+// UpsertItem copies an item from another feed, adding it or updating the item with the same GUID
+// Returns true if any actual changes were made to the feed
+func (rss *RSS) UpsertItem(item Item) bool {
+	pubDate, err := time.Parse(time.RFC1123Z, item.PubDate)
+	if err != nil {
+		pubDate = citytime.Now()
+	}
+	return rss.UpdateItem(item.Title, item.Description.Text, item.Link, item.GUID.Value, pubDate, item.Category, item.Author, item.Source, item.Comments, item.ContentEncoded.Text)
+}
+
 // TrimToMaxItems keeps only the most recent N items in the feed
 func (rss *RSS) TrimToMaxItems(maxItems int) {
 	if len(rss.Channel.Items) > maxItems {
@@ -177,6 +199,31 @@ func (rss *RSS) TrimToMaxItems(maxItems int) {
 	}
 }
 
+// SortByPubDate orders items newest first, items with the same date are ordered by GUID so output is stable
+func (rss *RSS) SortByPubDate() {
+	sort.SliceStable(rss.Channel.Items, func(i, j int) bool {
+		iDate, _ := time.Parse(time.RFC1123Z, rss.Channel.Items[i].PubDate)
+		jDate, _ := time.Parse(time.RFC1123Z, rss.Channel.Items[j].PubDate)
+		if !iDate.Equal(jDate) {
+			return iDate.After(jDate)
+		}
+		return rss.Channel.Items[i].GUID.Value < rss.Channel.Items[j].GUID.Value
+	})
+}
+
+// SetHubLinks advertises a WebSub hub and the feed's own URL, clears the links when either is empty
+func (rss *RSS) SetHubLinks(hubURL, selfURL string) {
+	if hubURL == "" || selfURL == "" {
+		rss.Channel.AtomLinks = nil
+		return
+	}
+
+	rss.Channel.AtomLinks = []AtomLink{
+		{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+		{Href: hubURL, Rel: "hub"},
+	}
+}
+
 // GetOrCreateRSSFeed loads an existing RSS feed from file or creates a new one
 func GetOrCreateRSSFeed(filepath, title, description, link string) (*RSS, error) {
 	// Try to load existing feed
@@ -212,13 +259,23 @@ func GetOrCreateRSSFeed(filepath, title, description, link string) (*RSS, error)
 }
 
 // SaveRSSFeed saves an RSS feed to a file
-func SaveRSSFeed(rss *RSS, filepath string) error {
+// Returns true if the file was written, false if it already held the same feed
+func SaveRSSFeed(rss *RSS, filepath string) (bool, error) {
 	xmlData, err := rss.ToXML()
 	if err != nil {
-		return fmt.Errorf("failed to convert RSS to XML: %v", err)
+		return false, fmt.Errorf("failed to convert RSS to XML: %v", err)
+	}
+
+	existingData, loadErr := loadRSSFile(filepath)
+	if loadErr == nil && bytes.Equal(existingData, xmlData) {
+		return false, nil
+	}
+
+	if err := saveRSSFile(filepath, xmlData); err != nil {
+		return false, err
 	}
 
-	return saveRSSFile(filepath, xmlData)
+	return true, nil
 }
 
 // loadRSSFile reads RSS XML from file using the existing fileio utility
@@ -384,3 +384,89 @@ func TestGetOrCreateRSSFeed_FixesEmptyContentEncoded(t *testing.T) {
 	// Should contain the preserved content:encoded for item 2
 	assert.Contains(t, xmlString, `<content:encoded><![CDATA[Already has content]]></content:encoded>`)
 }
+
+func TestSortByPubDate(t *testing.T) {
+	rss := CreateRSSFeed("Test", "Test", "https://example.com")
+	older := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	rss.AddItem("Newer", "Desc", "https://example.com/1", "guid-b", newer, "", "", "", "", "")
+	rss.AddItem("Older", "Desc", "https://example.com/2", "guid-c", older, "", "", "", "", "")
+	rss.AddItem("Newer Tie", "Desc", "https://example.com/3", "guid-a", newer, "", "", "", "", "")
+
+	rss.SortByPubDate()
+
+	require.Len(t, rss.Channel.Items, 3)
+	assert.Equal(t, "guid-a", rss.Channel.Items[0].GUID.Value)
+	assert.Equal(t, "guid-b", rss.Channel.Items[1].GUID.Value)
+	assert.Equal(t, "guid-c", rss.Channel.Items[2].GUID.Value)
+}
+
+func TestSetHubLinks(t *testing.T) {
+	rss := CreateRSSFeed("Test Feed", "Test Description", "https://example.com")
+	rss.SetHubLinks("https://hub.example.com/", "https://example.com/feed.xml")
+
+	xmlData, err := rss.ToXML()
+	require.NoError(t, err)
+
+	xmlString := string(xmlData)
+	assert.Contains(t, xmlString, `<link xmlns="http://www.w3.org/2005/Atom" href="https://example.com/feed.xml" rel="self" type="application/rss+xml"></link>`)
+	assert.Contains(t, xmlString, `<link xmlns="http://www.w3.org/2005/Atom" href="https://hub.example.com/" rel="hub"></link>`)
+	assert.Contains(t, xmlString, `<link>https://example.com</link>`)
+
+	// Clearing the hub removes the links and the namespace
+	rss.SetHubLinks("", "")
+	xmlData, err = rss.ToXML()
+	require.NoError(t, err)
+	assert.NotContains(t, string(xmlData), "atom")
+}
+
+func TestLoadRSSFromXML_KeepsChannelLinkWithHubLinks(t *testing.T) {
+	rss := CreateRSSFeed("Test Feed", "Test Description", "https://example.com")
+	rss.SetHubLinks("https://hub.example.com/", "https://example.com/feed.xml")
+	xmlData, err := rss.ToXML()
+	require.NoError(t, err)
+
+	loaded, err := LoadRSSFromXML(xmlData)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", loaded.Channel.Link)
+	assert.Equal(t, rss.Channel.AtomLinks, loaded.Channel.AtomLinks)
+
+	// Feeds saved with an atom prefix load the same way
+	loaded, err = LoadRSSFromXML([]byte(`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel><title>Test Feed</title>` +
+		`<link>https://example.com</link><atom:link href="https://hub.example.com/" rel="hub"></atom:link></channel></rss>`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", loaded.Channel.Link)
+	assert.Equal(t, []AtomLink{{Href: "https://hub.example.com/", Rel: "hub"}}, loaded.Channel.AtomLinks)
+}
+
+func TestSaveRSSFeed_ReportsChanges(t *testing.T) {
+	tempFile := t.TempDir() + "/feed.xml"
+	rss := CreateRSSFeed("Test Feed", "Test Description", "https://example.com")
+
+	changed, err := SaveRSSFeed(rss, tempFile)
+	require.NoError(t, err)
+	assert.True(t, changed, "first save writes the file")
+
+	changed, err = SaveRSSFeed(rss, tempFile)
+	require.NoError(t, err)
+	assert.False(t, changed, "saving an identical feed is not a change")
+
+	rss.AddItem("Item", "Desc", "https://example.com/1", "guid-1", time.Now(), "", "", "", "", "Content")
+	changed, err = SaveRSSFeed(rss, tempFile)
+	require.NoError(t, err)
+	assert.True(t, changed, "adding an item is a change")
+}
+
+func TestUpsertItem(t *testing.T) {
+	source := CreateRSSFeed("Source", "Source feed", "https://example.com")
+	pubDate := time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC)
+	source.AddItem("Title", "Desc", "https://example.com/1", "guid-1", pubDate, "Category", "Author", "Source", "Comments", "Content")
+
+	target := CreateRSSFeed("Target", "Target feed", "https://example.com")
+	assert.True(t, target.UpsertItem(source.Channel.Items[0]))
+	assert.False(t, target.UpsertItem(source.Channel.Items[0]))
+
+	require.Len(t, target.Channel.Items, 1)
+	assert.Equal(t, source.Channel.Items[0], target.Channel.Items[0])
+}
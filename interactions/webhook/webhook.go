@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+)
+
+// Endpoint - a webhook URL, signing requests with the secret when one is set
+type Endpoint struct {
+	URL    string
+	Secret string
+	// Retries - how many more times a post is tried after a network error, 429 or 5xx
+	Retries int
+}
+
+// Post - POSTs a JSON payload, retrying with the HTTP policy's backoff and the server's Retry-After when the request fails or the
+// server is unavailable, until the endpoint's retries run out or the context is done
+func Post(ctx context.Context, endpoint Endpoint, payload []byte) error {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if endpoint.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers["X-Devbot-Timestamp"] = timestamp
+		headers["X-Devbot-Signature"] = "sha256=" + Sign(endpoint.Secret, timestamp, payload)
+	}
+
+	response, err := simplehttp.SimplePostRetryContext(ctx, endpoint.URL, headers, payload, endpoint.Retries)
+	if err != nil {
+		return fmt.Errorf("error posting to webhook. Error: %s", err.Error())
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("error posting to webhook. Http Status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign - The hex HMAC-SHA256 of the timestamp and payload, so receivers can check the request came from the bot and is recent
+func Sign(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quickRetries - retries without waiting long, restoring the default policy afterwards
+func quickRetries(t *testing.T) {
+	simplehttp.Configure(simplehttp.Policy{Timeout: time.Second, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	t.Cleanup(func() { simplehttp.Configure(simplehttp.DefaultPolicy) })
+}
+
+func Test_Post_SignsPayload(t *testing.T) {
+	quickRetries(t)
+	payload := []byte(`{"text":"New rezoning"}`)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get("X-Devbot-Timestamp")
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "sha256="+Sign("s3cret", timestamp, body), r.Header.Get("X-Devbot-Signature"))
+		assert.Equal(t, payload, body)
+	}))
+	defer ts.Close()
+
+	require.NoError(t, Post(context.Background(), Endpoint{URL: ts.URL, Secret: "s3cret"}, payload))
+}
+
+func Test_Post_Unsigned(t *testing.T) {
+	quickRetries(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("X-Devbot-Signature"))
+	}))
+	defer ts.Close()
+
+	require.NoError(t, Post(context.Background(), Endpoint{URL: ts.URL}, []byte(`{}`)))
+}
+
+func Test_Post_RetriesServerErrors(t *testing.T) {
+	quickRetries(t)
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	require.NoError(t, Post(context.Background(), Endpoint{URL: ts.URL, Retries: 3}, []byte(`{}`)))
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func Test_Post_GivesUpAfterRetries(t *testing.T) {
+	quickRetries(t)
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	err := Post(context.Background(), Endpoint{URL: ts.URL, Retries: 2}, []byte(`{}`))
+	assert.ErrorContains(t, err, "Http Status 429")
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func Test_Post_DoesNotRetryRejectedRequests(t *testing.T) {
+	quickRetries(t)
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	err := Post(context.Background(), Endpoint{URL: ts.URL, Retries: 3}, []byte(`{}`))
+	assert.ErrorContains(t, err, "Http Status 400")
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func Test_Post_GivesUpWhenRetryAfterIsTooLong(t *testing.T) {
+	quickRetries(t)
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	err := Post(context.Background(), Endpoint{URL: ts.URL, Retries: 3}, []byte(`{}`))
+	assert.ErrorContains(t, err, "Http Status 429")
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func Test_Post_StopsWhenCancelled(t *testing.T) {
+	simplehttp.Configure(simplehttp.Policy{Timeout: time.Second, InitialBackoff: time.Minute, MaxBackoff: time.Minute})
+	t.Cleanup(func() { simplehttp.Configure(simplehttp.DefaultPolicy) })
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := Post(ctx, Endpoint{URL: ts.URL, Retries: 3}, []byte(`{}`))
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	assert.Equal(t, int32(1), attempts.Load())
+}
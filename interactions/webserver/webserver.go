@@ -0,0 +1,200 @@
+package webserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+)
+
+const rssContentType = "application/rss+xml; charset=utf-8"
+
+// Server - serves published feeds from memory along with any extra handlers registered on it
+type Server struct {
+	mux       *http.ServeMux
+	lock      sync.RWMutex
+	documents map[string]*document
+}
+
+// document - a published output held in memory with its pre-compressed form and cache validators
+type document struct {
+	contentType  string
+	body         []byte
+	gzipBody     []byte
+	etag         string
+	lastModified time.Time
+}
+
+// NewServer - creates a server with no published documents
+func NewServer() *Server {
+	server := &Server{
+		mux:       http.NewServeMux(),
+		documents: make(map[string]*document),
+	}
+	server.mux.HandleFunc("GET /", server.ServePublished)
+
+	return server
+}
+
+// HandleFunc - registers an additional handler, more specific patterns take precedence over published documents
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// ServeHTTP - dispatches a request to the registered handlers
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe - serves HTTP on the given address until the listener fails
+func (s *Server) ListenAndServe(address string) error {
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      60 * time.Second,
+	}
+
+	return httpServer.ListenAndServe()
+}
+
+// PublishRSS - renders an RSS feed and makes it available at the given path, replacing any previous version
+func (s *Server) PublishRSS(path string, rss *rssfeed.RSS) error {
+	xmlData, err := rss.ToXML()
+	if err != nil {
+		return fmt.Errorf("failed to publish RSS feed at %s: %v", path, err)
+	}
+
+	doc, err := newDocument(rssContentType, xmlData, rss.Channel.LastBuildDate)
+	if err != nil {
+		return fmt.Errorf("failed to publish RSS feed at %s: %v", path, err)
+	}
+
+	s.lock.Lock()
+	s.documents[path] = doc
+	s.lock.Unlock()
+
+	return nil
+}
+
+// ServeRSS - writes a feed built for a single request, honouring conditional and gzip request headers
+func ServeRSS(w http.ResponseWriter, r *http.Request, rss *rssfeed.RSS) {
+	xmlData, err := rss.ToXML()
+	if err != nil {
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	// Filtered feeds share a build date with the full feed, so the query is folded into the validator
+	doc, err := newDocument(rssContentType, xmlData, rss.Channel.LastBuildDate+"?"+r.URL.RawQuery)
+	if err != nil {
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+	if lastModified, parseErr := time.Parse(time.RFC1123Z, rss.Channel.LastBuildDate); parseErr == nil {
+		doc.lastModified = lastModified
+	}
+
+	doc.serve(w, r)
+}
+
+// WriteJSON - writes a value as an indented JSON response
+func WriteJSON(w http.ResponseWriter, status int, value any) {
+	jsonBytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(jsonBytes)
+}
+
+// WriteJSONError - writes an error message as a JSON response
+func WriteJSONError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, map[string]string{"error": message})
+}
+
+// ServePublished - serves a published document by its request path
+func (s *Server) ServePublished(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	doc, found := s.documents[r.URL.Path]
+	s.lock.RUnlock()
+
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	doc.serve(w, r)
+}
+
+// newDocument - builds a document whose validators are derived from its version string
+func newDocument(contentType string, body []byte, version string) (*document, error) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress document: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress document: %v", err)
+	}
+
+	// LastBuildDate only moves when an item changes, which makes it a stable validator between runs
+	hash := sha256.Sum256([]byte(version))
+	lastModified, err := time.Parse(time.RFC1123Z, version)
+	if err != nil {
+		lastModified = time.Now()
+	}
+
+	return &document{
+		contentType:  contentType,
+		body:         body,
+		gzipBody:     compressed.Bytes(),
+		etag:         hex.EncodeToString(hash[:8]),
+		lastModified: lastModified,
+	}, nil
+}
+
+// serve - writes the document, letting http.ServeContent answer If-None-Match and If-Modified-Since with 304
+func (d *document) serve(w http.ResponseWriter, r *http.Request) {
+	body := d.body
+	etag := d.etag
+	w.Header().Set("Content-Type", d.contentType)
+	w.Header().Set("Vary", "Accept-Encoding")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	if acceptsGzip(r) {
+		body = d.gzipBody
+		etag += "-gzip"
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	w.Header().Set("ETag", fmt.Sprintf("%q", etag))
+
+	http.ServeContent(w, r, "", d.lastModified, bytes.NewReader(body))
+}
+
+// acceptsGzip - checks whether the client will accept a gzip encoded response
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(strings.TrimSpace(encoding), ";")
+		if strings.ToLower(strings.TrimSpace(parts[0])) != "gzip" {
+			continue
+		}
+		if len(parts) > 1 && strings.ReplaceAll(strings.TrimSpace(parts[1]), " ", "") == "q=0" {
+			return false
+		}
+		return true
+	}
+
+	return false
+}
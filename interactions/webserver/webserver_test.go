@@ -0,0 +1,161 @@
+package webserver
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFeed() *rssfeed.RSS {
+	rss := rssfeed.CreateRSSFeed("Test Feed", "Test Description", "https://example.com")
+	rss.AddItem("Item", "Desc", "https://example.com/1", "guid-1", time.Now(), "", "", "", "", "Content")
+	rss.Channel.LastBuildDate = "Sun, 29 Mar 2026 12:57:38 +0000"
+	return rss
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server := NewServer()
+	require.NoError(t, server.PublishRSS("/feed.xml", newTestFeed()))
+
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func Test_PublishRSS_ServesFeed(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/feed.xml")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "Sun, 29 Mar 2026 12:57:38 GMT", resp.Header.Get("Last-Modified"))
+	assert.NotEmpty(t, resp.Header.Get("ETag"))
+	assert.Contains(t, string(body), "<title>Test Feed</title>")
+}
+
+func Test_PublishRSS_UnknownPathIsNotFound(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/missing.xml")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func Test_PublishRSS_IfNoneMatchReturnsNotModified(t *testing.T) {
+	ts := newTestServer(t)
+
+	first, err := http.Get(ts.URL + "/feed.xml")
+	require.NoError(t, err)
+	first.Body.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL+"/feed.xml", nil)
+	req.Header.Set("If-None-Match", first.Header.Get("ETag"))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, body)
+}
+
+func Test_PublishRSS_IfModifiedSinceReturnsNotModified(t *testing.T) {
+	ts := newTestServer(t)
+
+	req, _ := http.NewRequest("GET", ts.URL+"/feed.xml", nil)
+	req.Header.Set("If-Modified-Since", "Sun, 29 Mar 2026 13:00:00 GMT")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func Test_PublishRSS_ChangedFeedGetsNewETag(t *testing.T) {
+	server := NewServer()
+	rss := newTestFeed()
+	require.NoError(t, server.PublishRSS("/feed.xml", rss))
+
+	first := httptest.NewRecorder()
+	server.ServeHTTP(first, httptest.NewRequest("GET", "/feed.xml", nil))
+
+	rss.Channel.LastBuildDate = "Mon, 30 Mar 2026 12:57:38 +0000"
+	require.NoError(t, server.PublishRSS("/feed.xml", rss))
+
+	req := httptest.NewRequest("GET", "/feed.xml", nil)
+	req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	second := httptest.NewRecorder()
+	server.ServeHTTP(second, req)
+
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.NotEqual(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+}
+
+func Test_PublishRSS_GzipWhenAccepted(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.PublishRSS("/feed.xml", newTestFeed()))
+
+	req := httptest.NewRequest("GET", "/feed.xml", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+
+	reader, err := gzip.NewReader(recorder.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "<title>Test Feed</title>")
+}
+
+func Test_AcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.8", true},
+		{"gzip;q=0", false},
+		{"br", false},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", test.header)
+		assert.Equal(t, test.expected, acceptsGzip(req), "Accept-Encoding: %q", test.header)
+	}
+}
+
+func Test_WriteJSON(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	WriteJSON(recorder, http.StatusCreated, map[string]int{"total": 2})
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"total": 2}`, recorder.Body.String())
+}
+
+func Test_WriteJSONError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	WriteJSONError(recorder, http.StatusNotFound, "permit not found")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"error": "permit not found"}`, recorder.Body.String())
+}
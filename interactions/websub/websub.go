@@ -0,0 +1,288 @@
+package websub
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+	"github.com/jeffadavidson/development-bot/utilities/toolbox"
+)
+
+const (
+	defaultLeaseSeconds = 10 * 24 * 60 * 60
+	maxLeaseSeconds     = 30 * 24 * 60 * 60
+
+	// DefaultMaxPerTopic, DefaultMaxSubscriptions - how many subscriptions a hub holds for one topic and in all
+	DefaultMaxPerTopic      = 100
+	DefaultMaxSubscriptions = 500
+	// maxPendingVerifications - how many subscribers are checked at once, requests beyond it are turned away to try later
+	maxPendingVerifications = 8
+)
+
+// PingHub - Tells a WebSub hub that a topic has new content so it can fetch and distribute it
+func PingHub(hubURL string, topicURL string) error {
+	form := url.Values{}
+	form.Set("hub.mode", "publish")
+	form.Set("hub.url", topicURL)
+	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+
+	response, err := simplehttp.SimplePost(hubURL, headers, []byte(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error pinging WebSub hub %s. Error: %s", hubURL, err.Error())
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("error pinging WebSub hub %s. Http Status %d", hubURL, response.StatusCode)
+	}
+
+	return nil
+}
+
+// Hub - A minimal WebSub hub that verifies subscriptions and pushes new content of its topics to subscribers
+type Hub struct {
+	hubURL        string
+	topics        []string
+	lock          sync.Mutex
+	subscriptions map[string]subscription
+	// pending - holds a slot for each verification underway
+	pending          chan struct{}
+	maxPerTopic      int
+	maxSubscriptions int
+	now              func() time.Time
+}
+
+// subscription - a verified subscriber callback for a topic
+type subscription struct {
+	topic     string
+	callback  string
+	secret    string
+	expiresAt time.Time
+}
+
+// NewHub - Creates a hub, reachable at hubURL, that accepts subscriptions to the given topics
+func NewHub(hubURL string, topics ...string) *Hub {
+	return &Hub{
+		hubURL:           hubURL,
+		topics:           topics,
+		subscriptions:    make(map[string]subscription),
+		pending:          make(chan struct{}, maxPendingVerifications),
+		maxPerTopic:      DefaultMaxPerTopic,
+		maxSubscriptions: DefaultMaxSubscriptions,
+		now:              time.Now,
+	}
+}
+
+// ServeHTTP - Accepts subscribe and unsubscribe requests, verifying intent with the subscriber before applying them
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "subscription requests must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	mode := r.PostForm.Get("hub.mode")
+	topic := r.PostForm.Get("hub.topic")
+	callback := r.PostForm.Get("hub.callback")
+	secret := r.PostForm.Get("hub.secret")
+
+	if mode != "subscribe" && mode != "unsubscribe" {
+		http.Error(w, "hub.mode must be subscribe or unsubscribe", http.StatusBadRequest)
+		return
+	}
+	if !toolbox.SliceContains(h.topics, topic) {
+		http.Error(w, "unknown hub.topic", http.StatusNotFound)
+		return
+	}
+	callbackURL, err := url.Parse(callback)
+	if err != nil || (callbackURL.Scheme != "http" && callbackURL.Scheme != "https") || callbackURL.Host == "" {
+		http.Error(w, "hub.callback must be an http or https URL", http.StatusBadRequest)
+		return
+	}
+	if len(secret) >= 200 {
+		http.Error(w, "hub.secret must be less than 200 bytes", http.StatusBadRequest)
+		return
+	}
+
+	leaseSeconds := defaultLeaseSeconds
+	if requested, parseErr := strconv.Atoi(r.PostForm.Get("hub.lease_seconds")); parseErr == nil && requested > 0 {
+		leaseSeconds = min(requested, maxLeaseSeconds)
+	}
+
+	// Renewals are always accepted, new subscribers only while there is room for them
+	if mode == "subscribe" && !h.hasRoom(topic, callback) {
+		http.Error(w, "the hub has no room for more subscriptions", http.StatusTooManyRequests)
+		return
+	}
+	select {
+	case h.pending <- struct{}{}:
+	default:
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "too many subscriptions are being verified, try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Intent is verified after responding, as the spec expects
+	w.WriteHeader(http.StatusAccepted)
+	go func() {
+		defer func() { <-h.pending }()
+		if verifyErr := h.verifyIntent(mode, topic, callback, secret, leaseSeconds); verifyErr != nil {
+			fmt.Println(verifyErr.Error())
+		}
+	}()
+}
+
+// Publish - Pushes new topic content to every active subscriber, returns an error listing failed deliveries
+func (h *Hub) Publish(topic string, contentType string, content []byte) error {
+	var failures []string
+	for _, sub := range h.activeSubscriptions(topic) {
+		headers := map[string]string{
+			"Content-Type": contentType,
+			"Link":         fmt.Sprintf("<%s>; rel=\"hub\", <%s>; rel=\"self\"", h.hubURL, topic),
+		}
+		if sub.secret != "" {
+			mac := hmac.New(sha256.New, []byte(sub.secret))
+			mac.Write(content)
+			headers["X-Hub-Signature"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		}
+
+		response, err := simplehttp.SimplePost(sub.callback, headers, content)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", sub.callback, err.Error()))
+		} else if response.StatusCode == http.StatusGone {
+			// Subscribers signal that they no longer want updates with 410 Gone
+			h.removeSubscription(topic, sub.callback)
+		} else if response.StatusCode < 200 || response.StatusCode > 299 {
+			failures = append(failures, fmt.Sprintf("%s (Http Status %d)", sub.callback, response.StatusCode))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to distribute %s to %d subscriber(s): %s", topic, len(failures), strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// SubscriberCount - The number of active subscriptions to a topic
+func (h *Hub) SubscriberCount(topic string) int {
+	return len(h.activeSubscriptions(topic))
+}
+
+// verifyIntent - confirms the subscriber asked for the change by having it echo a random challenge
+func (h *Hub) verifyIntent(mode, topic, callback, secret string, leaseSeconds int) error {
+	challenge, err := randomChallenge()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("hub.mode", mode)
+	query.Set("hub.topic", topic)
+	query.Set("hub.challenge", challenge)
+	if mode == "subscribe" {
+		query.Set("hub.lease_seconds", strconv.Itoa(leaseSeconds))
+	}
+	separator := "?"
+	if strings.Contains(callback, "?") {
+		separator = "&"
+	}
+
+	response, err := simplehttp.SimpleRequest("GET", callback+separator+query.Encode(), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to verify WebSub %s for %s. Error: %s", mode, callback, err.Error())
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 || string(response.Body) != challenge {
+		return fmt.Errorf("WebSub subscriber %s did not confirm %s", callback, mode)
+	}
+
+	if mode == "unsubscribe" {
+		h.removeSubscription(topic, callback)
+		return nil
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	// Checked again, other subscribers may have taken the room while this one was verified
+	if !h.hasRoomLocked(topic, callback) {
+		return fmt.Errorf("WebSub hub has no room to subscribe %s", callback)
+	}
+	h.subscriptions[topic+" "+callback] = subscription{
+		topic:     topic,
+		callback:  callback,
+		secret:    secret,
+		expiresAt: h.now().Add(time.Duration(leaseSeconds) * time.Second),
+	}
+
+	return nil
+}
+
+// activeSubscriptions - subscriptions to a topic whose lease has not expired, dropping the expired ones
+func (h *Hub) activeSubscriptions(topic string) []subscription {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	var active []subscription
+	for key, sub := range h.subscriptions {
+		if h.now().After(sub.expiresAt) {
+			delete(h.subscriptions, key)
+			continue
+		}
+		if sub.topic == topic {
+			active = append(active, sub)
+		}
+	}
+
+	return active
+}
+
+// hasRoom - true if the callback is already subscribed to the topic or the topic and hub are below their limits
+func (h *Hub) hasRoom(topic string, callback string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.hasRoomLocked(topic, callback)
+}
+
+// hasRoomLocked - hasRoom for callers holding the lock, expired subscriptions do not count
+func (h *Hub) hasRoomLocked(topic string, callback string) bool {
+	if _, found := h.subscriptions[topic+" "+callback]; found {
+		return true
+	}
+
+	total, onTopic := 0, 0
+	for _, sub := range h.subscriptions {
+		if h.now().After(sub.expiresAt) {
+			continue
+		}
+		total++
+		if sub.topic == topic {
+			onTopic++
+		}
+	}
+	return total < h.maxSubscriptions && onTopic < h.maxPerTopic
+}
+
+func (h *Hub) removeSubscription(topic string, callback string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.subscriptions, topic+" "+callback)
+}
+
+func randomChallenge() (string, error) {
+	challengeBytes := make([]byte, 16)
+	if _, err := rand.Read(challengeBytes); err != nil {
+		return "", fmt.Errorf("failed to create WebSub challenge: %v", err)
+	}
+	return hex.EncodeToString(challengeBytes), nil
+}
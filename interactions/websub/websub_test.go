@@ -0,0 +1,213 @@
+package websub
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTopic = "https://example.com/feed.xml"
+
+// testSubscriber - a fake subscriber that confirms intent and records distributed content
+type testSubscriber struct {
+	lock       sync.Mutex
+	confirm    bool
+	deliveries []*http.Request
+	bodies     []string
+}
+
+func (s *testSubscriber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if r.Method == http.MethodGet {
+		if s.confirm {
+			io.WriteString(w, r.URL.Query().Get("hub.challenge"))
+		} else {
+			http.NotFound(w, r)
+		}
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	s.deliveries = append(s.deliveries, r)
+	s.bodies = append(s.bodies, string(body))
+}
+
+func subscribe(t *testing.T, hub *Hub, callback string, secret string) int {
+	form := url.Values{}
+	form.Set("hub.mode", "subscribe")
+	form.Set("hub.topic", testTopic)
+	form.Set("hub.callback", callback)
+	if secret != "" {
+		form.Set("hub.secret", secret)
+	}
+
+	req := httptest.NewRequest("POST", "/websub", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	hub.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func Test_PingHub_SendsPublishRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "publish", r.PostForm.Get("hub.mode"))
+		assert.Equal(t, testTopic, r.PostForm.Get("hub.url"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	assert.NoError(t, PingHub(ts.URL, testTopic))
+}
+
+func Test_PingHub_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	err := PingHub(ts.URL, testTopic)
+	assert.ErrorContains(t, err, "Http Status 400")
+}
+
+func Test_Hub_SubscribeVerifiesAndDistributes(t *testing.T) {
+	subscriber := &testSubscriber{confirm: true}
+	ts := httptest.NewServer(subscriber)
+	defer ts.Close()
+
+	hub := NewHub("https://example.com/websub", testTopic)
+	assert.Equal(t, http.StatusAccepted, subscribe(t, hub, ts.URL+"/callback", "s3cret"))
+	assert.Eventually(t, func() bool { return hub.SubscriberCount(testTopic) == 1 }, time.Second, 10*time.Millisecond)
+
+	content := []byte("<rss></rss>")
+	require.NoError(t, hub.Publish(testTopic, "application/rss+xml", content))
+
+	subscriber.lock.Lock()
+	defer subscriber.lock.Unlock()
+	require.Len(t, subscriber.deliveries, 1)
+	assert.Equal(t, "<rss></rss>", subscriber.bodies[0])
+	assert.Equal(t, "application/rss+xml", subscriber.deliveries[0].Header.Get("Content-Type"))
+	assert.Contains(t, subscriber.deliveries[0].Header.Get("Link"), `<https://example.com/websub>; rel="hub"`)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(content)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), subscriber.deliveries[0].Header.Get("X-Hub-Signature"))
+}
+
+func Test_Hub_UnconfirmedSubscriptionIsIgnored(t *testing.T) {
+	subscriber := &testSubscriber{confirm: false}
+	ts := httptest.NewServer(subscriber)
+	defer ts.Close()
+
+	hub := NewHub("https://example.com/websub", testTopic)
+	err := hub.verifyIntent("subscribe", testTopic, ts.URL+"/callback", "", defaultLeaseSeconds)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, hub.SubscriberCount(testTopic))
+}
+
+func Test_Hub_Unsubscribe(t *testing.T) {
+	subscriber := &testSubscriber{confirm: true}
+	ts := httptest.NewServer(subscriber)
+	defer ts.Close()
+
+	hub := NewHub("https://example.com/websub", testTopic)
+	require.NoError(t, hub.verifyIntent("subscribe", testTopic, ts.URL+"/callback", "", defaultLeaseSeconds))
+	assert.Equal(t, 1, hub.SubscriberCount(testTopic))
+
+	require.NoError(t, hub.verifyIntent("unsubscribe", testTopic, ts.URL+"/callback", "", defaultLeaseSeconds))
+	assert.Equal(t, 0, hub.SubscriberCount(testTopic))
+}
+
+func Test_Hub_ExpiredLeaseIsDropped(t *testing.T) {
+	subscriber := &testSubscriber{confirm: true}
+	ts := httptest.NewServer(subscriber)
+	defer ts.Close()
+
+	hub := NewHub("https://example.com/websub", testTopic)
+	require.NoError(t, hub.verifyIntent("subscribe", testTopic, ts.URL+"/callback", "", 60))
+	assert.Equal(t, 1, hub.SubscriberCount(testTopic))
+
+	hub.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	assert.Equal(t, 0, hub.SubscriberCount(testTopic))
+}
+
+func Test_Hub_RejectsInvalidRequests(t *testing.T) {
+	hub := NewHub("https://example.com/websub", testTopic)
+
+	tests := []struct {
+		form     url.Values
+		expected int
+	}{
+		{url.Values{"hub.mode": {"publish"}, "hub.topic": {testTopic}, "hub.callback": {"https://reader.example.com/cb"}}, http.StatusBadRequest},
+		{url.Values{"hub.mode": {"subscribe"}, "hub.topic": {"https://other.example.com/feed"}, "hub.callback": {"https://reader.example.com/cb"}}, http.StatusNotFound},
+		{url.Values{"hub.mode": {"subscribe"}, "hub.topic": {testTopic}, "hub.callback": {"ftp://reader.example.com/cb"}}, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/websub", strings.NewReader(test.form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		hub.ServeHTTP(recorder, req)
+		assert.Equal(t, test.expected, recorder.Code, test.form.Encode())
+	}
+
+	recorder := httptest.NewRecorder()
+	hub.ServeHTTP(recorder, httptest.NewRequest("GET", "/websub", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func Test_Hub_LimitsSubscriptions(t *testing.T) {
+	subscriber := &testSubscriber{confirm: true}
+	ts := httptest.NewServer(subscriber)
+	defer ts.Close()
+
+	hub := NewHub("https://example.com/websub", testTopic, "https://example.com/other.xml")
+	hub.maxPerTopic = 1
+	hub.maxSubscriptions = 2
+	require.NoError(t, hub.verifyIntent("subscribe", testTopic, ts.URL+"/first", "", defaultLeaseSeconds))
+
+	// The topic is full, but its subscriber can still renew
+	assert.Equal(t, http.StatusTooManyRequests, subscribe(t, hub, ts.URL+"/second", ""))
+	assert.Error(t, hub.verifyIntent("subscribe", testTopic, ts.URL+"/second", "", defaultLeaseSeconds))
+	assert.Equal(t, http.StatusAccepted, subscribe(t, hub, ts.URL+"/first", ""))
+	assert.Eventually(t, func() bool { return len(hub.pending) == 0 }, time.Second, 10*time.Millisecond)
+
+	// The hub is full once every topic's subscriptions add up to its limit
+	require.NoError(t, hub.verifyIntent("subscribe", "https://example.com/other.xml", ts.URL+"/second", "", defaultLeaseSeconds))
+	hub.maxPerTopic = 10
+	assert.Equal(t, http.StatusTooManyRequests, subscribe(t, hub, ts.URL+"/third", ""))
+
+	// Expired subscriptions make room again
+	hub.now = func() time.Time { return time.Now().Add(maxLeaseSeconds * time.Second) }
+	assert.Equal(t, http.StatusAccepted, subscribe(t, hub, ts.URL+"/third", ""))
+}
+
+func Test_Hub_LimitsPendingVerifications(t *testing.T) {
+	hub := NewHub("https://example.com/websub", testTopic)
+	for i := 0; i < cap(hub.pending); i++ {
+		hub.pending <- struct{}{}
+	}
+
+	form := url.Values{"hub.mode": {"subscribe"}, "hub.topic": {testTopic}, "hub.callback": {"https://reader.example.com/cb"}}
+	req := httptest.NewRequest("POST", "/websub", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	hub.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+}
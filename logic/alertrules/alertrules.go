@@ -0,0 +1,249 @@
+package alertrules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/logic/feedfilter"
+	"github.com/jeffadavidson/development-bot/objects/community"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/toolbox"
+)
+
+// defaultRadiusMeters - radius used when a rule gives a near point without one
+const defaultRadiusMeters = 500
+
+// feedNamePattern - rule feeds are written to ./output/<feed>.xml, so names are limited to safe file names
+var feedNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Rule - a compiled alert rule, every populated criteria must match for an action to be routed
+type Rule struct {
+	Name            string
+	Datasets        []string
+	Actions         []string
+	Categories      []string
+	StatusFrom      []string
+	StatusTo        []string
+	ProposedLandUse []string
+	// Increases - land use limits, such as max-height, that a rezoning must raise
+	Increases         []string
+	ApplicantContains []string
+	Keywords          []string
+	Near              *geo.Point
+	RadiusMeters      float64
+	Notify            []string
+	Feed              string
+}
+
+// Routes - the actions each notifier and rule feed should receive
+type Routes struct {
+	Notifiers map[string][]fileaction.FileAction
+	Feeds     map[string][]fileaction.FileAction
+}
+
+// Compile - Validates alert rules from the config, checking they only notify known notifiers
+func Compile(settings []config.AlertRule, notifierNames []string) ([]Rule, error) {
+	var rules []Rule
+	for i, setting := range settings {
+		name := setting.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+
+		rule := Rule{
+			Name:              name,
+			Actions:           normalize(setting.Match.Actions, strings.ToUpper),
+			Categories:        normalize(setting.Match.Categories, strings.ToLower),
+			StatusFrom:        normalize(setting.Match.StatusFrom, strings.ToLower),
+			StatusTo:          normalize(setting.Match.StatusTo, strings.ToLower),
+			ProposedLandUse:   normalize(setting.Match.ProposedLandUse, strings.ToUpper),
+			ApplicantContains: normalize(setting.Match.ApplicantContains, strings.ToLower),
+			Keywords:          normalize(setting.Match.Keywords, strings.ToLower),
+			Notify:            setting.Notify,
+			Feed:              setting.Feed,
+		}
+
+		for _, value := range setting.Match.Datasets {
+			dataset, err := feedfilter.ParseDataset(value)
+			if err != nil {
+				return nil, fmt.Errorf("alert rule %s: %v", name, err)
+			}
+			rule.Datasets = append(rule.Datasets, dataset)
+		}
+		for _, value := range setting.Match.Increases {
+			aspect, err := landuse.ParseLimit(value)
+			if err != nil {
+				return nil, fmt.Errorf("alert rule %s: %v", name, err)
+			}
+			rule.Increases = append(rule.Increases, aspect)
+		}
+		for _, action := range rule.Actions {
+			if action != "CREATE" && action != "UPDATE" && action != "CLOSE" {
+				return nil, fmt.Errorf("alert rule %s: unknown action '%s', expected create, update or close", name, action)
+			}
+		}
+
+		if setting.Match.Near != "" {
+			point, err := geo.ParsePoint(setting.Match.Near)
+			if err != nil {
+				return nil, fmt.Errorf("alert rule %s: %v", name, err)
+			}
+			rule.Near = &point
+			rule.RadiusMeters = defaultRadiusMeters
+		}
+		if setting.Match.Radius != "" {
+			if rule.Near == nil {
+				return nil, fmt.Errorf("alert rule %s: radius requires a near point", name)
+			}
+			radiusMeters, err := geo.ParseDistance(setting.Match.Radius)
+			if err != nil {
+				return nil, fmt.Errorf("alert rule %s: %v", name, err)
+			}
+			rule.RadiusMeters = radiusMeters
+		}
+
+		if len(rule.Notify) == 0 && rule.Feed == "" {
+			return nil, fmt.Errorf("alert rule %s must notify someone or write a feed", name)
+		}
+		for _, notifier := range rule.Notify {
+			if !toolbox.SliceContains(notifierNames, notifier) {
+				return nil, fmt.Errorf("alert rule %s notifies '%s', which is not a configured notifier", name, notifier)
+			}
+		}
+		if rule.Feed != "" && !feedNamePattern.MatchString(rule.Feed) {
+			return nil, fmt.Errorf("alert rule %s feed '%s' must only use lower case letters, numbers and dashes", name, rule.Feed)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// Matches - Checks if an action passes every criteria of the rule
+func (r Rule) Matches(action fileaction.FileAction) bool {
+	record := action.Record
+
+	if len(r.Datasets) > 0 && !toolbox.SliceContains(r.Datasets, record.Dataset) {
+		return false
+	}
+	if len(r.Actions) > 0 && !toolbox.SliceContains(r.Actions, action.Action) {
+		return false
+	}
+	if len(r.Categories) > 0 && !toolbox.SliceContains(r.Categories, strings.ToLower(strings.TrimSpace(record.Category))) {
+		return false
+	}
+	// New records have no previous status, so they never match a transition from a status
+	if len(r.StatusFrom) > 0 && !toolbox.SliceContains(r.StatusFrom, strings.ToLower(strings.TrimSpace(action.PreviousStatus))) {
+		return false
+	}
+	if len(r.StatusTo) > 0 && !toolbox.SliceContains(r.StatusTo, strings.ToLower(strings.TrimSpace(record.Status))) {
+		return false
+	}
+	if len(r.ProposedLandUse) > 0 && !matchesLandUse(r.ProposedLandUse, record.ProposedLandUse) {
+		return false
+	}
+	if len(r.Increases) > 0 && !record.IncreasesAny(r.Increases) {
+		return false
+	}
+	if len(r.ApplicantContains) > 0 && !containsAny(record.Applicant, r.ApplicantContains) {
+		return false
+	}
+	if len(r.Keywords) > 0 && !containsAny(record.Description+"\n"+record.Summary, r.Keywords) {
+		return false
+	}
+	if r.Near != nil {
+		if record.Location == nil || geo.DistanceMeters(*r.Near, *record.Location) > r.RadiusMeters {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Route - Sends each action to the notifiers and feeds of every rule it matches
+// Every notifier and feed named by a rule gets an entry, even when nothing matched, so it can tell it was routed to
+func Route(rules []Rule, actions []fileaction.FileAction) Routes {
+	routes := Routes{
+		Notifiers: make(map[string][]fileaction.FileAction),
+		Feeds:     make(map[string][]fileaction.FileAction),
+	}
+
+	for _, rule := range rules {
+		for _, notifier := range rule.Notify {
+			if _, found := routes.Notifiers[notifier]; !found {
+				routes.Notifiers[notifier] = []fileaction.FileAction{}
+			}
+		}
+		if rule.Feed != "" {
+			if _, found := routes.Feeds[rule.Feed]; !found {
+				routes.Feeds[rule.Feed] = []fileaction.FileAction{}
+			}
+		}
+	}
+
+	for _, action := range actions {
+		// An action matched by several rules for the same destination is only sent once
+		notified := map[string]bool{}
+		fed := map[string]bool{}
+		for _, rule := range rules {
+			if !rule.Matches(action) {
+				continue
+			}
+			// Subscribers who watch a point are told how far the action is from it rather than from the reference point
+			routed := action
+			if rule.Near != nil {
+				routed.Record.Position = community.AnnotateFrom(action.Record.Location, *rule.Near, rule.Name)
+			}
+			for _, notifier := range rule.Notify {
+				if !notified[notifier] {
+					notified[notifier] = true
+					routes.Notifiers[notifier] = append(routes.Notifiers[notifier], routed)
+				}
+			}
+			if rule.Feed != "" && !fed[rule.Feed] {
+				fed[rule.Feed] = true
+				routes.Feeds[rule.Feed] = append(routes.Feeds[rule.Feed], action)
+			}
+		}
+	}
+
+	return routes
+}
+
+// matchesLandUse - checks a land use district against rule values, a value matches the whole district or its base district
+func matchesLandUse(values []string, district string) bool {
+	district = strings.ToUpper(strings.TrimSpace(district))
+	for _, value := range values {
+		if district == value || strings.HasPrefix(district, value+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny - checks if text contains any of the lower case terms, ignoring case
+func containsAny(text string, terms []string) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize - trims values and converts their case, dropping empty values
+func normalize(values []string, convert func(string) string) []string {
+	var normalized []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			normalized = append(normalized, convert(value))
+		}
+	}
+	return normalized
+}
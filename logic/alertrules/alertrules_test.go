@@ -0,0 +1,188 @@
+package alertrules
+
+import (
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rezoningDecision(proposedLandUse string) fileaction.FileAction {
+	return fileaction.FileAction{
+		PermitNum:      "LOC2026-0023",
+		Action:         "CLOSE",
+		PreviousStatus: "Under Review",
+		Record: activity.Record{
+			Dataset:         activity.RezoningApplication,
+			PermitNum:       "LOC2026-0023",
+			Status:          "Approved",
+			Category:        "Land Use",
+			Description:     "Rezoning to allow rowhouses near the LRT",
+			Applicant:       "Civicworks Planning + Design",
+			ProposedLandUse: proposedLandUse,
+			Location:        &geo.Point{Latitude: 51.0302, Longitude: -114.1295},
+		},
+	}
+}
+
+func newPermit() fileaction.FileAction {
+	return fileaction.FileAction{
+		PermitNum: "DP2026-01776",
+		Action:    "CREATE",
+		Record: activity.Record{
+			Dataset:     activity.DevelopmentPermit,
+			Status:      "Submitted",
+			Category:    "Residential - Semi-detached",
+			Description: "New: Semi-detached Dwelling, Secondary Suite",
+			Applicant:   "Sabal Homes",
+			Location:    &geo.Point{Latitude: 51.0288, Longitude: -114.1401},
+		},
+	}
+}
+
+func compileOne(t *testing.T, match config.AlertMatch) Rule {
+	rules, err := Compile([]config.AlertRule{{Name: "test", Match: match, Notify: []string{"email"}}}, []string{"email"})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	return rules[0]
+}
+
+func Test_Rule_CommitteeRezoningDecisions(t *testing.T) {
+	rule := compileOne(t, config.AlertMatch{Datasets: []string{"rezoning"}, Actions: []string{"close"}, ProposedLandUse: []string{"R-CG", "h-go"}})
+
+	assert.True(t, rule.Matches(rezoningDecision("R-CG")))
+	assert.True(t, rule.Matches(rezoningDecision("H-GO")))
+	assert.False(t, rule.Matches(rezoningDecision("MU-1 f3.0h16")))
+	assert.False(t, rule.Matches(newPermit()))
+
+	opened := rezoningDecision("R-CG")
+	opened.Action = "CREATE"
+	assert.False(t, rule.Matches(opened))
+}
+
+func Test_Rule_ProposedLandUseMatchesBaseDistrict(t *testing.T) {
+	rule := compileOne(t, config.AlertMatch{ProposedLandUse: []string{"MU-1"}})
+
+	assert.True(t, rule.Matches(rezoningDecision("MU-1 f3.0h16")))
+	assert.False(t, rule.Matches(rezoningDecision("MU-2 f3.0h16")))
+}
+
+func Test_Rule_IncreasesHeight(t *testing.T) {
+	rule := compileOne(t, config.AlertMatch{Datasets: []string{"rezoning"}, Increases: []string{"height"}})
+
+	rezoning := func(from string, to string) fileaction.FileAction {
+		action := rezoningDecision(to)
+		action.Record.CurrentDistricts = landuse.Explain(from)
+		action.Record.ProposedDistricts = landuse.Explain(to)
+		return action
+	}
+
+	assert.True(t, rule.Matches(rezoning("R-CG", "H-GO")))
+	assert.True(t, rule.Matches(rezoning("MU-1 f3.0h16", "MU-1 f4.5h23")))
+	assert.False(t, rule.Matches(rezoning("MU-1 f3.0h16", "MU-1 f4.5h16")))
+	assert.False(t, rule.Matches(rezoning("DC", "R-CG")))
+	assert.False(t, rule.Matches(newPermit()))
+}
+
+func Test_Rule_StatusTransition(t *testing.T) {
+	rule := compileOne(t, config.AlertMatch{StatusFrom: []string{"under review"}, StatusTo: []string{"Approved", "Refused"}})
+
+	assert.True(t, rule.Matches(rezoningDecision("R-CG")))
+
+	fromHold := rezoningDecision("R-CG")
+	fromHold.PreviousStatus = "Hold"
+	assert.False(t, rule.Matches(fromHold))
+	assert.False(t, rule.Matches(newPermit()))
+}
+
+func Test_Rule_TextPredicates(t *testing.T) {
+	applicant := compileOne(t, config.AlertMatch{ApplicantContains: []string{"sabal"}})
+	keyword := compileOne(t, config.AlertMatch{Keywords: []string{"secondary suite", "rowhouse"}})
+	category := compileOne(t, config.AlertMatch{Categories: []string{"residential - semi-detached"}})
+
+	assert.True(t, applicant.Matches(newPermit()))
+	assert.False(t, applicant.Matches(rezoningDecision("R-CG")))
+	assert.True(t, keyword.Matches(newPermit()))
+	assert.True(t, keyword.Matches(rezoningDecision("R-CG")))
+	assert.True(t, category.Matches(newPermit()))
+	assert.False(t, category.Matches(rezoningDecision("R-CG")))
+}
+
+func Test_Rule_Distance(t *testing.T) {
+	rule := compileOne(t, config.AlertMatch{Near: "51.0300,-114.1300", Radius: "100m"})
+
+	assert.True(t, rule.Matches(rezoningDecision("R-CG")))
+	assert.False(t, rule.Matches(newPermit()))
+
+	noLocation := newPermit()
+	noLocation.Record.Location = nil
+	assert.False(t, rule.Matches(noLocation))
+}
+
+func Test_Compile_Errors(t *testing.T) {
+	tests := []struct {
+		rule     config.AlertRule
+		expected string
+	}{
+		{config.AlertRule{Name: "a", Match: config.AlertMatch{Datasets: []string{"building"}}, Notify: []string{"email"}}, "unknown type 'building'"},
+		{config.AlertRule{Name: "b", Match: config.AlertMatch{Actions: []string{"delete"}}, Notify: []string{"email"}}, "unknown action 'DELETE'"},
+		{config.AlertRule{Name: "c", Match: config.AlertMatch{Radius: "1km"}, Notify: []string{"email"}}, "radius requires a near point"},
+		{config.AlertRule{Name: "d", Match: config.AlertMatch{Near: "north"}, Notify: []string{"email"}}, "alert rule d"},
+		{config.AlertRule{Name: "e", Notify: []string{"pager"}}, "'pager', which is not a configured notifier"},
+		{config.AlertRule{Name: "f"}, "must notify someone or write a feed"},
+		{config.AlertRule{Name: "g", Feed: "../secrets"}, "feed '../secrets'"},
+		{config.AlertRule{Name: "h", Match: config.AlertMatch{Increases: []string{"width"}}, Notify: []string{"email"}}, "alert rule h: unknown land use limit 'width'"},
+	}
+
+	for _, test := range tests {
+		_, err := Compile([]config.AlertRule{test.rule}, []string{"email"})
+		assert.ErrorContains(t, err, test.expected, test.rule.Name)
+	}
+}
+
+func Test_Route(t *testing.T) {
+	rules, err := Compile([]config.AlertRule{
+		{Name: "decisions", Match: config.AlertMatch{Actions: []string{"close"}}, Notify: []string{"board-slack", "email"}, Feed: "decisions"},
+		{Name: "rezonings", Match: config.AlertMatch{Datasets: []string{"rezoning"}}, Notify: []string{"board-slack"}},
+		{Name: "applicant", Match: config.AlertMatch{ApplicantContains: []string{"nobody"}}, Notify: []string{"mastodon"}},
+	}, []string{"board-slack", "email", "mastodon"})
+	require.NoError(t, err)
+
+	routes := Route(rules, []fileaction.FileAction{newPermit(), rezoningDecision("R-CG")})
+
+	// The rezoning decision matches two rules for board-slack but is only sent once
+	require.Len(t, routes.Notifiers["board-slack"], 1)
+	assert.Equal(t, "LOC2026-0023", routes.Notifiers["board-slack"][0].PermitNum)
+	assert.Len(t, routes.Notifiers["email"], 1)
+	assert.Len(t, routes.Feeds["decisions"], 1)
+
+	// Notifiers named by rules get an empty entry when nothing matched
+	notified, found := routes.Notifiers["mastodon"]
+	assert.True(t, found)
+	assert.Empty(t, notified)
+}
+
+func Test_Route_MeasuresFromSubscriberPoint(t *testing.T) {
+	rules, err := Compile([]config.AlertRule{
+		{Name: "my-block", Match: config.AlertMatch{Near: "51.0300,-114.1300", Radius: "500m"}, Notify: []string{"email"}},
+	}, []string{"email"})
+	require.NoError(t, err)
+
+	action := rezoningDecision("R-CG")
+	routes := Route(rules, []fileaction.FileAction{action})
+
+	require.Len(t, routes.Notifiers["email"], 1)
+	position := routes.Notifiers["email"][0].Record.Position
+	require.NotNil(t, position)
+	assert.Equal(t, 41.0, *position.DistanceMeters)
+	assert.Equal(t, "NE", position.Direction)
+	assert.Equal(t, "my-block", position.Reference)
+
+	// The action itself is left as it was for other destinations
+	assert.Nil(t, action.Record.Position)
+}
@@ -0,0 +1,225 @@
+package examinedata
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/logic/feedfilter"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+	defaultSort      = "-applieddate"
+)
+
+// recordPage - one page of a sorted and filtered list of records
+type recordPage[T any] struct {
+	Total  int    `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Sort   string `json:"sort"`
+	Items  []T    `json:"items"`
+}
+
+// stateHistoryResponse - the lifecycle of a single permit or application
+type stateHistoryResponse[T any] struct {
+	PermitNum    string `json:"permitnum"`
+	Status       string `json:"statuscurrent"`
+	StateHistory []T    `json:"state_history"`
+}
+
+// sortKeys - record fields that lists can be sorted by
+var sortKeys = map[string]func(a activity.Record, b activity.Record) int{
+	"permitnum": func(a activity.Record, b activity.Record) int {
+		return strings.Compare(a.PermitNum, b.PermitNum)
+	},
+	"status": func(a activity.Record, b activity.Record) int {
+		return strings.Compare(strings.ToLower(a.Status), strings.ToLower(b.Status))
+	},
+	"applieddate": func(a activity.Record, b activity.Record) int {
+		return a.AppliedDate.Compare(b.AppliedDate)
+	},
+	"updated": func(a activity.Record, b activity.Record) int {
+		return a.LastUpdated.Compare(b.LastUpdated)
+	},
+}
+
+// registerAPI - adds the read only JSON API over the stored records to the server
+func registerAPI(server *webserver.Server, snapshot *activitySnapshot) {
+	server.HandleFunc("GET /api/permits", snapshot.listDevelopmentPermits)
+	server.HandleFunc("GET /api/permits/{permitnum}", snapshot.getDevelopmentPermit)
+	server.HandleFunc("GET /api/permits/{permitnum}/history", snapshot.getDevelopmentPermitHistory)
+	server.HandleFunc("GET /api/rezonings", snapshot.listRezoningApplications)
+	server.HandleFunc("GET /api/rezonings/{permitnum}", snapshot.getRezoningApplication)
+	server.HandleFunc("GET /api/rezonings/{permitnum}/history", snapshot.getRezoningApplicationHistory)
+	server.HandleFunc("GET /api/parcels", snapshot.listParcels)
+	server.HandleFunc("GET /api/parcels/{address}", snapshot.getParcel)
+}
+
+func (s *activitySnapshot) listDevelopmentPermits(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	page, err := buildRecordPage(s.developmentPermits, developmentpermit.DevelopmentPermit.ActivityRecord, r.URL.Query())
+	if err != nil {
+		webserver.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, page)
+}
+
+func (s *activitySnapshot) getDevelopmentPermit(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	dp := findByPermitNum(s.developmentPermits, developmentpermit.DevelopmentPermit.ActivityRecord, r.PathValue("permitnum"))
+	if dp == nil {
+		webserver.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("development permit %s not found", r.PathValue("permitnum")))
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, dp)
+}
+
+func (s *activitySnapshot) getDevelopmentPermitHistory(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	dp := findByPermitNum(s.developmentPermits, developmentpermit.DevelopmentPermit.ActivityRecord, r.PathValue("permitnum"))
+	if dp == nil {
+		webserver.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("development permit %s not found", r.PathValue("permitnum")))
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, stateHistoryResponse[developmentpermit.StateChange]{
+		PermitNum:    dp.PermitNum,
+		Status:       dp.StatusCurrent,
+		StateHistory: dp.StateHistory,
+	})
+}
+
+func (s *activitySnapshot) listRezoningApplications(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	page, err := buildRecordPage(s.rezoningApplications, rezoningapplications.RezoningApplication.ActivityRecord, r.URL.Query())
+	if err != nil {
+		webserver.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, page)
+}
+
+func (s *activitySnapshot) getRezoningApplication(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ra := findByPermitNum(s.rezoningApplications, rezoningapplications.RezoningApplication.ActivityRecord, r.PathValue("permitnum"))
+	if ra == nil {
+		webserver.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("rezoning application %s not found", r.PathValue("permitnum")))
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, ra)
+}
+
+func (s *activitySnapshot) getRezoningApplicationHistory(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ra := findByPermitNum(s.rezoningApplications, rezoningapplications.RezoningApplication.ActivityRecord, r.PathValue("permitnum"))
+	if ra == nil {
+		webserver.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("rezoning application %s not found", r.PathValue("permitnum")))
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, stateHistoryResponse[rezoningapplications.StateChange]{
+		PermitNum:    ra.PermitNum,
+		Status:       ra.StatusCurrent,
+		StateHistory: ra.StateHistory,
+	})
+}
+
+// findByPermitNum - finds a record by permit number, ignoring case
+func findByPermitNum[T any](records []T, toRecord func(T) activity.Record, permitNum string) *T {
+	for i := range records {
+		if strings.EqualFold(toRecord(records[i]).PermitNum, permitNum) {
+			return &records[i]
+		}
+	}
+
+	return nil
+}
+
+// buildRecordPage - filters, sorts and pages records using the limit, offset, sort and feed filter query parameters
+func buildRecordPage[T any](records []T, toRecord func(T) activity.Record, query url.Values) (recordPage[T], error) {
+	page := recordPage[T]{Limit: defaultPageLimit, Sort: defaultSort, Items: []T{}}
+
+	filter, err := feedfilter.ParseQuery(query)
+	if err != nil {
+		return page, err
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil || page.Limit < 1 || page.Limit > maxPageLimit {
+			return page, fmt.Errorf("limit must be a number from 1 to %d", maxPageLimit)
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		page.Offset, err = strconv.Atoi(offset)
+		if err != nil || page.Offset < 0 {
+			return page, fmt.Errorf("offset must be a positive number")
+		}
+	}
+	if sortParam := query.Get("sort"); sortParam != "" {
+		page.Sort = strings.ToLower(sortParam)
+	}
+	compare, found := sortKeys[strings.TrimPrefix(page.Sort, "-")]
+	if !found {
+		return page, fmt.Errorf("sort must be one of permitnum, status, applieddate or updated, prefixed with - for descending")
+	}
+	descending := strings.HasPrefix(page.Sort, "-")
+
+	// Pair records with their views so each view is only built once
+	type pairedRecord struct {
+		record activity.Record
+		item   T
+	}
+	var matched []pairedRecord
+	for _, item := range records {
+		record := toRecord(item)
+		if filter.Matches(record) {
+			matched = append(matched, pairedRecord{record: record, item: item})
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		result := compare(matched[i].record, matched[j].record)
+		if result == 0 {
+			result = strings.Compare(matched[i].record.PermitNum, matched[j].record.PermitNum)
+		}
+		if descending {
+			return result > 0
+		}
+		return result < 0
+	})
+
+	page.Total = len(matched)
+	for i := page.Offset; i < len(matched) && i < page.Offset+page.Limit; i++ {
+		page.Items = append(page.Items, matched[i].item)
+	}
+
+	return page, nil
+}
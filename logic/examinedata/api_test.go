@@ -0,0 +1,185 @@
+package examinedata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/parcel"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIServer() (*webserver.Server, *activitySnapshot) {
+	snapshot := testSnapshot()
+	snapshot.developmentPermits = append(snapshot.developmentPermits,
+		developmentpermit.DevelopmentPermit{
+			PermitNum:     "DP2026-01757",
+			StatusCurrent: "Under Review",
+			Ward:          stringPointer("8"),
+			AppliedDate:   stringPointer("2026-03-26T00:00:00.000"),
+			StateHistory: []developmentpermit.StateChange{
+				{Status: "hold", Timestamp: "2026-03-28T12:56:43Z"},
+				{Status: "under review", Timestamp: "2026-03-29T12:57:38Z"},
+			},
+		},
+		developmentpermit.DevelopmentPermit{
+			PermitNum:     "DP2026-01738",
+			StatusCurrent: "New",
+			Ward:          stringPointer("8"),
+			AppliedDate:   stringPointer("2026-03-28T00:00:00.000"),
+		},
+	)
+
+	server := webserver.NewServer()
+	registerAPI(server, snapshot)
+	return server, snapshot
+}
+
+func getJSON(t *testing.T, server *webserver.Server, path string, target any) int {
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+	if target != nil && recorder.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), target))
+	}
+	return recorder.Code
+}
+
+func Test_API_ListPermits_DefaultSortNewestApplied(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	var page recordPage[developmentpermit.DevelopmentPermit]
+	status := getJSON(t, server, "/api/permits", &page)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, "-applieddate", page.Sort)
+	require.Len(t, page.Items, 3)
+	assert.Equal(t, "DP2026-01738", page.Items[0].PermitNum)
+	assert.Equal(t, "DP2026-01776", page.Items[1].PermitNum)
+	assert.Equal(t, "DP2026-01757", page.Items[2].PermitNum)
+}
+
+func Test_API_ListPermits_PagingAndSorting(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	var page recordPage[developmentpermit.DevelopmentPermit]
+	status := getJSON(t, server, "/api/permits?sort=permitnum&limit=1&offset=1", &page)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, 1, page.Limit)
+	assert.Equal(t, 1, page.Offset)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "DP2026-01757", page.Items[0].PermitNum)
+}
+
+func Test_API_ListPermits_Filtered(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	var page recordPage[developmentpermit.DevelopmentPermit]
+	status := getJSON(t, server, "/api/permits?ward=8&status=new", &page)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, page.Total)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "DP2026-01738", page.Items[0].PermitNum)
+}
+
+func Test_API_ListPermits_OffsetPastEnd(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	var page recordPage[developmentpermit.DevelopmentPermit]
+	status := getJSON(t, server, "/api/permits?offset=10", &page)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 3, page.Total)
+	assert.Empty(t, page.Items)
+}
+
+func Test_API_ListPermits_InvalidParameters(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	for _, path := range []string{
+		"/api/permits?limit=0",
+		"/api/permits?limit=1000",
+		"/api/permits?offset=-1",
+		"/api/permits?sort=applicant",
+		"/api/permits?since=tomorrow",
+	} {
+		assert.Equal(t, http.StatusBadRequest, getJSON(t, server, path, nil), path)
+	}
+}
+
+func Test_API_GetPermit(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	var dp developmentpermit.DevelopmentPermit
+	status := getJSON(t, server, "/api/permits/dp2026-01757", &dp)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "DP2026-01757", dp.PermitNum)
+	assert.Equal(t, "Under Review", dp.StatusCurrent)
+}
+
+func Test_API_GetPermit_NotFound(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	assert.Equal(t, http.StatusNotFound, getJSON(t, server, "/api/permits/DP1999-00001", nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, server, "/api/permits/DP1999-00001/history", nil))
+}
+
+func Test_API_GetPermitHistory(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	var history stateHistoryResponse[developmentpermit.StateChange]
+	status := getJSON(t, server, "/api/permits/DP2026-01757/history", &history)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "DP2026-01757", history.PermitNum)
+	require.Len(t, history.StateHistory, 2)
+	assert.Equal(t, "hold", history.StateHistory[0].Status)
+	assert.Equal(t, "under review", history.StateHistory[1].Status)
+}
+
+func Test_API_Rezonings(t *testing.T) {
+	server, _ := newTestAPIServer()
+
+	var page recordPage[rezoningapplications.RezoningApplication]
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/rezonings", &page))
+	assert.Equal(t, 1, page.Total)
+
+	var ra rezoningapplications.RezoningApplication
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/rezonings/LOC2026-0023", &ra))
+	assert.Equal(t, "LOC2026-0023", ra.PermitNum)
+
+	var history stateHistoryResponse[rezoningapplications.StateChange]
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/rezonings/LOC2026-0023/history", &history))
+	assert.Equal(t, "Under Review", history.Status)
+}
+
+func Test_API_Parcels(t *testing.T) {
+	server, snapshot := newTestAPIServer()
+	snapshot.parcels = parcel.Index{}
+	snapshot.parcels.Add([]activity.Record{
+		{Dataset: activity.RezoningApplication, PermitNum: "LOC2026-0009", Address: "2823 30 ST SW", Status: "Miscellaneous"},
+		{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00867", Address: "2823 30 ST SW", Status: "Hold"},
+	})
+
+	var parcels []parcel.Parcel
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/parcels", &parcels))
+	require.Len(t, parcels, 1)
+
+	var found parcel.Parcel
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/parcels/"+url.PathEscape("#2 2823 30 st sw"), &found))
+	assert.Equal(t, "2823 30 ST SW", found.Address)
+	assert.Len(t, found.Applications, 2)
+
+	assert.Equal(t, http.StatusNotFound, getJSON(t, server, "/api/parcels/1%20MAIN%20ST%20SW", nil))
+}
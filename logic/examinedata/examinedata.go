@@ -1,53 +1,230 @@
 package examinedata
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jeffadavidson/development-bot/interactions/calgaryopendata"
 	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/logic/alertrules"
+	"github.com/jeffadavidson/development-bot/logic/notifications"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/community"
 	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/fetchstate"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
 	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+	"github.com/jeffadavidson/development-bot/objects/selection"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+	"github.com/jeffadavidson/development-bot/utilities/templates"
+	"github.com/jeffadavidson/development-bot/utilities/toolbox"
+	"github.com/jeffadavidson/development-bot/utilities/workpool"
 )
 
+// combinedFeedPath - location of the combined RSS feed on disk
+const combinedFeedPath = "./output/killarney-development.xml"
+
+// feedNeighborhood - the neighborhood named in feed titles and descriptions
+const feedNeighborhood = "Killarney"
+
+// feedLink - the channel link of every feed
+const feedLink = "https://calgary.ca/development"
+
 func ManualInit() error {
-	// No initialization needed for RSS feeds
-	return nil
+	if err := i18n.SetDefault(config.Config.Language); err != nil {
+		return err
+	}
+	if err := citytime.SetTimezone(config.Config.Timezone); err != nil {
+		return err
+	}
+	if err := configureCommunity(config.Config.Neighborhood); err != nil {
+		return err
+	}
+	calgaryopendata.SetBaseURL(config.Config.OpenData.BaseURL)
+	simplehttp.Configure(simplehttp.Policy{
+		Timeout:           config.Config.HTTP.Timeout,
+		Retries:           toolbox.IntValue(config.Config.HTTP.Retries),
+		InitialBackoff:    config.Config.HTTP.InitialBackoff,
+		MaxBackoff:        config.Config.HTTP.MaxBackoff,
+		RequestsPerSecond: config.Config.HTTP.RequestsPerSecond,
+	})
+
+	// Load template overrides so a broken template fails at startup rather than mid run
+	return templates.Load(config.Config.Templates.Directory)
+}
+
+// configureCommunity - Sets the home community and reference point items are positioned against
+func configureCommunity(neighborhood config.Neighborhood) error {
+	var reference *geo.Point
+	if neighborhood.ReferencePoint != "" {
+		point, err := geo.ParsePoint(neighborhood.ReferencePoint)
+		if err != nil {
+			return fmt.Errorf("neighborhood reference-point: %v", err)
+		}
+		reference = &point
+	}
+	// Permits are selected by their communitycode column, but rezonings are found by the boundaries of listed communities,
+	// so ones without a boundary only come from the bounding box
+	for _, code := range neighborhood.CommunityCodes {
+		if _, found := community.Lookup(code); !found {
+			fmt.Printf("Community %s has no built-in boundary, its rezoning applications are only found inside the bounding box\n", code)
+		}
+	}
+	return community.Configure(neighborhood.Community, reference, neighborhood.ReferenceName)
+}
+
+// PartialFailureError - some datasets failed while the rest were processed and published, the failed ones are fetched again next run
+type PartialFailureError struct {
+	Errors []error
+}
+
+// Error - Lists the failures
+func (e *PartialFailureError) Error() string {
+	return errors.Join(e.Errors...).Error()
 }
 
 // ProcessAllDevelopmentActivity - Evaluates both development permits and rezoning applications and generates a combined RSS feed
-func ProcessAllDevelopmentActivity() error {
+// When one dataset fails the feed is still written for the other and a *PartialFailureError is returned with the feed
+func ProcessAllDevelopmentActivity(ctx context.Context) (*rssfeed.RSS, error) {
+	rss, changed, err := processDevelopmentActivity(ctx)
+	// A one off run publishes the feed by saving it, so WebSub subscribers are told once the run is done
+	if changed {
+		announceFeedUpdate(rss)
+	}
+	return rss, err
+}
+
+// processDevelopmentActivity - runs ProcessAllDevelopmentActivity without announcing the feed, reporting whether it changed
+// so the caller can announce it once it is published
+func processDevelopmentActivity(ctx context.Context) (*rssfeed.RSS, bool, error) {
+	// Check alert rules before fetching anything so a config mistake fails fast
+	notifiers := notifications.FromConfig()
+	rules, err := alertrules.Compile(config.Config.AlertRules, notifications.Names(notifiers))
+	if err != nil {
+		return nil, false, err
+	}
+
 	// Load or create combined RSS feed
-	rss, err := rssfeed.GetOrCreateRSSFeed(
-		"./output/killarney-development.xml",
-		"Killarney Development Activity",
-		"All development permits and land use rezoning applications for the Killarney neighborhood in Calgary",
-		"https://calgary.ca/development",
-	)
+	rss, err := getOrCreateLanguageFeed(combinedFeedPath, config.Config.Language)
 	if err != nil {
-		return fmt.Errorf("failed to load RSS feed: %v", err)
+		return nil, false, fmt.Errorf("failed to load RSS feed: %v", err)
 	}
 
-	// Process development permits
-	dpActions, dpErr := developmentpermit.EvaluateDevelopmentPermits(rss)
+	// Only rows changed since the last successful run are fetched, with the whole window fetched now and then to catch deletions
+	fetchState, err := fetchstate.Load(fetchstate.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	// Changing the neighborhood's selection changes the query, which fetches the whole window for the newly selected rows
+	fetchedAt := citytime.Now()
+	query := selection.FromConfig(config.Config.Neighborhood).Where(true)
+	dpSince := fetchState.Since(activity.DevelopmentPermit, query, config.Config.Fetch.Incremental, config.Config.Fetch.FullEvery, fetchedAt)
+	raSince := fetchState.Since(activity.RezoningApplication, query, config.Config.Fetch.Incremental, config.Config.Fetch.FullEvery, fetchedAt)
+
+	// Each dataset is fetched on its own, so one failing does not hold back the other
+	var developmentPermits, storedDevelopmentPermits []developmentpermit.DevelopmentPermit
+	var rezoningApplications, storedRezoningApplications []rezoningapplications.RezoningApplication
+	var dpLatest, raLatest string
+	fetches := workpool.New(config.Config.HTTP.Concurrency)
+	fetches.Go(func() (err error) {
+		developmentPermits, storedDevelopmentPermits, dpLatest, err = developmentpermit.LoadDevelopmentPermits(ctx, dpSince)
+		return err
+	})
+	fetches.Go(func() (err error) {
+		rezoningApplications, storedRezoningApplications, raLatest, err = rezoningapplications.LoadRezoningApplications(ctx, raSince)
+		return err
+	})
+	fetchErrors := fetches.Wait()
+	dpErr, raErr := fetchErrors[0], fetchErrors[1]
+
+	// The feed is changed in the same order every run, whichever fetch finished first
+	var failures []error
+	var dpActions, raActions []fileaction.FileAction
 	if dpErr != nil {
-		return fmt.Errorf("failed to process development permits: %v", dpErr)
+		failures = append(failures, fmt.Errorf("failed to process development permits: %v", dpErr))
+	} else {
+		dpActions = developmentpermit.EvaluateDevelopmentPermits(rss, developmentPermits, storedDevelopmentPermits)
+	}
+	if raErr != nil {
+		failures = append(failures, fmt.Errorf("failed to process rezoning applications: %v", raErr))
+	} else {
+		raActions = rezoningapplications.EvaluateRezoningApplications(rss, rezoningApplications, storedRezoningApplications)
+	}
+	if dpErr != nil && raErr != nil {
+		return nil, false, errors.Join(failures...)
 	}
 
-	// Process rezoning applications
-	raActions, raErr := rezoningapplications.EvaluateRezoningApplications(rss)
+	// A failed dataset is linked against as it was stored, it is not saved so its changes are found again next run
+	if dpErr != nil {
+		if developmentPermits, err = developmentpermit.LoadStoredDevelopmentPermits(); err != nil {
+			failures = append(failures, fmt.Errorf("failed to load stored development permits to link against: %v", err))
+		}
+	}
 	if raErr != nil {
-		return fmt.Errorf("failed to process rezoning applications: %v", raErr)
+		if rezoningApplications, err = rezoningapplications.LoadStoredRezoningApplications(); err != nil {
+			failures = append(failures, fmt.Errorf("failed to load stored rezoning applications to link against: %v", err))
+		}
 	}
+	linkRelatedApplications(rss, developmentPermits, rezoningApplications)
 
 	// Trim RSS feed to keep only recent items (increased since we have both types)
 	rss.TrimToMaxItems(200)
 
+	// Advertise the WebSub hub so readers can subscribe for push updates
+	rss.SetHubLinks(config.Config.WebSub.HubURL, config.Config.WebSub.SelfURL)
+
 	// Save combined RSS feed
-	if err := rssfeed.SaveRSSFeed(rss, "./output/killarney-development.xml"); err != nil {
-		return fmt.Errorf("failed to save RSS feed: %v", err)
+	changed, err := rssfeed.SaveRSSFeed(rss, combinedFeedPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save RSS feed: %v", err)
 	}
 
+	// The fetched data is only saved once the feed holds its changes, so a run that stops before here is repeated in full
+	// and the high-water mark only moves once the data it covers is saved
+	if dpErr == nil {
+		if err := developmentpermit.SaveDevelopmentPermits(developmentPermits); err != nil {
+			failures = append(failures, fmt.Errorf("failed to save development permits: %v", err))
+		} else {
+			fetchState.Record(activity.DevelopmentPermit, query, dpLatest, dpSince == "", fetchedAt)
+		}
+	}
+	if raErr == nil {
+		if err := rezoningapplications.SaveRezoningApplications(rezoningApplications); err != nil {
+			failures = append(failures, fmt.Errorf("failed to save rezoning applications: %v", err))
+		} else {
+			fetchState.Record(activity.RezoningApplication, query, raLatest, raSince == "", fetchedAt)
+		}
+	}
+	if err := fetchState.Save(fetchstate.Path); err != nil {
+		fmt.Printf("Failed to save fetch state, the next run fetches more than it needs to: %v\n", err)
+	}
+	if err := updateParcelIndex(); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := writeLanguageFeeds(rss, config.Config.FeedLanguages); err != nil {
+		fmt.Println(err.Error())
+	}
+	recordRunMetrics(dpActions, raActions, rss)
+
+	// Tell subscribers about the run's new and changed activity, as routed by the alert rules
+	actions := append(dpActions, raActions...)
+	routes := alertrules.Route(rules, actions)
+	if err := writeRuleFeeds(rss, routes.Feeds); err != nil {
+		fmt.Println(err.Error())
+	}
+	notifications.NotifyAll(ctx, notifiers, actions, routes.Notifiers)
+
 	fmt.Printf("Combined RSS feed processed with %d development permit actions and %d rezoning application actions\n",
 		len(dpActions), len(raActions))
 
-	return nil
+	if len(failures) > 0 {
+		return rss, changed, &PartialFailureError{Errors: failures}
+	}
+	return rss, changed, nil
 }
@@ -0,0 +1,197 @@
+package examinedata
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/calgaryopendata"
+	"github.com/jeffadavidson/development-bot/interactions/fakesocrata"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/parcel"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyRun - runs a test in an empty data directory with datasets fetched from the base URL, restoring everything afterwards
+func emptyRun(t *testing.T, baseURL string, incremental bool) {
+	workingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+	previousConfig := config.Config
+	t.Cleanup(func() {
+		config.Config = previousConfig
+		simplehttp.SetTransport(nil)
+		citytime.Unfreeze()
+		require.NoError(t, os.Chdir(workingDirectory))
+	})
+
+	require.NoError(t, os.Chdir(t.TempDir()))
+	require.NoError(t, os.MkdirAll("data", 0755))
+	require.NoError(t, os.MkdirAll("output", 0755))
+	for _, path := range storedFiles[:2] {
+		require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	}
+
+	config.Config = config.DevBot{
+		Neighborhood: config.Neighborhood{
+			Name:        "Killarney",
+			BoundingBox: config.BoundingBox{NorthLatitude: 51.038912, EastLongitude: -114.117927, SouthLatitude: 51.022361, WestLongitude: -114.142638},
+			Selection:   "any",
+		},
+		HTTP:     config.HTTP{Timeout: time.Second, Concurrency: 2},
+		Fetch:    config.Fetch{Incremental: incremental, FullEvery: 24 * time.Hour},
+		OpenData: config.OpenData{BaseURL: baseURL},
+		Language: "en",
+		Timezone: citytime.DefaultTimezone,
+	}
+	require.NoError(t, ManualInit())
+}
+
+// storedStatuses - the status history of each stored rezoning application
+func storedStatuses(t *testing.T) map[string][]string {
+	applications, err := rezoningapplications.LoadStoredRezoningApplications()
+	require.NoError(t, err)
+	statuses := make(map[string][]string)
+	for _, application := range applications {
+		for _, change := range application.StateHistory {
+			statuses[application.PermitNum] = append(statuses[application.PermitNum], change.Status)
+		}
+	}
+	return statuses
+}
+
+func Test_ProcessAllDevelopmentActivity_ReplaysRecordedDay(t *testing.T) {
+	fixtures, err := filepath.Abs("testdata/fixtures")
+	require.NoError(t, err)
+	emptyRun(t, calgaryopendata.DefaultBaseURL, false)
+	require.NoError(t, UseFixtures("", fixtures))
+
+	rss, err := ProcessAllDevelopmentActivity(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, rss.Channel.Items, 6)
+	for _, path := range append(storedFiles, parcel.IndexPath) {
+		assert.FileExists(t, path)
+	}
+	feed, err := os.ReadFile(combinedFeedPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(feed), "DP2026-01776")
+	assert.Contains(t, string(feed), "LOC2026-0023")
+
+	// Replaying the same day again finds nothing new
+	rss, err = ProcessAllDevelopmentActivity(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, rss.Channel.Items, 6)
+	unchanged, err := os.ReadFile(combinedFeedPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(feed), string(unchanged))
+}
+
+func Test_ProcessAllDevelopmentActivity_OneDatasetFails(t *testing.T) {
+	scenario, err := fakesocrata.LoadScenario("../../interactions/fakesocrata/testdata/lifecycle.json")
+	require.NoError(t, err)
+	// Without the development permits dataset the fake answers its queries with 404 Not Found
+	delete(scenario.Datasets, calgaryopendata.DevelopmentPermitsDataset)
+	fake := fakesocrata.New(scenario)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	emptyRun(t, server.URL, true)
+	citytime.Freeze(fake.Now())
+
+	stored := []byte(`[{"permitnum": "DP2026-01700", "statuscurrent": "In Circulation", "applieddate": "2026-03-20T00:00:00.000"}]`)
+	require.NoError(t, os.WriteFile(storedFiles[0], stored, 0644))
+
+	rss, err := ProcessAllDevelopmentActivity(context.Background())
+
+	var partialFailure *PartialFailureError
+	require.ErrorAs(t, err, &partialFailure)
+	require.Len(t, partialFailure.Errors, 1)
+	assert.ErrorContains(t, partialFailure.Errors[0], "failed to process development permits")
+
+	// The rezoning application is in the written feed and saved
+	require.NotNil(t, rss)
+	feed, err := os.ReadFile(combinedFeedPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(feed), "LOC2026-0031")
+	assert.Equal(t, map[string][]string{"LOC2026-0031": {"submitted"}}, storedStatuses(t))
+
+	// The failed dataset's stored data is left as it was
+	unchanged, err := os.ReadFile(storedFiles[0])
+	require.NoError(t, err)
+	assert.Equal(t, string(stored), string(unchanged))
+}
+
+func Test_UseFixtures_RecordsWithTheClockRunningAndReplays(t *testing.T) {
+	scenario, err := fakesocrata.LoadScenario("../../interactions/fakesocrata/testdata/lifecycle.json")
+	require.NoError(t, err)
+	// Moved to today, as the clock is not frozen while recording
+	server := httptest.NewServer(fakesocrata.New(scenario.MoveTo(time.Now())))
+	defer server.Close()
+	emptyRun(t, server.URL, false)
+
+	fixtures := t.TempDir()
+	require.NoError(t, UseFixtures(fixtures, ""))
+	started := citytime.Now()
+	time.Sleep(5 * time.Millisecond)
+	assert.True(t, citytime.Now().After(started))
+
+	recorded, err := ProcessAllDevelopmentActivity(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recorded.Channel.Items, 2)
+
+	// Replayed from empty data without the fake, the recorded queries are sent again
+	server.Close()
+	for _, path := range storedFiles[:2] {
+		require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	}
+	require.NoError(t, os.Remove(combinedFeedPath))
+	require.NoError(t, UseFixtures("", fixtures))
+	replayed, err := ProcessAllDevelopmentActivity(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, replayed.Channel.Items, 2)
+}
+
+func Test_ProcessAllDevelopmentActivity_FollowsLifecycleOnFakeSocrata(t *testing.T) {
+	scenario, err := fakesocrata.LoadScenario("../../interactions/fakesocrata/testdata/lifecycle.json")
+	require.NoError(t, err)
+	fake := fakesocrata.New(scenario)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	emptyRun(t, server.URL, true)
+
+	expected := [][]string{{"submitted"}, {"submitted", "under review"}, {"submitted", "under review", "approved"}}
+	for day := 0; day < len(expected); day++ {
+		if day > 0 {
+			require.True(t, fake.Advance())
+		}
+		// Each run happens on the scenario's day, so the 3 month window and :updated_at marks line up with the fake's rows
+		citytime.Freeze(fake.Now())
+		rss, err := ProcessAllDevelopmentActivity(context.Background())
+		require.NoError(t, err, "day %d", day)
+
+		assert.Equal(t, map[string][]string{"LOC2026-0031": expected[day]}, storedStatuses(t), "day %d", day)
+		// The downtown permit is outside the bounding box
+		assert.Len(t, rss.Channel.Items, 2, "day %d", day)
+	}
+
+	permits, err := developmentpermit.LoadStoredDevelopmentPermits()
+	require.NoError(t, err)
+	require.Len(t, permits, 1)
+	assert.Equal(t, "Released", permits[0].StatusCurrent)
+	assert.Equal(t, "Approval", *permits[0].Decision)
+
+	// Nothing changes once the scenario is over
+	feed, err := os.ReadFile(combinedFeedPath)
+	require.NoError(t, err)
+	_, err = ProcessAllDevelopmentActivity(context.Background())
+	require.NoError(t, err)
+	unchanged, err := os.ReadFile(combinedFeedPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(feed), string(unchanged))
+}
@@ -0,0 +1,136 @@
+package examinedata
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/logic/feedfilter"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/parcel"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+)
+
+// maxFilteredFeedItems - filtered feeds are capped like the combined feed
+const maxFilteredFeedItems = 200
+
+// activitySnapshot - the stored records behind the last published feed, kept in memory to build filtered feeds
+type activitySnapshot struct {
+	lock                 sync.RWMutex
+	feed                 *rssfeed.RSS
+	developmentPermits   []developmentpermit.DevelopmentPermit
+	rezoningApplications []rezoningapplications.RezoningApplication
+	parcels              parcel.Index
+	// entries - every stored record rendered once per refresh, so filtered feeds only filter and copy items
+	entries []feedEntry
+}
+
+// feedEntry - a stored record's filtering view and its rendered feed item
+type feedEntry struct {
+	record activity.Record
+	item   rssfeed.Item
+}
+
+// refresh - reloads the stored records to match a newly published feed
+func (s *activitySnapshot) refresh(rss *rssfeed.RSS) error {
+	developmentPermits, dpErr := developmentpermit.LoadStoredDevelopmentPermits()
+	if dpErr != nil {
+		return fmt.Errorf("failed to load stored development permits: %v", dpErr)
+	}
+	rezoningApplications, raErr := rezoningapplications.LoadStoredRezoningApplications()
+	if raErr != nil {
+		return fmt.Errorf("failed to load stored rezoning applications: %v", raErr)
+	}
+	parcels, parcelErr := parcel.Load(parcel.IndexPath)
+	if parcelErr != nil {
+		return fmt.Errorf("failed to load parcel index: %v", parcelErr)
+	}
+
+	s.set(rss, developmentPermits, rezoningApplications, parcels)
+
+	return nil
+}
+
+// set - replaces the snapshot, rendering each record's feed item in the published feed's language
+func (s *activitySnapshot) set(rss *rssfeed.RSS, developmentPermits []developmentpermit.DevelopmentPermit, rezoningApplications []rezoningapplications.RezoningApplication, parcels parcel.Index) {
+	var entries []feedEntry
+	if rss != nil {
+		rendered := rssfeed.CreateRSSFeed(rss.Channel.Title, rss.Channel.Description, rss.Channel.Link)
+		rendered.Channel.Language = rss.Channel.Language
+		// Render each record on its own so the item is the only one in the scratch feed
+		addEntry := func(record activity.Record, render func(*rssfeed.RSS) bool) {
+			rendered.Channel.Items = nil
+			render(rendered)
+			if len(rendered.Channel.Items) == 1 {
+				entries = append(entries, feedEntry{record: record, item: rendered.Channel.Items[0]})
+			}
+		}
+		for i := range developmentPermits {
+			addEntry(developmentPermits[i].ActivityRecord(), developmentPermits[i].AddToRSSFeed)
+		}
+		for i := range rezoningApplications {
+			addEntry(rezoningApplications[i].ActivityRecord(), rezoningApplications[i].AddToRSSFeed)
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.feed = rss
+	s.developmentPermits = developmentPermits
+	s.rezoningApplications = rezoningApplications
+	s.parcels = parcels
+	s.entries = entries
+}
+
+// buildFilteredFeed - builds a feed of the stored records that match a filter, returns nil before the first refresh
+func (s *activitySnapshot) buildFilteredFeed(filter feedfilter.Filter) *rssfeed.RSS {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.feed == nil {
+		return nil
+	}
+
+	rss := rssfeed.CreateRSSFeed(s.feed.Channel.Title, s.feed.Channel.Description, s.feed.Channel.Link)
+	rss.Channel.Language = s.feed.Channel.Language
+	for _, entry := range s.entries {
+		if filter.Matches(entry.record) {
+			rss.Channel.Items = append(rss.Channel.Items, entry.item)
+		}
+	}
+
+	rss.SortByPubDate()
+	rss.TrimToMaxItems(maxFilteredFeedItems)
+
+	// Share the combined feed's build date so the filtered feed only looks modified when the data is
+	rss.Channel.LastBuildDate = s.feed.Channel.LastBuildDate
+
+	return rss
+}
+
+// serveFeed - serves the published feed, or a filtered feed built on the fly when the request has query parameters
+func serveFeed(server *webserver.Server, snapshot *activitySnapshot) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery == "" {
+			server.ServePublished(w, r)
+			return
+		}
+
+		filter, err := feedfilter.ParseQuery(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rss := snapshot.buildFilteredFeed(filter)
+		if rss == nil {
+			http.Error(w, "feed is not ready yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		webserver.ServeRSS(w, r, rss)
+	}
+}
@@ -0,0 +1,143 @@
+package examinedata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/logic/feedfilter"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func testSnapshot() *activitySnapshot {
+	feed := rssfeed.CreateRSSFeed("Test Feed", "Test Description", "https://example.com")
+	feed.Channel.LastBuildDate = "Sun, 29 Mar 2026 12:57:38 +0000"
+
+	snapshot := &activitySnapshot{}
+	snapshot.set(feed,
+		[]developmentpermit.DevelopmentPermit{
+			{
+				PermitNum:     "DP2026-01776",
+				StatusCurrent: "Hold",
+				Ward:          stringPointer("6"),
+				Point:         developmentpermit.Point{Type: "Point", Coordinates: []float64{-114.14133925156962, 51.02865023779817}},
+				AppliedDate:   stringPointer("2026-03-27T00:00:00.000"),
+				RSSGuid:       "dp-guid",
+			},
+		},
+		[]rezoningapplications.RezoningApplication{
+			{
+				PermitNum:     "LOC2026-0023",
+				StatusCurrent: "Under Review",
+				Latitude:      stringPointer("51.02656816016038"),
+				Longitude:     stringPointer("-114.12936552510145"),
+				AppliedDate:   stringPointer("2026-02-21T18:39:10.000"),
+				RSSGuid:       "ra-guid",
+			},
+		},
+		nil,
+	)
+
+	return snapshot
+}
+
+func Test_BuildFilteredFeed_NotReady(t *testing.T) {
+	snapshot := &activitySnapshot{}
+	assert.Nil(t, snapshot.buildFilteredFeed(feedfilter.Filter{}))
+}
+
+func Test_BuildFilteredFeed_EmptyFilterIncludesEverything(t *testing.T) {
+	snapshot := testSnapshot()
+	// Items are rendered once when the snapshot is set, not on every request
+	require.Len(t, snapshot.entries, 2)
+
+	rss := snapshot.buildFilteredFeed(feedfilter.Filter{})
+
+	require.NotNil(t, rss)
+	require.Len(t, rss.Channel.Items, 2)
+	// Newest activity first
+	assert.Equal(t, "dp-guid", rss.Channel.Items[0].GUID.Value)
+	assert.Equal(t, "ra-guid", rss.Channel.Items[1].GUID.Value)
+	assert.Equal(t, "Test Feed", rss.Channel.Title)
+	assert.Equal(t, "Sun, 29 Mar 2026 12:57:38 +0000", rss.Channel.LastBuildDate)
+}
+
+func Test_ServeFeed_FiltersByQuery(t *testing.T) {
+	snapshot := testSnapshot()
+	server := webserver.NewServer()
+	server.HandleFunc("GET /feed.xml", serveFeed(server, snapshot))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/feed.xml?type=rezoning&status=under+review", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "LOC2026-0023")
+	assert.NotContains(t, recorder.Body.String(), "DP2026-01776")
+}
+
+func Test_ServeFeed_FiltersByDistance(t *testing.T) {
+	snapshot := testSnapshot()
+	server := webserver.NewServer()
+	server.HandleFunc("GET /feed.xml", serveFeed(server, snapshot))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/feed.xml?near=51.0287,-114.1413&radius=300m", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "DP2026-01776")
+	assert.NotContains(t, recorder.Body.String(), "LOC2026-0023")
+}
+
+func Test_ServeFeed_InvalidQuery(t *testing.T) {
+	snapshot := testSnapshot()
+	server := webserver.NewServer()
+	server.HandleFunc("GET /feed.xml", serveFeed(server, snapshot))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/feed.xml?since=yesterday", nil))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_ServeFeed_WithoutQueryServesPublishedFeed(t *testing.T) {
+	snapshot := testSnapshot()
+	server := webserver.NewServer()
+	server.HandleFunc("GET /feed.xml", serveFeed(server, snapshot))
+	require.NoError(t, server.PublishRSS("/feed.xml", snapshot.feed))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/feed.xml", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "<title>Test Feed</title>")
+	assert.NotContains(t, recorder.Body.String(), "<item>")
+}
+
+func Test_BuildFilteredFeed_SinceSkipsUndatedRecords(t *testing.T) {
+	snapshot := &activitySnapshot{}
+	snapshot.set(rssfeed.CreateRSSFeed("Test Feed", "Test Description", "https://example.com"),
+		[]developmentpermit.DevelopmentPermit{
+			{PermitNum: "DP2026-00001", AppliedDate: stringPointer("2026-03-27T00:00:00.000"), RSSGuid: "dated-guid"},
+			{PermitNum: "DP2026-00002", RSSGuid: "undated-guid"},
+		},
+		nil,
+		nil,
+	)
+
+	filter, err := feedfilter.ParseQuery(map[string][]string{"since": {"2026-03-01"}})
+	require.NoError(t, err)
+
+	rss := snapshot.buildFilteredFeed(filter)
+	require.NotNil(t, rss)
+	require.Len(t, rss.Channel.Items, 1)
+	assert.Equal(t, "dated-guid", rss.Channel.Items[0].GUID.Value)
+}
@@ -0,0 +1,37 @@
+package examinedata
+
+import (
+	"fmt"
+
+	"github.com/jeffadavidson/development-bot/interactions/calgaryopendata"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/simplehttp"
+)
+
+// UseFixtures - Records the open data responses to one directory or replays upstream responses from another, both empty uses the network
+// Only open data is recorded, so sign ins and posts to notification services are never saved. A replay freezes the clock at the
+// recording's start time so its queries match the recorded ones, a recording keeps the clock running so a server recording still refreshes
+func UseFixtures(recordDirectory string, replayDirectory string) error {
+	if recordDirectory != "" && replayDirectory != "" {
+		return fmt.Errorf("cannot record and replay fixtures in the same run")
+	}
+
+	if recordDirectory != "" {
+		recordedAt := citytime.Now()
+		if err := simplehttp.Record(recordDirectory, recordedAt, calgaryopendata.Host()); err != nil {
+			return err
+		}
+		fmt.Printf("Recording open data responses from %s to %s\n", calgaryopendata.Host(), recordDirectory)
+	}
+
+	if replayDirectory != "" {
+		recordedAt, err := simplehttp.Replay(replayDirectory)
+		if err != nil {
+			return err
+		}
+		citytime.Freeze(recordedAt)
+		fmt.Printf("Replaying upstream responses recorded %s from %s\n", recordedAt.In(citytime.Location()).Format("2006-01-02 15:04 MST"), replayDirectory)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package examinedata
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/metrics"
+)
+
+// storedFiles - files the bot keeps between runs, reported in the storage size metric
+var storedFiles = []string{
+	"./data/development-permits.json",
+	"./data/rezoning-applications.json",
+	combinedFeedPath,
+}
+
+var (
+	actionsTaken   = metrics.Default.NewCounter("devbot_actions_total", "Feed actions taken by dataset and action type.", "dataset", "action")
+	runsCompleted  = metrics.Default.NewCounter("devbot_runs_total", "Processing runs by result.", "result")
+	lastSuccessful = metrics.Default.NewGauge("devbot_last_success_timestamp_seconds", "Unix time of the last successful processing run.")
+	feedItems      = metrics.Default.NewGauge("devbot_feed_items", "Number of items in the combined feed.")
+	storageBytes   = metrics.Default.NewGauge("devbot_storage_bytes", "Size of the stored data and feed files.", "file")
+)
+
+// runHealth - the outcome of the processing runs, used to answer health and readiness checks
+type runHealth struct {
+	lock        sync.RWMutex
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastErr     error
+}
+
+// healthResponse - the body of the health and readiness endpoints
+type healthResponse struct {
+	Status      string     `json:"status"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// recordRunMetrics - updates the metrics that describe a completed processing run
+func recordRunMetrics(dpActions []fileaction.FileAction, raActions []fileaction.FileAction, rss *rssfeed.RSS) {
+	for _, action := range dpActions {
+		actionsTaken.Inc(activity.DevelopmentPermit, action.Action)
+	}
+	for _, action := range raActions {
+		actionsTaken.Inc(activity.RezoningApplication, action.Action)
+	}
+	feedItems.Set(float64(len(rss.Channel.Items)))
+
+	for _, path := range storedFiles {
+		if info, err := os.Stat(path); err == nil {
+			storageBytes.Set(float64(info.Size()), filepath.Base(path))
+		}
+	}
+}
+
+// record - notes the result of a processing run
+func (h *runHealth) record(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.lastRun = time.Now()
+	h.lastErr = err
+	if err != nil {
+		runsCompleted.Inc("failure")
+		return
+	}
+
+	h.lastSuccess = h.lastRun
+	runsCompleted.Inc("success")
+	lastSuccessful.Set(float64(h.lastSuccess.Unix()))
+}
+
+// response - describes the current health, reporting whether the check passes
+func (h *runHealth) response() (healthResponse, bool, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	response := healthResponse{Status: "ok"}
+	if !h.lastRun.IsZero() {
+		lastRun := h.lastRun
+		response.LastRun = &lastRun
+	}
+	if !h.lastSuccess.IsZero() {
+		lastSuccess := h.lastSuccess
+		response.LastSuccess = &lastSuccess
+	}
+	if h.lastErr != nil {
+		response.Error = h.lastErr.Error()
+	}
+
+	healthy := h.lastErr == nil
+	ready := healthy && !h.lastSuccess.IsZero()
+	return response, healthy, ready
+}
+
+// serveHealthz - reports unhealthy when the last processing run failed
+func (h *runHealth) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	response, healthy, _ := h.response()
+	if !healthy {
+		response.Status = "failing"
+		webserver.WriteJSON(w, http.StatusServiceUnavailable, response)
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, response)
+}
+
+// serveReadyz - reports not ready until a processing run has succeeded, and while the last run is failing
+func (h *runHealth) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	response, _, ready := h.response()
+	if !ready {
+		response.Status = "not ready"
+		webserver.WriteJSON(w, http.StatusServiceUnavailable, response)
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, response)
+}
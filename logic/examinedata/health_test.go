@@ -0,0 +1,83 @@
+package examinedata
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkHealth(t *testing.T, handler http.HandlerFunc) (int, healthResponse) {
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/", nil))
+
+	var response healthResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return recorder.Code, response
+}
+
+func Test_RunHealth_BeforeFirstRun(t *testing.T) {
+	health := &runHealth{}
+
+	status, _ := checkHealth(t, health.serveHealthz)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, response := checkHealth(t, health.serveReadyz)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "not ready", response.Status)
+}
+
+func Test_RunHealth_AfterSuccessfulRun(t *testing.T) {
+	health := &runHealth{}
+	health.record(nil)
+
+	status, response := checkHealth(t, health.serveHealthz)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotNil(t, response.LastSuccess)
+
+	status, _ = checkHealth(t, health.serveReadyz)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func Test_RunHealth_AfterFailedRun(t *testing.T) {
+	health := &runHealth{}
+	health.record(nil)
+	health.record(errors.New("failed to process development permits"))
+
+	status, response := checkHealth(t, health.serveHealthz)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "failing", response.Status)
+	assert.Equal(t, "failed to process development permits", response.Error)
+	assert.NotNil(t, response.LastSuccess)
+
+	status, _ = checkHealth(t, health.serveReadyz)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	health.record(nil)
+	status, _ = checkHealth(t, health.serveHealthz)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func Test_RecordRunMetrics(t *testing.T) {
+	createsBefore := actionsTaken.Value(activity.DevelopmentPermit, "CREATE")
+	closesBefore := actionsTaken.Value(activity.RezoningApplication, "CLOSE")
+
+	rss := rssfeed.CreateRSSFeed("Test", "Test", "https://example.com")
+	rss.Channel.Items = make([]rssfeed.Item, 3)
+	recordRunMetrics(
+		[]fileaction.FileAction{{Action: "CREATE"}, {Action: "CREATE"}},
+		[]fileaction.FileAction{{Action: "CLOSE"}},
+		rss,
+	)
+
+	assert.Equal(t, createsBefore+2, actionsTaken.Value(activity.DevelopmentPermit, "CREATE"))
+	assert.Equal(t, closesBefore+1, actionsTaken.Value(activity.RezoningApplication, "CLOSE"))
+	assert.Equal(t, 3.0, feedItems.Value())
+}
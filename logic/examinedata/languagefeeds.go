@@ -0,0 +1,112 @@
+package examinedata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+)
+
+// languageFeedPath - the combined feed's path with the language before the extension, such as ./output/killarney-development.fr.xml
+func languageFeedPath(language string) string {
+	return strings.TrimSuffix(combinedFeedPath, ".xml") + "." + language + ".xml"
+}
+
+// getOrCreateLanguageFeed - loads or creates a feed, setting its channel title, description and language for the language
+func getOrCreateLanguageFeed(path string, language string) (*rssfeed.RSS, error) {
+	title := i18n.Tf(language, "feed-title", feedNeighborhood)
+	description := i18n.Tf(language, "feed-description", feedNeighborhood)
+	rss, err := rssfeed.GetOrCreateRSSFeed(path, title, description, feedLink)
+	if err != nil {
+		return nil, err
+	}
+
+	// A feed saved before the language setting changed is switched to the new language
+	rss.Channel.Title = title
+	rss.Channel.Description = description
+	rss.Channel.Language = i18n.FeedLanguage(language)
+
+	return rss, nil
+}
+
+// writeLanguageFeeds - writes a copy of the combined feed in each language, rendering the items from the stored records
+func writeLanguageFeeds(combined *rssfeed.RSS, languages []string) error {
+	if len(languages) == 0 {
+		return nil
+	}
+
+	renderers, err := storedRecordRenderers()
+	if err != nil {
+		return fmt.Errorf("failed to write language feeds: %v", err)
+	}
+
+	var failures []string
+	for _, language := range languages {
+		path := languageFeedPath(language)
+		rss, err := getOrCreateLanguageFeed(path, language)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", language, err.Error()))
+			continue
+		}
+
+		mirrorFeed(rss, combined, renderers)
+		if _, err := rssfeed.SaveRSSFeed(rss, path); err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", language, err.Error()))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to write language feeds: %s", strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// mirrorFeed - gives a feed the combined feed's items in the same order, rendered in the feed's language where the record is known
+func mirrorFeed(rss *rssfeed.RSS, combined *rssfeed.RSS, renderers map[string]func(*rssfeed.RSS) bool) {
+	for _, item := range combined.Channel.Items {
+		if render, found := renderers[item.GUID.Value]; found {
+			render(rss)
+		} else {
+			rss.UpsertItem(item)
+		}
+	}
+
+	// Items trimmed from the combined feed are dropped
+	items := make([]rssfeed.Item, 0, len(combined.Channel.Items))
+	for _, item := range combined.Channel.Items {
+		if mirrored := rss.FindItemByGUID(item.GUID.Value); mirrored != nil {
+			items = append(items, *mirrored)
+		}
+	}
+	rss.Channel.Items = items
+	rss.Channel.AtomLinks = nil
+
+	// Share the combined feed's build date so the copy only looks modified when the data is
+	rss.Channel.LastBuildDate = combined.Channel.LastBuildDate
+}
+
+// storedRecordRenderers - adds each stored record to a feed, by the record's feed GUID
+func storedRecordRenderers() (map[string]func(*rssfeed.RSS) bool, error) {
+	developmentPermits, dpErr := developmentpermit.LoadStoredDevelopmentPermits()
+	if dpErr != nil {
+		return nil, fmt.Errorf("failed to load stored development permits: %v", dpErr)
+	}
+	rezoningApplications, raErr := rezoningapplications.LoadStoredRezoningApplications()
+	if raErr != nil {
+		return nil, fmt.Errorf("failed to load stored rezoning applications: %v", raErr)
+	}
+
+	renderers := make(map[string]func(*rssfeed.RSS) bool)
+	for i := range developmentPermits {
+		renderers[developmentPermits[i].RSSGuid] = developmentPermits[i].AddToRSSFeed
+	}
+	for i := range rezoningApplications {
+		renderers[rezoningApplications[i].RSSGuid] = rezoningApplications[i].AddToRSSFeed
+	}
+
+	return renderers, nil
+}
@@ -0,0 +1,51 @@
+package examinedata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MirrorFeed_RendersItemsInTheFeedLanguage(t *testing.T) {
+	address := "2807 27 ST SW"
+	mustCommenceDate := "2027-08-01T00:00:00.000"
+	permit := developmentpermit.DevelopmentPermit{
+		PermitNum:        "DP2026-01776",
+		StatusCurrent:    "In Circulation",
+		Address:          &address,
+		MustCommenceDate: &mustCommenceDate,
+		RSSGuid:          "guid-permit",
+	}
+
+	combined := rssfeed.CreateRSSFeed("Killarney Development Activity", "All activity", feedLink)
+	pubDate := time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC)
+	combined.AddItem("Rezoning LOC2026-0023", "Desc", "https://example.com/LOC2026-0023", "guid-unknown", pubDate, "Land Use Rezoning", "", "", "", "Content")
+	permit.AddToRSSFeed(combined)
+
+	rss := rssfeed.CreateRSSFeed(i18n.Tf("fr", "feed-title", feedNeighborhood), "", feedLink)
+	rss.Channel.Language = i18n.FeedLanguage("fr")
+	rss.AddItem("Trimmed", "Desc", "https://example.com/old", "guid-trimmed", pubDate, "", "", "", "", "")
+
+	mirrorFeed(rss, combined, map[string]func(*rssfeed.RSS) bool{"guid-permit": permit.AddToRSSFeed})
+
+	require.Len(t, rss.Channel.Items, 2)
+	assert.Equal(t, "Activité de développement à Killarney", rss.Channel.Title)
+	assert.Equal(t, "🏗️ Permis d'aménagement: DP2026-01776 - 2807 27 ST SW", rss.Channel.Items[0].Title)
+	assert.Contains(t, rss.Channel.Items[0].Description.Text, "<li>Début des travaux avant le: 1er août 2027</li>")
+	// Items without a stored record are copied as they are
+	assert.Equal(t, "Rezoning LOC2026-0023", rss.Channel.Items[1].Title)
+	assert.Equal(t, combined.Channel.LastBuildDate, rss.Channel.LastBuildDate)
+
+	// The combined feed is still in English
+	assert.Equal(t, "🏗️ Development Permit: DP2026-01776 - 2807 27 ST SW", combined.Channel.Items[0].Title)
+	assert.Contains(t, combined.Channel.Items[0].Description.Text, "<li>Must Commence By: August 1, 2027</li>")
+}
+
+func Test_LanguageFeedPath(t *testing.T) {
+	assert.Equal(t, "./output/killarney-development.fr.xml", languageFeedPath("fr"))
+}
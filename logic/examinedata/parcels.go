@@ -0,0 +1,49 @@
+package examinedata
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/objects/parcel"
+)
+
+// updateParcelIndex - Adds the stored records to the parcel index, which keeps them after they leave the fetched data
+func updateParcelIndex() error {
+	dpRecords, raRecords, err := storedActivityRecords()
+	if err != nil {
+		return err
+	}
+
+	index, err := parcel.Load(parcel.IndexPath)
+	if err != nil {
+		return err
+	}
+	if changed := index.Add(append(dpRecords, raRecords...)); changed == 0 {
+		return nil
+	}
+	if err := index.Save(parcel.IndexPath); err != nil {
+		return fmt.Errorf("failed to save parcel index: %v", err)
+	}
+	return nil
+}
+
+func (s *activitySnapshot) listParcels(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	webserver.WriteJSON(w, http.StatusOK, s.parcels.Parcels())
+}
+
+func (s *activitySnapshot) getParcel(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	found, ok := s.parcels.Lookup(r.PathValue("address"))
+	if !ok {
+		webserver.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("no applications found at %s", r.PathValue("address")))
+		return
+	}
+
+	webserver.WriteJSON(w, http.StatusOK, found)
+}
@@ -0,0 +1,57 @@
+package examinedata
+
+import (
+	"fmt"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/related"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+)
+
+// linkRelatedApplications - Links each development permit to the rezoning applications on its parcel and each rezoning to its permits
+// Runs on the run's records before they are saved so new applications are linked in the same run, and refreshes their feed items
+func linkRelatedApplications(rss *rssfeed.RSS, developmentPermits []developmentpermit.DevelopmentPermit, rezoningApplications []rezoningapplications.RezoningApplication) {
+	dpRecords := developmentPermitRecords(developmentPermits)
+	raRecords := rezoningApplicationRecords(rezoningApplications)
+
+	dpChanged := developmentpermit.SetRelatedApplications(developmentPermits, related.FindAll(dpRecords, raRecords, related.DefaultRadiusMeters), rss)
+	raChanged := rezoningapplications.SetRelatedApplications(rezoningApplications, related.FindAll(raRecords, dpRecords, related.DefaultRadiusMeters), rss)
+
+	if dpChanged > 0 || raChanged > 0 {
+		fmt.Printf("Linked related applications for %d development permits and %d rezoning applications\n", dpChanged, raChanged)
+	}
+}
+
+// storedActivityRecords - Builds the activity records of the stored development permits and rezoning applications
+func storedActivityRecords() ([]activity.Record, []activity.Record, error) {
+	developmentPermits, dpErr := developmentpermit.LoadStoredDevelopmentPermits()
+	if dpErr != nil {
+		return nil, nil, fmt.Errorf("failed to load development permits: %v", dpErr)
+	}
+	rezoningApplications, raErr := rezoningapplications.LoadStoredRezoningApplications()
+	if raErr != nil {
+		return nil, nil, fmt.Errorf("failed to load rezoning applications: %v", raErr)
+	}
+
+	return developmentPermitRecords(developmentPermits), rezoningApplicationRecords(rezoningApplications), nil
+}
+
+// developmentPermitRecords - Builds the activity records of development permits
+func developmentPermitRecords(developmentPermits []developmentpermit.DevelopmentPermit) []activity.Record {
+	records := make([]activity.Record, 0, len(developmentPermits))
+	for _, dp := range developmentPermits {
+		records = append(records, dp.ActivityRecord())
+	}
+	return records
+}
+
+// rezoningApplicationRecords - Builds the activity records of rezoning applications
+func rezoningApplicationRecords(rezoningApplications []rezoningapplications.RezoningApplication) []activity.Record {
+	records := make([]activity.Record, 0, len(rezoningApplications))
+	for _, ra := range rezoningApplications {
+		records = append(records, ra.ActivityRecord())
+	}
+	return records
+}
@@ -0,0 +1,44 @@
+package examinedata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/related"
+	"github.com/jeffadavidson/development-bot/objects/rezoningapplications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LinkRelatedApplications_LinksRecordsBeforeTheyAreSaved(t *testing.T) {
+	developmentPermits := []developmentpermit.DevelopmentPermit{
+		{PermitNum: "DP2026-00867", StatusCurrent: "Hold", Address: stringPointer("2823 30 ST SW")},
+		{PermitNum: "DP2026-01738", StatusCurrent: "New", Address: stringPointer("2936 26 AV SW")},
+	}
+	rezoningApplications := []rezoningapplications.RezoningApplication{
+		{PermitNum: "LOC2026-0009", StatusCurrent: "Miscellaneous", Address: stringPointer("2823 30 ST SW")},
+	}
+	rss := rssfeed.CreateRSSFeed("Test", "Test", "https://calgary.ca/development")
+
+	linkRelatedApplications(rss, developmentPermits, rezoningApplications)
+
+	require.Len(t, developmentPermits[0].Related, 1)
+	assert.Equal(t, "LOC2026-0009", developmentPermits[0].Related[0].PermitNum)
+	assert.Equal(t, related.ReasonSameAddress, developmentPermits[0].Related[0].Reason)
+	assert.Empty(t, developmentPermits[1].Related)
+	require.Len(t, rezoningApplications[0].Related, 1)
+	assert.Equal(t, "DP2026-00867", rezoningApplications[0].Related[0].PermitNum)
+
+	// Linking alone does not add items to the feed
+	assert.Empty(t, rss.Channel.Items)
+}
+
+func Test_PartialFailureError(t *testing.T) {
+	var err error = &PartialFailureError{Errors: []error{errors.New("failed to process rezoning applications: timeout")}}
+
+	var partialFailure *PartialFailureError
+	assert.True(t, errors.As(err, &partialFailure))
+	assert.Equal(t, "failed to process rezoning applications: timeout", err.Error())
+}
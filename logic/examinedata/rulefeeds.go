@@ -0,0 +1,53 @@
+package examinedata
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+)
+
+// ruleFeedDir - alert rule feeds are written next to the combined feed
+var ruleFeedDir = filepath.Dir(combinedFeedPath)
+
+// writeRuleFeeds - copies the combined feed items of each alert rule's matched actions into the rule's own feed
+func writeRuleFeeds(combined *rssfeed.RSS, feeds map[string][]fileaction.FileAction) error {
+	var failures []string
+	for name, actions := range feeds {
+		path := filepath.Join(ruleFeedDir, name+".xml")
+		rss, err := rssfeed.GetOrCreateRSSFeed(
+			path,
+			fmt.Sprintf("%s: %s", combined.Channel.Title, name),
+			fmt.Sprintf("Development activity matching the %s alert rule", name),
+			combined.Channel.Link,
+		)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", name, err.Error()))
+			continue
+		}
+
+		changed := false
+		for _, action := range actions {
+			if item := combined.FindItemByGUID(action.GUID); item != nil && rss.UpsertItem(*item) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		rss.SortByPubDate()
+		rss.TrimToMaxItems(200)
+		if _, err := rssfeed.SaveRSSFeed(rss, path); err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", name, err.Error()))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to write alert rule feeds: %s", strings.Join(failures, ", "))
+	}
+
+	return nil
+}
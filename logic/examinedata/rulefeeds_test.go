@@ -0,0 +1,42 @@
+package examinedata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteRuleFeeds(t *testing.T) {
+	previousDir := ruleFeedDir
+	ruleFeedDir = t.TempDir()
+	t.Cleanup(func() { ruleFeedDir = previousDir })
+
+	combined := rssfeed.CreateRSSFeed("Killarney Development Activity", "All activity", "https://calgary.ca/development")
+	pubDate := time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC)
+	combined.AddItem("Rezoning LOC2026-0023", "Desc", "https://example.com/LOC2026-0023", "guid-rezoning", pubDate, "Land Use Rezoning", "", "", "", "Content")
+	combined.AddItem("Permit DP2026-01776", "Desc", "https://example.com/DP2026-01776", "guid-permit", pubDate, "Development Permit", "", "", "", "Content")
+
+	err := writeRuleFeeds(combined, map[string][]fileaction.FileAction{
+		"committee": {{PermitNum: "LOC2026-0023", GUID: "guid-rezoning"}},
+		"quiet":     {},
+	})
+	require.NoError(t, err)
+
+	xmlData, err := os.ReadFile(filepath.Join(ruleFeedDir, "committee.xml"))
+	require.NoError(t, err)
+	feed, err := rssfeed.LoadRSSFromXML(xmlData)
+	require.NoError(t, err)
+	assert.Equal(t, "Killarney Development Activity: committee", feed.Channel.Title)
+	require.Len(t, feed.Channel.Items, 1)
+	assert.Equal(t, "guid-rezoning", feed.Channel.Items[0].GUID.Value)
+
+	// Feeds with nothing new are left alone
+	_, err = os.Stat(filepath.Join(ruleFeedDir, "quiet.xml"))
+	assert.True(t, os.IsNotExist(err))
+}
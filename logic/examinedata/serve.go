@@ -0,0 +1,92 @@
+package examinedata
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/interactions/websub"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/fileio"
+	"github.com/jeffadavidson/development-bot/utilities/metrics"
+)
+
+// ServeDevelopmentActivity - Runs the bot as a long lived server that refreshes the feed on an interval and serves it over HTTP
+func ServeDevelopmentActivity() error {
+	server := webserver.NewServer()
+	snapshot := &activitySnapshot{}
+	feedURLPath := "/" + filepath.Base(combinedFeedPath)
+	server.HandleFunc("GET "+feedURLPath, serveFeed(server, snapshot))
+	registerAPI(server, snapshot)
+
+	health := &runHealth{}
+	server.HandleFunc("GET /healthz", health.serveHealthz)
+	server.HandleFunc("GET /readyz", health.serveReadyz)
+	server.HandleFunc("GET /metrics", metrics.Default.ServeHTTP)
+
+	if config.Config.WebSub.BuiltinHub && config.Config.WebSub.HubURL != "" && config.Config.WebSub.SelfURL != "" {
+		// The hub only takes subscriptions to a feed this server publishes, the self URL may sit behind a path prefix
+		if selfURL, err := url.Parse(config.Config.WebSub.SelfURL); err != nil || !strings.HasSuffix(selfURL.Path, feedURLPath) {
+			fmt.Printf("Not running the WebSub hub, websub self-url %s is not the served feed %s\n", config.Config.WebSub.SelfURL, feedURLPath)
+		} else {
+			builtinHub = websub.NewHub(config.Config.WebSub.HubURL, config.Config.WebSub.SelfURL)
+			server.HandleFunc("/websub", builtinHub.ServeHTTP)
+			fmt.Printf("Running WebSub hub for %s at %s\n", config.Config.WebSub.SelfURL, config.Config.WebSub.HubURL)
+		}
+	}
+
+	// Serve the last saved feed straight away so readers are not turned away while the first run fetches data
+	if xmlData, err := fileio.GetFileContents(combinedFeedPath); err == nil {
+		if rss, loadErr := rssfeed.LoadRSSFromXML(xmlData); loadErr == nil {
+			publish(server, snapshot, feedURLPath, rss)
+		}
+	}
+
+	go refreshDevelopmentActivity(server, snapshot, health, feedURLPath, config.Config.Server.RefreshInterval)
+
+	fmt.Printf("Serving development activity on %s%s\n", config.Config.Server.Address, feedURLPath)
+	return server.ListenAndServe(config.Config.Server.Address)
+}
+
+// refreshDevelopmentActivity - processes development activity now and then on every interval, publishing each new feed
+func refreshDevelopmentActivity(server *webserver.Server, snapshot *activitySnapshot, health *runHealth, feedURLPath string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		refreshOnce(server, snapshot, health, feedURLPath)
+		<-ticker.C
+	}
+}
+
+// refreshOnce - processes development activity, publishes the new feed and then tells WebSub subscribers about it
+func refreshOnce(server *webserver.Server, snapshot *activitySnapshot, health *runHealth, feedURLPath string) {
+	rss, changed, err := processDevelopmentActivity(context.Background())
+	health.record(err)
+	if err != nil {
+		fmt.Printf("Failed to process development activity: %v\n", err)
+	}
+	// A partial failure still wrote the feed for the datasets that succeeded
+	if rss != nil {
+		publish(server, snapshot, feedURLPath, rss)
+	}
+	// Only announced once served, so a hub fetching the feed gets the new one
+	if changed {
+		announceFeedUpdate(rss)
+	}
+}
+
+// publish - makes a feed and the records behind it available to readers
+func publish(server *webserver.Server, snapshot *activitySnapshot, feedURLPath string, rss *rssfeed.RSS) {
+	if err := server.PublishRSS(feedURLPath, rss); err != nil {
+		fmt.Println(err.Error())
+	}
+	if err := snapshot.refresh(rss); err != nil {
+		fmt.Println(err.Error())
+	}
+}
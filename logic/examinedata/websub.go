@@ -0,0 +1,46 @@
+package examinedata
+
+import (
+	"fmt"
+
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/interactions/websub"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+)
+
+// builtinHub - the hub run by server mode when websub.builtin-hub is set, nil otherwise
+var builtinHub *websub.Hub
+
+// announceFeedUpdate - tells WebSub subscribers that the feed changed, through the built in hub or by pinging the configured hub
+func announceFeedUpdate(rss *rssfeed.RSS) {
+	hubURL := config.Config.WebSub.HubURL
+	selfURL := config.Config.WebSub.SelfURL
+	if hubURL == "" || selfURL == "" {
+		return
+	}
+
+	if builtinHub != nil {
+		xmlData, err := rss.ToXML()
+		if err != nil {
+			fmt.Printf("Failed to distribute feed to WebSub subscribers: %v\n", err)
+			return
+		}
+		if err := builtinHub.Publish(selfURL, "application/rss+xml; charset=utf-8", xmlData); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Printf("Distributed feed to %d WebSub subscriber(s)\n", builtinHub.SubscriberCount(selfURL))
+		return
+	}
+
+	if config.Config.WebSub.BuiltinHub {
+		// The built in hub only exists in server mode, a one off run has no subscribers to tell
+		return
+	}
+
+	if err := websub.PingHub(hubURL, selfURL); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Pinged WebSub hub %s\n", hubURL)
+}
@@ -0,0 +1,125 @@
+package examinedata
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/fakesocrata"
+	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/interactions/webserver"
+	"github.com/jeffadavidson/development-bot/interactions/websub"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withWebSubConfig(t *testing.T, settings config.WebSub) {
+	previous := config.Config.WebSub
+	config.Config.WebSub = settings
+	t.Cleanup(func() {
+		config.Config.WebSub = previous
+		builtinHub = nil
+	})
+}
+
+func Test_AnnounceFeedUpdate_PingsConfiguredHub(t *testing.T) {
+	var pings atomic.Int32
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hub.Close()
+	withWebSubConfig(t, config.WebSub{HubURL: hub.URL, SelfURL: "https://example.com/feed.xml"})
+
+	announceFeedUpdate(rssfeed.CreateRSSFeed("Test", "Test", "https://example.com"))
+
+	assert.Equal(t, int32(1), pings.Load())
+}
+
+func Test_AnnounceFeedUpdate_NotConfigured(t *testing.T) {
+	var pings atomic.Int32
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings.Add(1)
+	}))
+	defer hub.Close()
+	withWebSubConfig(t, config.WebSub{HubURL: hub.URL})
+
+	announceFeedUpdate(rssfeed.CreateRSSFeed("Test", "Test", "https://example.com"))
+
+	assert.Equal(t, int32(0), pings.Load())
+}
+
+func Test_AnnounceFeedUpdate_DistributesThroughBuiltinHub(t *testing.T) {
+	received := make(chan string, 1)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			io.WriteString(w, r.URL.Query().Get("hub.challenge"))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer subscriber.Close()
+
+	selfURL := "https://example.com/feed.xml"
+	withWebSubConfig(t, config.WebSub{HubURL: "https://example.com/websub", SelfURL: selfURL, BuiltinHub: true})
+	builtinHub = websub.NewHub("https://example.com/websub", selfURL)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/websub", nil)
+	req.PostForm = map[string][]string{"hub.mode": {"subscribe"}, "hub.topic": {selfURL}, "hub.callback": {subscriber.URL}}
+	builtinHub.ServeHTTP(recorder, req)
+	assert.Eventually(t, func() bool { return builtinHub.SubscriberCount(selfURL) == 1 }, time.Second, 10*time.Millisecond)
+
+	announceFeedUpdate(rssfeed.CreateRSSFeed("Pushed Feed", "Test", "https://example.com"))
+
+	select {
+	case body := <-received:
+		assert.Contains(t, body, "<title>Pushed Feed</title>")
+	case <-time.After(time.Second):
+		t.Fatal("subscriber did not receive the feed")
+	}
+}
+
+func Test_RefreshOnce_PingsHubAfterPublishing(t *testing.T) {
+	scenario, err := fakesocrata.LoadScenario("../../interactions/fakesocrata/testdata/lifecycle.json")
+	require.NoError(t, err)
+	fake := fakesocrata.New(scenario)
+	socrata := httptest.NewServer(fake)
+	defer socrata.Close()
+	emptyRun(t, socrata.URL, true)
+	citytime.Freeze(fake.Now())
+
+	server := webserver.NewServer()
+	feedURLPath := "/" + filepath.Base(combinedFeedPath)
+	feedServer := httptest.NewServer(server)
+	defer feedServer.Close()
+
+	// The hub fetches the feed as soon as it is pinged, as a real one would
+	fetched := make(chan string, 1)
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, err := http.Get(feedServer.URL + feedURLPath)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		fetched <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hub.Close()
+	withWebSubConfig(t, config.WebSub{HubURL: hub.URL, SelfURL: feedServer.URL + feedURLPath})
+
+	refreshOnce(server, &activitySnapshot{}, &runHealth{}, feedURLPath)
+
+	select {
+	case body := <-fetched:
+		assert.Contains(t, body, "LOC2026-0031")
+	default:
+		t.Fatal("the hub was not pinged")
+	}
+}
@@ -0,0 +1,161 @@
+package feedfilter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/toolbox"
+)
+
+// defaultRadiusMeters - radius used when a near point is given without one
+const defaultRadiusMeters = 500
+
+// datasetAliases - accepted values for the type parameter and the dataset they select
+var datasetAliases = map[string]string{
+	"permit":               activity.DevelopmentPermit,
+	"permits":              activity.DevelopmentPermit,
+	"dp":                   activity.DevelopmentPermit,
+	"development-permit":   activity.DevelopmentPermit,
+	"rezoning":             activity.RezoningApplication,
+	"rezonings":            activity.RezoningApplication,
+	"rezoning-application": activity.RezoningApplication,
+}
+
+// Filter - selects development activity records, every populated criteria must match
+type Filter struct {
+	Datasets []string
+	Statuses []string
+	Wards    []string
+	// Increases - land use limits, such as max-height, that a rezoning must raise
+	Increases    []string
+	Since        *time.Time
+	Near         *geo.Point
+	RadiusMeters float64
+}
+
+// ParseQuery - Builds a filter from query parameters such as ?type=rezoning&status=under+review&ward=8&since=2026-01-01&near=51.03,-114.13&radius=500m
+func ParseQuery(query url.Values) (Filter, error) {
+	var filter Filter
+
+	for _, value := range splitValues(query["type"]) {
+		dataset, err := ParseDataset(value)
+		if err != nil {
+			return filter, err
+		}
+		if !toolbox.SliceContains(filter.Datasets, dataset) {
+			filter.Datasets = append(filter.Datasets, dataset)
+		}
+	}
+
+	filter.Statuses = splitValues(query["status"])
+	filter.Wards = splitValues(query["ward"])
+
+	for _, value := range splitValues(query["increases"]) {
+		aspect, err := landuse.ParseLimit(value)
+		if err != nil {
+			return filter, err
+		}
+		if !toolbox.SliceContains(filter.Increases, aspect) {
+			filter.Increases = append(filter.Increases, aspect)
+		}
+	}
+
+	if since := query.Get("since"); since != "" {
+		sinceDate, err := time.ParseInLocation("2006-01-02", since, citytime.Location())
+		if err != nil {
+			return filter, fmt.Errorf("since '%s' must be a date in the form YYYY-MM-DD", since)
+		}
+		filter.Since = &sinceDate
+	}
+
+	if near := query.Get("near"); near != "" {
+		point, err := geo.ParsePoint(near)
+		if err != nil {
+			return filter, err
+		}
+		filter.Near = &point
+		filter.RadiusMeters = defaultRadiusMeters
+	}
+
+	if radius := query.Get("radius"); radius != "" {
+		if filter.Near == nil {
+			return filter, fmt.Errorf("radius requires a near point")
+		}
+		radiusMeters, err := geo.ParseDistance(radius)
+		if err != nil {
+			return filter, err
+		}
+		filter.RadiusMeters = radiusMeters
+	}
+
+	return filter, nil
+}
+
+// ParseDataset - Looks up the dataset selected by a type such as permit or rezoning
+func ParseDataset(value string) (string, error) {
+	dataset, found := datasetAliases[strings.ToLower(strings.TrimSpace(value))]
+	if !found {
+		return "", fmt.Errorf("unknown type '%s', expected permit or rezoning", value)
+	}
+
+	return dataset, nil
+}
+
+// Matches - Checks if a record passes every criteria of the filter
+func (f Filter) Matches(record activity.Record) bool {
+	if len(f.Datasets) > 0 && !toolbox.SliceContains(f.Datasets, record.Dataset) {
+		return false
+	}
+
+	if len(f.Statuses) > 0 && !toolbox.SliceContains(f.Statuses, strings.ToLower(strings.TrimSpace(record.Status))) {
+		return false
+	}
+
+	// Records without a ward, such as rezoning applications, can never match a ward filter
+	if len(f.Wards) > 0 && !toolbox.SliceContains(f.Wards, strings.ToLower(strings.TrimSpace(record.Ward))) {
+		return false
+	}
+
+	if len(f.Increases) > 0 && !record.IncreasesAny(f.Increases) {
+		return false
+	}
+
+	// Records without a recorded date can't be shown to be recent, so they never match a since filter
+	if f.Since != nil && (record.LastUpdated.IsZero() || record.LastUpdated.Before(*f.Since)) {
+		return false
+	}
+
+	if f.Near != nil {
+		if record.Location == nil || geo.DistanceMeters(*f.Near, *record.Location) > f.RadiusMeters {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsEmpty - Checks if the filter has no criteria and would match every record
+func (f Filter) IsEmpty() bool {
+	return len(f.Datasets) == 0 && len(f.Statuses) == 0 && len(f.Wards) == 0 && len(f.Increases) == 0 && f.Since == nil && f.Near == nil
+}
+
+// splitValues - lower cases and splits repeated or comma separated parameter values
+func splitValues(values []string) []string {
+	var split []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part != "" {
+				split = append(split, part)
+			}
+		}
+	}
+
+	return split
+}
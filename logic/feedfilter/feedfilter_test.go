@@ -0,0 +1,149 @@
+package feedfilter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord() activity.Record {
+	return activity.Record{
+		Dataset:     activity.RezoningApplication,
+		PermitNum:   "LOC2026-0023",
+		Status:      "Under Review",
+		Ward:        "8",
+		Location:    &geo.Point{Latitude: 51.02656816016038, Longitude: -114.12936552510145},
+		LastUpdated: time.Date(2026, 2, 27, 13, 0, 36, 0, time.UTC),
+	}
+}
+
+func Test_ParseQuery_AllParameters(t *testing.T) {
+	query, _ := url.ParseQuery("type=rezoning&status=under+review&ward=8&since=2026-01-01&near=51.03,-114.13&radius=500m")
+
+	filter, err := ParseQuery(query)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{activity.RezoningApplication}, filter.Datasets)
+	assert.Equal(t, []string{"under review"}, filter.Statuses)
+	assert.Equal(t, []string{"8"}, filter.Wards)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, citytime.Location()), *filter.Since)
+	assert.Equal(t, geo.Point{Latitude: 51.03, Longitude: -114.13}, *filter.Near)
+	assert.Equal(t, 500.0, filter.RadiusMeters)
+}
+
+func Test_ParseQuery_Empty(t *testing.T) {
+	filter, err := ParseQuery(url.Values{})
+	require.NoError(t, err)
+	assert.True(t, filter.IsEmpty())
+	assert.True(t, filter.Matches(testRecord()))
+}
+
+func Test_ParseQuery_CommaSeparatedValues(t *testing.T) {
+	query, _ := url.ParseQuery("type=permit,rezoning&status=Hold&status=In+Circulation")
+
+	filter, err := ParseQuery(query)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{activity.DevelopmentPermit, activity.RezoningApplication}, filter.Datasets)
+	assert.Equal(t, []string{"hold", "in circulation"}, filter.Statuses)
+}
+
+func Test_ParseQuery_DefaultRadius(t *testing.T) {
+	query, _ := url.ParseQuery("near=51.03,-114.13")
+
+	filter, err := ParseQuery(query)
+	require.NoError(t, err)
+	assert.Equal(t, 500.0, filter.RadiusMeters)
+}
+
+func Test_ParseQuery_Invalid(t *testing.T) {
+	invalidQueries := []string{
+		"type=house",
+		"since=January",
+		"near=north",
+		"radius=500m",
+		"near=51.03,-114.13&radius=far",
+		"increases=width",
+	}
+
+	for _, raw := range invalidQueries {
+		query, _ := url.ParseQuery(raw)
+		_, err := ParseQuery(query)
+		assert.Error(t, err, raw)
+	}
+}
+
+func Test_Matches_Criteria(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"type=rezoning", true},
+		{"type=permit", false},
+		{"status=under+review", true},
+		{"status=UNDER+REVIEW", true},
+		{"status=approved", false},
+		{"ward=8", true},
+		{"ward=6", false},
+		{"since=2026-02-01", true},
+		{"since=2026-03-01", false},
+		{"near=51.0266,-114.1294&radius=100m", true},
+		{"near=51.0300,-114.1300&radius=300m", false},
+		{"type=rezoning&status=under+review&ward=8&since=2026-01-01&near=51.03,-114.13&radius=500m", true},
+	}
+
+	for _, test := range tests {
+		query, _ := url.ParseQuery(test.query)
+		filter, err := ParseQuery(query)
+		require.NoError(t, err, test.query)
+		assert.Equal(t, test.expected, filter.Matches(testRecord()), test.query)
+	}
+}
+
+func Test_Matches_Increases(t *testing.T) {
+	query, _ := url.ParseQuery("increases=height,density")
+	filter, err := ParseQuery(query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{landuse.AspectMaxHeight, landuse.AspectMaxDensity}, filter.Increases)
+	assert.False(t, filter.IsEmpty())
+
+	record := testRecord()
+	record.CurrentDistricts = landuse.Explain("M-C1 d75")
+	record.ProposedDistricts = landuse.Explain("M-C1 d100")
+	assert.True(t, filter.Matches(record))
+
+	record.ProposedDistricts = landuse.Explain("M-C1")
+	assert.False(t, filter.Matches(record))
+
+	assert.False(t, filter.Matches(testRecord()))
+}
+
+func Test_Matches_NearWithoutLocation(t *testing.T) {
+	record := testRecord()
+	record.Location = nil
+
+	query, _ := url.ParseQuery("near=51.03,-114.13&radius=5km")
+	filter, err := ParseQuery(query)
+	require.NoError(t, err)
+
+	assert.False(t, filter.Matches(record))
+}
+
+func Test_Matches_SinceWithoutTimestamp(t *testing.T) {
+	record := testRecord()
+	record.LastUpdated = time.Time{}
+
+	query, _ := url.ParseQuery("since=2026-01-01")
+	filter, err := ParseQuery(query)
+	require.NoError(t, err)
+
+	assert.False(t, filter.Matches(record))
+	assert.True(t, Filter{}.Matches(record))
+}
@@ -0,0 +1,99 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Notifiers_DeliverNewPermitAsWritten - sends a new permit's information message through every notifier,
+// upstream text arrives as written, escaped once and only where the destination's format needs it
+func Test_Notifiers_DeliverNewPermitAsWritten(t *testing.T) {
+	action := permitAction("CREATE")
+	actions := []fileaction.FileAction{action}
+
+	var lock sync.Mutex
+	received := map[string][]byte{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		lock.Lock()
+		received[r.URL.Path] = body
+		lock.Unlock()
+		if r.URL.Path == "/xrpc/com.atproto.server.createSession" {
+			w.Write([]byte(`{"accessJwt": "jwt-123", "did": "did:plc:abc123"}`))
+		}
+	}))
+	defer ts.Close()
+
+	for _, format := range []string{"json", "slack", "discord"} {
+		notifier := NewWebhookNotifier(config.Webhook{Name: format, URL: ts.URL + "/" + format, Format: format, MaxPerRun: 5})
+		require.NoError(t, notifier.Notify(context.Background(), actions))
+	}
+	require.NoError(t, NewMastodonPublisher(config.Mastodon{InstanceURL: ts.URL, AccessToken: "token-123", MaxPerRun: 5, MaxCharacters: 500}).Notify(context.Background(), actions))
+	require.NoError(t, NewBlueskyPublisher(config.Bluesky{ServiceURL: ts.URL, Handle: "killarney.example.com", AppPassword: "app-password", MaxPerRun: 5}).Notify(context.Background(), actions))
+
+	now := time.Date(2026, 3, 28, 9, 0, 0, 0, citytime.Location())
+	digest, sent := newTestDigest(t, "daily", &now)
+	require.NoError(t, digest.Notify(context.Background(), actions))
+	now = now.AddDate(0, 0, 1)
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	require.NotEmpty(t, *sent)
+
+	// JSON webhooks carry the Markdown message, escaped for Markdown and nothing else
+	var jsonBody jsonPayload
+	require.NoError(t, json.Unmarshal(received["/json"], &jsonBody))
+	assert.Contains(t, jsonBody.Message, "**Applicant:** SMITH & SONS (2021) LTD.\n")
+	assert.Contains(t, jsonBody.Message, `**Description:** NEW: SIGN - CLASS B (FASCIA SIGN) \*LIT\* \<PYLON>`)
+
+	// Slack escapes &, < and > itself, once
+	var slackBody slackPayload
+	require.NoError(t, json.Unmarshal(received["/slack"], &slackBody))
+	require.Len(t, slackBody.Blocks, 2)
+	assert.Contains(t, slackBody.Blocks[1].Fields, slackText{Type: "mrkdwn", Text: "*Applicant*\nSMITH &amp; SONS (2021) LTD."})
+
+	var discordBody discordPayload
+	require.NoError(t, json.Unmarshal(received["/discord"], &discordBody))
+	require.Len(t, discordBody.Embeds, 1)
+	assert.Contains(t, discordBody.Embeds[0].Fields, discordField{Name: "Applicant", Value: "SMITH & SONS (2021) LTD."})
+
+	// Social posts are plain text
+	var mastodonBody map[string]string
+	require.NoError(t, json.Unmarshal(received["/api/v1/statuses"], &mastodonBody))
+	assert.Contains(t, mastodonBody["status"], "at #A 3214 28 ST SW: NEW: SIGN - CLASS B (FASCIA SIGN) *LIT* <PYLON>")
+
+	var blueskyBody struct {
+		Record struct {
+			Text string `json:"text"`
+		} `json:"record"`
+	}
+	require.NoError(t, json.Unmarshal(received["/xrpc/com.atproto.repo.createRecord"], &blueskyBody))
+	assert.Contains(t, blueskyBody.Record.Text, "at #A 3214 28 ST SW: NEW: SIGN - CLASS B (FASCIA SIGN) *LIT* <PYLON>")
+
+	// Email has a plain text part and an HTML part escaped once
+	message := (*sent)[0]
+	assert.Contains(t, message.Text, "  Applicant: SMITH & SONS (2021) LTD.\n")
+	assert.Contains(t, message.HTML, "SMITH &amp; SONS (2021) LTD.")
+
+	// Nothing arrives escaped twice or with Markdown escapes showing
+	for path, body := range received {
+		assert.NotContains(t, string(body), "&amp;amp;", path)
+		assert.NotContains(t, string(body), `SONS \\(2021\\)`, path)
+	}
+	for _, part := range []string{message.Text, message.HTML} {
+		assert.NotContains(t, part, "&amp;amp;")
+		assert.NotContains(t, part, `\(`)
+	}
+	assert.NotContains(t, message.Text, "&amp;")
+}
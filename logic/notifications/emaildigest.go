@@ -0,0 +1,356 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/email"
+	"github.com/jeffadavidson/development-bot/objects/community"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/fileio"
+	"golang.org/x/exp/slices"
+)
+
+// pendingDigestPath - actions collected since the last digest was sent
+const pendingDigestPath = "./data/email-digest.json"
+
+// digestSections - the action types actions are grouped by in the digest, in the order they are shown
+var digestSections = []string{"CREATE", "UPDATE", "CLOSE"}
+
+// markdownHeading - the marker at the start of a Markdown heading
+var markdownHeading = regexp.MustCompile(`^#{1,6} +`)
+
+// markdownSyntax - the backslash escapes, entities, bold markers and links of a Markdown information message
+var markdownSyntax = regexp.MustCompile(`\\(.)|&#?[A-Za-z0-9]+;|\*\*|\[((?:\\.|[^\]\\])*)\]\(([^)\s]+)\)`)
+
+// EmailDigest - collects each run's actions and emails them to subscribers once a day or once a week
+type EmailDigest struct {
+	settings    config.Email
+	pendingPath string
+	now         func() time.Time
+	send        func(message email.Message) error
+}
+
+// pendingDigest - the digest being collected, stored between runs
+type pendingDigest struct {
+	PeriodStart time.Time     `json:"period_start"`
+	Entries     []digestEntry `json:"entries"`
+	// Unsent - ended digests that some subscribers have not received yet, retried on every run until they have
+	Unsent []unsentDigest `json:"unsent,omitempty"`
+}
+
+// unsentDigest - an ended digest and the subscribers it still has to reach
+type unsentDigest struct {
+	PeriodStart time.Time     `json:"period_start"`
+	Entries     []digestEntry `json:"entries"`
+	Subscribers []string      `json:"subscribers"`
+}
+
+// digestEntry - one action waiting to be sent
+type digestEntry struct {
+	PermitNum string `json:"permitnum"`
+	Action    string `json:"action"`
+	Title     string `json:"title"`
+	Link      string `json:"link"`
+	// Message - the Markdown information message of a new item, or the plain text changes of an update or closure
+	Message string `json:"message"`
+	// Description - the HTML feed description of the item
+	Description string `json:"description,omitempty"`
+	// Position - where the action is, such as "Adjacent · RICHMOND · 350 m southeast of the community centre"
+	Position string    `json:"position,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// NewEmailDigest - Creates an email digest notifier from the email settings
+func NewEmailDigest(settings config.Email) *EmailDigest {
+	server := email.Server{
+		Host:     settings.SMTPHost,
+		Port:     settings.SMTPPort,
+		Username: settings.Username,
+		Password: settings.Password,
+	}
+
+	return &EmailDigest{
+		settings:    settings,
+		pendingPath: pendingDigestPath,
+		now:         citytime.Now,
+		send:        func(message email.Message) error { return email.Send(server, message) },
+	}
+}
+
+// Name - Alert rules refer to the email digest as email
+func (d *EmailDigest) Name() string {
+	return "email"
+}
+
+// Notify - Adds the actions to the pending digest and sends it once the digest period has ended
+func (d *EmailDigest) Notify(ctx context.Context, actions []fileaction.FileAction) error {
+	digest, err := d.loadPending()
+	if err != nil {
+		return err
+	}
+
+	// Ended digests go to the subscribers that missed them first, anyone who has since unsubscribed is skipped
+	var failures []error
+	var unsent []unsentDigest
+	for _, missed := range digest.Unsent {
+		remaining, err := d.sendDigest(pendingDigest{PeriodStart: missed.PeriodStart, Entries: missed.Entries}, d.subscribed(missed.Subscribers))
+		if err != nil {
+			failures = append(failures, err)
+		}
+		if len(remaining) > 0 {
+			unsent = append(unsent, unsentDigest{PeriodStart: missed.PeriodStart, Entries: missed.Entries, Subscribers: remaining})
+		}
+	}
+	digest.Unsent = unsent
+
+	now := d.now()
+	if digest.PeriodStart.IsZero() {
+		digest.PeriodStart = now
+	}
+	for _, action := range actions {
+		title := action.Title
+		if title == "" {
+			title = action.PermitNum
+		}
+		entry := digestEntry{
+			PermitNum:   action.PermitNum,
+			Action:      action.Action,
+			Title:       title,
+			Link:        action.Link,
+			Message:     action.Message,
+			Description: action.Description,
+			Time:        now,
+		}
+		if position := community.TemplatePosition("", action.Record.Position); position != nil {
+			entry.Position = position.Summary
+		}
+		digest.Entries = append(digest.Entries, entry)
+	}
+
+	if d.periodEnded(digest.PeriodStart, now) {
+		if len(digest.Entries) > 0 {
+			remaining, err := d.sendDigest(digest, d.settings.Subscribers)
+			if err != nil {
+				// Only the subscribers it did not reach get the digest again on the next run
+				failures = append(failures, err)
+				digest.Unsent = append(digest.Unsent, unsentDigest{PeriodStart: digest.PeriodStart, Entries: digest.Entries, Subscribers: remaining})
+			}
+			fmt.Printf("Sent %s email digest of %d actions to %d subscribers\n", d.settings.Digest, len(digest.Entries), len(d.settings.Subscribers)-len(remaining))
+		}
+		digest = pendingDigest{PeriodStart: now, Unsent: digest.Unsent}
+	}
+
+	if err := d.savePending(digest); err != nil {
+		return err
+	}
+	return errors.Join(failures...)
+}
+
+// subscribed - the subscribers that are still in the settings
+func (d *EmailDigest) subscribed(subscribers []string) []string {
+	var still []string
+	for _, subscriber := range subscribers {
+		if slices.Contains(d.settings.Subscribers, subscriber) {
+			still = append(still, subscriber)
+		}
+	}
+	return still
+}
+
+// periodEnded - daily digests are sent on the first run of a new day, weekly digests on the first run of a new week
+func (d *EmailDigest) periodEnded(periodStart time.Time, now time.Time) bool {
+	// Days and weeks are the city's, whatever offset the period start was saved with
+	periodStart = periodStart.In(citytime.Location())
+	now = now.In(citytime.Location())
+	if d.settings.Digest == "weekly" {
+		startYear, startWeek := periodStart.ISOWeek()
+		year, week := now.ISOWeek()
+		return year != startYear || week != startWeek
+	}
+
+	return now.Format("2006-01-02") != periodStart.Format("2006-01-02")
+}
+
+// sendDigest - emails the digest to each subscriber separately so addresses are not shared, returns the subscribers it failed to reach
+func (d *EmailDigest) sendDigest(digest pendingDigest, subscribers []string) ([]string, error) {
+	subject, text, htmlBody := d.composeDigest(digest)
+
+	var failed []string
+	var failures []string
+	for _, subscriber := range subscribers {
+		message := email.Message{
+			From:    d.settings.From,
+			To:      subscriber,
+			Subject: subject,
+			Text:    text,
+			HTML:    htmlBody,
+		}
+		if err := d.send(message); err != nil {
+			failed = append(failed, subscriber)
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return failed, fmt.Errorf("failed to send email digest to %d of %d subscribers: %s", len(failures), len(subscribers), strings.Join(failures, "; "))
+	}
+
+	return nil, nil
+}
+
+// composeDigest - builds the subject and the plain text and HTML bodies of a digest
+func (d *EmailDigest) composeDigest(digest pendingDigest) (string, string, string) {
+	neighborhood := config.Config.Neighborhood.Name
+	if neighborhood == "" {
+		neighborhood = "Neighborhood"
+	}
+
+	var counts []string
+	var text strings.Builder
+	var htmlBody strings.Builder
+
+	period := "Daily"
+	if d.settings.Digest == "weekly" {
+		period = "Weekly"
+	}
+	heading := fmt.Sprintf("%s development activity since %s", neighborhood, digest.PeriodStart.Format("Mon Jan 2, 2006"))
+	text.WriteString(heading + "\n")
+	htmlBody.WriteString("<html><body style='font-family: sans-serif;'>")
+	htmlBody.WriteString(fmt.Sprintf("<h2>%s</h2>", html.EscapeString(heading)))
+
+	for _, section := range digestSections {
+		sectionHeading := actionLabel(section)
+		var entries []digestEntry
+		for _, entry := range digest.Entries {
+			if entry.Action == section {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		counts = append(counts, fmt.Sprintf("%d %s", len(entries), strings.ToLower(sectionHeading)))
+
+		text.WriteString(fmt.Sprintf("\n%s (%d)\n", strings.ToUpper(sectionHeading), len(entries)))
+		htmlBody.WriteString(fmt.Sprintf("<h3>%s (%d)</h3><ul>", sectionHeading, len(entries)))
+		for _, entry := range entries {
+			text.WriteString(fmt.Sprintf("- %s\n", entry.Title))
+			if entry.Link != "" {
+				text.WriteString(fmt.Sprintf("  %s\n", entry.Link))
+			}
+			if entry.Position != "" {
+				text.WriteString(fmt.Sprintf("  %s\n", entry.Position))
+			}
+			for _, line := range strings.Split(entryText(entry), "\n") {
+				if strings.TrimSpace(line) != "" {
+					text.WriteString(fmt.Sprintf("  %s\n", strings.TrimSpace(line)))
+				}
+			}
+
+			htmlBody.WriteString("<li>")
+			if entry.Link != "" {
+				htmlBody.WriteString(fmt.Sprintf("<a href='%s'>%s</a>", html.EscapeString(entry.Link), html.EscapeString(entry.Title)))
+			} else {
+				htmlBody.WriteString(html.EscapeString(entry.Title))
+			}
+			if entry.Position != "" {
+				htmlBody.WriteString(fmt.Sprintf("<br><small>%s</small>", html.EscapeString(entry.Position)))
+			}
+			// The changes of an update or closure, then the item as the feed describes it
+			if entry.Action != "CREATE" || entry.Description == "" {
+				if message := entryText(entry); message != "" {
+					htmlBody.WriteString(fmt.Sprintf("<br><span style='color: #555;'>%s</span>", strings.ReplaceAll(html.EscapeString(message), "\n", "<br>")))
+				}
+			}
+			if entry.Description != "" {
+				// Already HTML, with every value escaped by the description template
+				htmlBody.WriteString(fmt.Sprintf("<div style='color: #555;'>%s</div>", entry.Description))
+			}
+			htmlBody.WriteString("</li>")
+		}
+		htmlBody.WriteString("</ul>")
+	}
+
+	footer := "You are receiving this because you subscribed to development activity updates."
+	text.WriteString("\n" + footer + "\n")
+	htmlBody.WriteString(fmt.Sprintf("<p style='color: #888; font-size: small;'>%s</p></body></html>", html.EscapeString(footer)))
+
+	subject := fmt.Sprintf("%s %s development digest: %s", period, neighborhood, strings.Join(counts, ", "))
+	return subject, text.String(), htmlBody.String()
+}
+
+// entryText - the message of an entry as plain text, new items carry a Markdown message
+func entryText(entry digestEntry) string {
+	message := strings.TrimSpace(entry.Message)
+	if entry.Action == "CREATE" {
+		message = plainText(message)
+	}
+	return message
+}
+
+// plainText - removes the Markdown syntax from a message, keeping heading text, link text followed by its address and the characters escapes and entities stand for
+func plainText(markdown string) string {
+	var lines []string
+	for _, line := range strings.Split(markdown, "\n") {
+		lines = append(lines, unmarkdown(markdownHeading.ReplaceAllString(strings.TrimSpace(line), "")))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// unmarkdown - replaces the backslash escapes, entities, bold markers and links in a line of Markdown
+func unmarkdown(line string) string {
+	return markdownSyntax.ReplaceAllStringFunc(line, func(match string) string {
+		parts := markdownSyntax.FindStringSubmatch(match)
+		switch {
+		case strings.HasPrefix(match, `\`):
+			return parts[1]
+		case strings.HasPrefix(match, "&"):
+			return html.UnescapeString(match)
+		case match == "**":
+			return ""
+		default:
+			return fmt.Sprintf("%s (%s)", unmarkdown(parts[2]), parts[3])
+		}
+	})
+}
+
+// loadPending - loads the pending digest, starting a new one if none has been saved
+func (d *EmailDigest) loadPending() (pendingDigest, error) {
+	var digest pendingDigest
+	if _, err := os.Stat(d.pendingPath); errors.Is(err, os.ErrNotExist) {
+		return digest, nil
+	}
+
+	digestBytes, err := fileio.GetFileContents(d.pendingPath)
+	if err != nil {
+		return digest, fmt.Errorf("failed to load pending email digest: %v", err)
+	}
+	if err := json.Unmarshal(digestBytes, &digest); err != nil {
+		return digest, fmt.Errorf("failed to parse pending email digest: %v", err)
+	}
+
+	return digest, nil
+}
+
+// savePending - stores the pending digest for the next run
+func (d *EmailDigest) savePending(digest pendingDigest) error {
+	digestBytes, err := json.MarshalIndent(digest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending email digest: %v", err)
+	}
+	if err := fileio.WriteFileContents(d.pendingPath, digestBytes); err != nil {
+		return fmt.Errorf("failed to save pending email digest: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/email"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDigest - a digest that stores its pending file in a temporary directory and records sent messages
+func newTestDigest(t *testing.T, mode string, now *time.Time) (*EmailDigest, *[]email.Message) {
+	var sent []email.Message
+	digest := NewEmailDigest(config.Email{
+		Enabled:     true,
+		From:        "Development Bot <bot@example.com>",
+		Subscribers: []string{"one@example.com", "two@example.com"},
+		Digest:      mode,
+	})
+	digest.pendingPath = filepath.Join(t.TempDir(), "email-digest.json")
+	digest.now = func() time.Time { return *now }
+	digest.send = func(message email.Message) error {
+		sent = append(sent, message)
+		return nil
+	}
+
+	return digest, &sent
+}
+
+func testActions() []fileaction.FileAction {
+	return []fileaction.FileAction{
+		{
+			PermitNum: "DP2026-01776",
+			Action:    "CREATE",
+			Message:   "Development Permit DP2026-01776\n\tAddress: 2807 27 ST SW",
+			Title:     "🏗️ Development Permit: DP2026-01776 - 2807 27 ST SW",
+			Link:      "https://developmentmap.calgary.ca/?find=DP2026-01776",
+		},
+		{
+			PermitNum: "LOC2026-0023",
+			Action:    "CLOSE",
+			Message:   "Status changed to <Approved>",
+			Title:     "🏛️ Rezoning Application: LOC2026-0023 - 3214 29 ST SW",
+			Link:      "https://developmentmap.calgary.ca/?find=LOC2026-0023",
+		},
+	}
+}
+
+func Test_EmailDigest_DailyCollectsUntilNextDay(t *testing.T) {
+	now := time.Date(2026, 3, 28, 9, 0, 0, 0, citytime.Location())
+	digest, sent := newTestDigest(t, "daily", &now)
+
+	require.NoError(t, digest.Notify(context.Background(), testActions()))
+	now = now.Add(10 * time.Hour)
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	assert.Empty(t, *sent)
+
+	now = time.Date(2026, 3, 29, 1, 0, 0, 0, citytime.Location())
+	require.NoError(t, digest.Notify(context.Background(), nil))
+
+	require.Len(t, *sent, 2)
+	assert.Equal(t, "one@example.com", (*sent)[0].To)
+	assert.Equal(t, "two@example.com", (*sent)[1].To)
+	assert.Equal(t, "Daily Neighborhood development digest: 1 new, 1 closed", (*sent)[0].Subject)
+	assert.Contains(t, (*sent)[0].Text, "NEW (1)\n- 🏗️ Development Permit: DP2026-01776 - 2807 27 ST SW\n  https://developmentmap.calgary.ca/?find=DP2026-01776\n")
+	assert.Contains(t, (*sent)[0].HTML, "<a href='https://developmentmap.calgary.ca/?find=LOC2026-0023'>")
+	assert.Contains(t, (*sent)[0].HTML, "Status changed to &lt;Approved&gt;")
+
+	// The next digest starts empty
+	now = time.Date(2026, 3, 30, 1, 0, 0, 0, citytime.Location())
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	assert.Len(t, *sent, 2)
+}
+
+func Test_EmailDigest_DaysAreCalgaryDays(t *testing.T) {
+	now := time.Date(2026, 3, 28, 9, 0, 0, 0, citytime.Location())
+	digest, sent := newTestDigest(t, "daily", &now)
+	require.NoError(t, digest.Notify(context.Background(), testActions()))
+
+	// Already the next day in UTC, still the same day in Calgary
+	now = time.Date(2026, 3, 29, 3, 0, 0, 0, time.UTC)
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	assert.Empty(t, *sent)
+
+	now = time.Date(2026, 3, 29, 7, 0, 0, 0, time.UTC)
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	assert.Len(t, *sent, 2)
+}
+
+func Test_EmailDigest_NewItemIsRenderedNotEscaped(t *testing.T) {
+	now := time.Date(2026, 3, 28, 9, 0, 0, 0, citytime.Location())
+	digest, sent := newTestDigest(t, "daily", &now)
+	require.NoError(t, digest.Notify(context.Background(), []fileaction.FileAction{permitAction("CREATE")}))
+	now = now.AddDate(0, 0, 1)
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	require.Len(t, *sent, 2)
+
+	// The HTML part carries the feed description as HTML rather than escaped Markdown
+	message := (*sent)[0]
+	assert.Contains(t, message.HTML, "<strong>Applicant:</strong> SMITH &amp; SONS (2021) LTD.</p>")
+	assert.NotContains(t, message.HTML, "**")
+	assert.NotContains(t, message.HTML, "&amp;amp;")
+	assert.NotContains(t, message.HTML, "## ")
+
+	// The plain text part is text, without Markdown syntax or escapes
+	assert.Contains(t, message.Text, "  Applicant: SMITH & SONS (2021) LTD.\n")
+	assert.Contains(t, message.Text, "  Description: NEW: SIGN - CLASS B (FASCIA SIGN) *LIT* <PYLON>\n")
+	assert.Contains(t, message.Text, "  Development Map (https://developmentmap.calgary.ca/?find=DP2026-01738)\n")
+	assert.NotContains(t, message.Text, "**")
+	assert.NotContains(t, message.Text, `\`)
+	assert.NotContains(t, message.Text, "&amp;")
+}
+
+func Test_PlainText(t *testing.T) {
+	markdown := "## About\n\n**Applicant:** SMITH &amp; SONS \\(2021\\) \\*LTD\\* \\&amp;\n- [Row \\[1\\]](https://example.com/?a=1)\n#A 3214 28 ST SW"
+	assert.Equal(t, "About\n\nApplicant: SMITH & SONS (2021) *LTD* &amp;\n- Row [1] (https://example.com/?a=1)\n#A 3214 28 ST SW", plainText(markdown))
+}
+
+func Test_EmailDigest_Weekly(t *testing.T) {
+	// Monday
+	now := time.Date(2026, 3, 23, 9, 0, 0, 0, citytime.Location())
+	digest, sent := newTestDigest(t, "weekly", &now)
+
+	require.NoError(t, digest.Notify(context.Background(), testActions()[:1]))
+	now = time.Date(2026, 3, 29, 23, 0, 0, 0, citytime.Location())
+	require.NoError(t, digest.Notify(context.Background(), testActions()[1:]))
+	assert.Empty(t, *sent)
+
+	now = time.Date(2026, 3, 30, 1, 0, 0, 0, citytime.Location())
+	require.NoError(t, digest.Notify(context.Background(), nil))
+
+	require.Len(t, *sent, 2)
+	assert.Equal(t, "Weekly Neighborhood development digest: 1 new, 1 closed", (*sent)[0].Subject)
+}
+
+func Test_EmailDigest_FailedSendIsRetried(t *testing.T) {
+	now := time.Date(2026, 3, 28, 9, 0, 0, 0, citytime.Location())
+	digest, sent := newTestDigest(t, "daily", &now)
+	require.NoError(t, digest.Notify(context.Background(), testActions()))
+
+	send := digest.send
+	digest.send = func(message email.Message) error {
+		if message.To == "two@example.com" {
+			return errors.New("connection refused")
+		}
+		return send(message)
+	}
+	now = now.AddDate(0, 0, 1)
+	assert.ErrorContains(t, digest.Notify(context.Background(), nil), "failed to send email digest to 1 of 2 subscribers")
+	require.Len(t, *sent, 1)
+	assert.Equal(t, "one@example.com", (*sent)[0].To)
+
+	// Only the subscriber that missed it gets the digest again, and the new day's actions wait for their own digest
+	digest.send = send
+	now = now.Add(time.Hour)
+	require.NoError(t, digest.Notify(context.Background(), testActions()[:1]))
+	require.Len(t, *sent, 2)
+	assert.Equal(t, "two@example.com", (*sent)[1].To)
+	assert.Equal(t, (*sent)[0].Text, (*sent)[1].Text)
+
+	pending, err := digest.loadPending()
+	require.NoError(t, err)
+	assert.Empty(t, pending.Unsent)
+	assert.Len(t, pending.Entries, 1)
+
+	// Nothing is resent once everyone has it
+	now = now.Add(time.Hour)
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	assert.Len(t, *sent, 2)
+}
+
+func Test_EmailDigest_UnsentSkipsUnsubscribed(t *testing.T) {
+	now := time.Date(2026, 3, 28, 9, 0, 0, 0, citytime.Location())
+	digest, sent := newTestDigest(t, "daily", &now)
+	require.NoError(t, digest.Notify(context.Background(), testActions()))
+
+	send := digest.send
+	digest.send = func(message email.Message) error { return errors.New("connection refused") }
+	now = now.AddDate(0, 0, 1)
+	assert.Error(t, digest.Notify(context.Background(), nil))
+
+	digest.send = send
+	digest.settings.Subscribers = []string{"one@example.com"}
+	now = now.Add(time.Hour)
+	require.NoError(t, digest.Notify(context.Background(), nil))
+	require.Len(t, *sent, 1)
+	assert.Equal(t, "one@example.com", (*sent)[0].To)
+}
+
+func Test_NotifyAll_ContinuesAfterFailure(t *testing.T) {
+	var calls []string
+	notifiers := []Notifier{
+		notifierFunc(func(actions []fileaction.FileAction) error {
+			calls = append(calls, "first")
+			return errors.New("broken")
+		}),
+		notifierFunc(func(actions []fileaction.FileAction) error {
+			calls = append(calls, "second")
+			return nil
+		}),
+	}
+
+	NotifyAll(context.Background(), notifiers, testActions(), nil)
+
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func Test_NotifyAll_RoutedActions(t *testing.T) {
+	received := map[string]int{}
+	record := func(name string) Notifier {
+		return namedNotifier{name: name, notify: func(actions []fileaction.FileAction) error {
+			received[name] = len(actions)
+			return nil
+		}}
+	}
+	notifiers := []Notifier{record("email"), record("board-slack")}
+
+	NotifyAll(context.Background(), notifiers, testActions(), map[string][]fileaction.FileAction{"board-slack": testActions()[:1]})
+
+	assert.Equal(t, map[string]int{"email": 2, "board-slack": 1}, received)
+	assert.Equal(t, []string{"email", "board-slack"}, Names(notifiers))
+}
+
+// notifierFunc - adapts a function to the Notifier interface
+type notifierFunc func(actions []fileaction.FileAction) error
+
+func (f notifierFunc) Name() string {
+	return "func"
+}
+
+func (f notifierFunc) Notify(ctx context.Context, actions []fileaction.FileAction) error {
+	return f(actions)
+}
+
+// namedNotifier - a notifier with a name that records what it is sent
+type namedNotifier struct {
+	name   string
+	notify func(actions []fileaction.FileAction) error
+}
+
+func (n namedNotifier) Name() string {
+	return n.name
+}
+
+func (n namedNotifier) Notify(ctx context.Context, actions []fileaction.FileAction) error {
+	return n.notify(actions)
+}
@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+)
+
+// Notifier - tells people about the actions taken in a run
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, actions []fileaction.FileAction) error
+}
+
+// FromConfig - Builds the notifiers enabled in the config
+func FromConfig() []Notifier {
+	var notifiers []Notifier
+	if config.Config.Notifications.Email.Enabled {
+		notifiers = append(notifiers, NewEmailDigest(config.Config.Notifications.Email))
+	}
+	if config.Config.Notifications.Mastodon.Enabled {
+		notifiers = append(notifiers, NewMastodonPublisher(config.Config.Notifications.Mastodon))
+	}
+	if config.Config.Notifications.Bluesky.Enabled {
+		notifiers = append(notifiers, NewBlueskyPublisher(config.Config.Notifications.Bluesky))
+	}
+	for _, settings := range config.Config.Notifications.Webhooks {
+		notifiers = append(notifiers, NewWebhookNotifier(settings))
+	}
+
+	return notifiers
+}
+
+// NotifyAll - Passes the run's actions to every notifier, a failing notifier does not stop the others
+// Notifiers with an entry in routed, such as those named by alert rules, only get the actions routed to them
+func NotifyAll(ctx context.Context, notifiers []Notifier, actions []fileaction.FileAction, routed map[string][]fileaction.FileAction) {
+	for _, notifier := range notifiers {
+		notifierActions := actions
+		if routedActions, found := routed[notifier.Name()]; found {
+			notifierActions = routedActions
+		}
+		if err := notifier.Notify(ctx, notifierActions); err != nil {
+			fmt.Printf("Failed to send %s notifications: %v\n", notifier.Name(), err)
+		}
+	}
+}
+
+// Names - The names notifiers are referred to by in alert rules
+func Names(notifiers []Notifier) []string {
+	var names []string
+	for _, notifier := range notifiers {
+		names = append(names, notifier.Name())
+	}
+	return names
+}
@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/bluesky"
+	"github.com/jeffadavidson/development-bot/interactions/mastodon"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/templates"
+)
+
+// blueskyMaxCharacters - the longest post Bluesky accepts
+const blueskyMaxCharacters = 300
+
+// SocialPublisher - posts a short status for each new or decided item, up to a limit per run
+type SocialPublisher struct {
+	name          string
+	maxPerRun     int
+	maxCharacters int
+	// linkLength - how many characters a link counts towards the limit, zero when it counts its full length
+	linkLength int
+	post       func(text string, link string, action fileaction.FileAction) error
+}
+
+// NewMastodonPublisher - Creates a publisher that posts statuses to a Mastodon account
+func NewMastodonPublisher(settings config.Mastodon) *SocialPublisher {
+	account := mastodon.Account{InstanceURL: settings.InstanceURL, AccessToken: settings.AccessToken}
+	return &SocialPublisher{
+		name:          "Mastodon",
+		maxPerRun:     settings.MaxPerRun,
+		maxCharacters: settings.MaxCharacters,
+		linkLength:    mastodon.URLLength,
+		post: func(text string, link string, action fileaction.FileAction) error {
+			return mastodon.PostStatus(account, text, settings.Visibility, action.PermitNum+"-"+action.Action+"-"+action.Record.Status)
+		},
+	}
+}
+
+// NewBlueskyPublisher - Creates a publisher that posts to a Bluesky account, signing in when the first post is made
+func NewBlueskyPublisher(settings config.Bluesky) *SocialPublisher {
+	account := bluesky.Account{ServiceURL: settings.ServiceURL, Handle: settings.Handle, AppPassword: settings.AppPassword}
+	var session *bluesky.Session
+	return &SocialPublisher{
+		name:          "Bluesky",
+		maxPerRun:     settings.MaxPerRun,
+		maxCharacters: blueskyMaxCharacters,
+		post: func(text string, link string, action fileaction.FileAction) error {
+			if session == nil {
+				signedIn, err := bluesky.SignIn(account)
+				if err != nil {
+					return err
+				}
+				session = signedIn
+			}
+			return session.Post(text, link, time.Now())
+		},
+	}
+}
+
+// Name - Alert rules refer to publishers by their platform in lower case, such as mastodon
+func (p *SocialPublisher) Name() string {
+	return strings.ToLower(p.name)
+}
+
+// Notify - Posts each new or closed item, skipping updates and anything beyond the per run limit
+func (p *SocialPublisher) Notify(ctx context.Context, actions []fileaction.FileAction) error {
+	var postable []fileaction.FileAction
+	for _, action := range actions {
+		if action.Action == "CREATE" || action.Action == "CLOSE" {
+			postable = append(postable, action)
+		}
+	}
+	if len(postable) > p.maxPerRun {
+		fmt.Printf("%s reached its limit of %d posts per run, skipped %d items\n", p.name, p.maxPerRun, len(postable)-p.maxPerRun)
+		postable = postable[:p.maxPerRun]
+	}
+
+	var failures []string
+	for _, action := range postable {
+		text := composeStatus(action, p.maxCharacters, p.linkLength)
+		if err := p.post(text, action.Link, action); err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", action.PermitNum, err.Error()))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s posting failed for %d items: %s", p.name, len(failures), strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// composeStatus - a one line description of the item followed by its link, shortened to fit the character limit
+func composeStatus(action fileaction.FileAction, maxCharacters int, linkLength int) string {
+	record := action.Record
+	text := templates.SocialStatus(templates.Status{
+		Action:    action.Action,
+		Dataset:   record.Dataset,
+		PermitNum: action.PermitNum,
+		Address:   record.Address,
+		Summary:   record.Summary,
+		Decision:  record.Decision,
+		Status:    record.Status,
+	})
+
+	if action.Link == "" {
+		return truncate(text, maxCharacters)
+	}
+
+	if linkLength == 0 {
+		linkLength = len([]rune(action.Link))
+	}
+	available := maxCharacters - linkLength - 2
+	if available < 1 {
+		return action.Link
+	}
+
+	return truncate(text, available) + "\n\n" + action.Link
+}
@@ -0,0 +1,113 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/interactions/mastodon"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ComposeStatus_NewRezoning(t *testing.T) {
+	status := composeStatus(describedAction(), 500, mastodon.URLLength)
+
+	assert.Equal(t, "New rezoning LOC2026-0023 at 3214 29 ST SW: DC → H-GO\n\nhttps://developmentmap.calgary.ca/?find=LOC2026-0023", status)
+}
+
+func Test_ComposeStatus_Decision(t *testing.T) {
+	action := fileaction.FileAction{
+		PermitNum: "DP2026-01776",
+		Action:    "CLOSE",
+		Link:      "https://developmentmap.calgary.ca/?find=DP2026-01776",
+		Record: activity.Record{
+			Dataset:  activity.DevelopmentPermit,
+			Status:   "Released",
+			Decision: "Approved",
+			Address:  "2807 27 ST SW",
+		},
+	}
+
+	status := composeStatus(action, 500, mastodon.URLLength)
+
+	assert.Equal(t, "Decision on development permit DP2026-01776 at 2807 27 ST SW: Approved\n\nhttps://developmentmap.calgary.ca/?find=DP2026-01776", status)
+}
+
+func Test_ComposeStatus_HonorsCharacterLimit(t *testing.T) {
+	action := describedAction()
+	action.Record.Summary = strings.Repeat("Contextual multi-residential building ", 20)
+
+	mastodonStatus := composeStatus(action, 500, mastodon.URLLength)
+	blueskyStatus := composeStatus(action, blueskyMaxCharacters, 0)
+
+	// Mastodon counts the link as 23 characters however long it is
+	assert.Equal(t, 500-mastodon.URLLength+len([]rune(action.Link)), len([]rune(mastodonStatus)))
+	assert.Len(t, []rune(blueskyStatus), blueskyMaxCharacters)
+	assert.True(t, strings.HasSuffix(blueskyStatus, "…\n\n"+action.Link))
+}
+
+func Test_MastodonPublisher_PostsNewAndDecidedItems(t *testing.T) {
+	var statuses []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/statuses", r.URL.Path)
+		assert.Equal(t, "Bearer token-123", r.Header.Get("Authorization"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		statuses = append(statuses, body["status"])
+	}))
+	defer ts.Close()
+
+	publisher := NewMastodonPublisher(config.Mastodon{InstanceURL: ts.URL, AccessToken: "token-123", MaxPerRun: 2, MaxCharacters: 500})
+	update := describedAction()
+	update.Action = "UPDATE"
+	closed := describedAction()
+	closed.Action = "CLOSE"
+
+	require.NoError(t, publisher.Notify(context.Background(), []fileaction.FileAction{update, describedAction(), closed, describedAction()}))
+
+	require.Len(t, statuses, 2)
+	assert.True(t, strings.HasPrefix(statuses[0], "New rezoning LOC2026-0023"))
+	assert.True(t, strings.HasPrefix(statuses[1], "Decision on rezoning LOC2026-0023 at 3214 29 ST SW: Under Review"))
+}
+
+func Test_BlueskyPublisher_SignsInOnce(t *testing.T) {
+	sessions := 0
+	var posts []map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /xrpc/com.atproto.server.createSession", func(w http.ResponseWriter, r *http.Request) {
+		sessions++
+		w.Write([]byte(`{"accessJwt": "jwt-123", "did": "did:plc:abc123"}`))
+	})
+	mux.HandleFunc("POST /xrpc/com.atproto.repo.createRecord", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		posts = append(posts, body)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	publisher := NewBlueskyPublisher(config.Bluesky{ServiceURL: ts.URL, Handle: "killarney.example.com", AppPassword: "app-password", MaxPerRun: 5})
+	require.NoError(t, publisher.Notify(context.Background(), []fileaction.FileAction{describedAction(), describedAction()}))
+
+	assert.Equal(t, 1, sessions)
+	assert.Len(t, posts, 2)
+}
+
+func Test_SocialPublisher_ReportsFailures(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer ts.Close()
+
+	publisher := NewMastodonPublisher(config.Mastodon{InstanceURL: ts.URL, AccessToken: "token-123", MaxPerRun: 5, MaxCharacters: 500})
+	err := publisher.Notify(context.Background(), []fileaction.FileAction{describedAction()})
+
+	assert.ErrorContains(t, err, "Mastodon posting failed for 1 items")
+}
@@ -0,0 +1,63 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/interactions/webhook"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/toolbox"
+)
+
+// WebhookNotifier - POSTs each action to a webhook, up to a limit per run
+type WebhookNotifier struct {
+	settings config.Webhook
+	format   func(action fileaction.FileAction) ([]byte, error)
+	post     func(ctx context.Context, endpoint webhook.Endpoint, payload []byte) error
+}
+
+// NewWebhookNotifier - Creates a notifier for a webhook, using the formatter named in its settings
+func NewWebhookNotifier(settings config.Webhook) *WebhookNotifier {
+	return &WebhookNotifier{
+		settings: settings,
+		format:   payloadFormatters[settings.Format],
+		post:     webhook.Post,
+	}
+}
+
+// Name - The webhook's name from the config
+func (n *WebhookNotifier) Name() string {
+	return n.settings.Name
+}
+
+// Notify - Sends each action as its own request, skipping any beyond the per run limit
+func (n *WebhookNotifier) Notify(ctx context.Context, actions []fileaction.FileAction) error {
+	endpoint := webhook.Endpoint{URL: n.settings.URL, Secret: n.settings.Secret, Retries: toolbox.IntValue(n.settings.Retries)}
+
+	var failures []string
+	sent := 0
+	for _, action := range actions {
+		if sent >= n.settings.MaxPerRun {
+			fmt.Printf("Webhook %s reached its limit of %d per run, skipped %d actions\n", n.settings.Name, n.settings.MaxPerRun, len(actions)-sent)
+			break
+		}
+		sent++
+
+		payload, err := n.format(action)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", action.PermitNum, err.Error()))
+			continue
+		}
+		if err := n.post(ctx, endpoint, payload); err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", action.PermitNum, err.Error()))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("webhook %s failed for %d actions: %s", n.settings.Name, len(failures), strings.Join(failures, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/interactions/webhook"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/developmentpermit"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func describedAction() fileaction.FileAction {
+	return fileaction.FileAction{
+		PermitNum: "LOC2026-0023",
+		Action:    "CREATE",
+		Message:   "Rezoning from DC to H-GO <see map>",
+		Title:     "🏛️ Rezoning Application: LOC2026-0023 - 3214 29 ST SW",
+		Link:      "https://developmentmap.calgary.ca/?find=LOC2026-0023",
+		Record: activity.Record{
+			Dataset:     activity.RezoningApplication,
+			PermitNum:   "LOC2026-0023",
+			Status:      "Under Review",
+			Category:    "Land Use",
+			Summary:     "DC → H-GO",
+			Address:     "3214 29 ST SW",
+			Ward:        "8",
+			Location:    &geo.Point{Latitude: 51.0302, Longitude: -114.1295},
+			LastUpdated: time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+// newTestWebhook - a webhook notifier that records payloads instead of posting them
+func newTestWebhook(format string, maxPerRun int, postErr error) (*WebhookNotifier, *[][]byte) {
+	var posted [][]byte
+	notifier := NewWebhookNotifier(config.Webhook{Name: "test", URL: "https://example.com/hook", Format: format, MaxPerRun: maxPerRun})
+	notifier.post = func(ctx context.Context, endpoint webhook.Endpoint, payload []byte) error {
+		posted = append(posted, payload)
+		return postErr
+	}
+	return notifier, &posted
+}
+
+func Test_WebhookNotifier_JSON(t *testing.T) {
+	notifier, posted := newTestWebhook("json", 20, nil)
+
+	require.NoError(t, notifier.Notify(context.Background(), []fileaction.FileAction{describedAction()}))
+
+	require.Len(t, *posted, 1)
+	assert.JSONEq(t, `{
+		"action": "CREATE",
+		"permitnum": "LOC2026-0023",
+		"dataset": "rezoning-application",
+		"title": "🏛️ Rezoning Application: LOC2026-0023 - 3214 29 ST SW",
+		"link": "https://developmentmap.calgary.ca/?find=LOC2026-0023",
+		"message": "Rezoning from DC to H-GO <see map>",
+		"status": "Under Review",
+		"category": "Land Use",
+		"summary": "DC → H-GO",
+		"address": "3214 29 ST SW",
+		"ward": "8",
+		"latitude": 51.0302,
+		"longitude": -114.1295,
+		"updated": "2026-03-28T12:00:00Z"
+	}`, string((*posted)[0]))
+}
+
+// permitAction - the action for a real development permit, described the way the feed describes it
+func permitAction(action string) fileaction.FileAction {
+	text := func(value string) *string { return &value }
+	dp := developmentpermit.DevelopmentPermit{
+		Point:         developmentpermit.Point{Coordinates: []float64{-114.1285, 51.0262}},
+		PermitNum:     "DP2026-01738",
+		Address:       text("#A 3214 28 ST SW"),
+		Applicant:     text("SMITH & SONS (2021) LTD."),
+		Category:      text("Signs - Permitted Use"),
+		Description:   text("NEW: SIGN - CLASS B (FASCIA SIGN) *LIT* <PYLON>"),
+		StatusCurrent: "Under Review",
+		AppliedDate:   text("2026-03-26T00:00:00.000"),
+		Ward:          text("8"),
+		RSSGuid:       "1a2b3c",
+	}
+	fileAction := fileaction.FileAction{PermitNum: dp.PermitNum, Action: action, Message: dp.CreateInformationMessage()}
+	dp.DescribeAction(&fileAction)
+	return fileAction
+}
+
+func Test_WebhookNotifier_Slack(t *testing.T) {
+	notifier, posted := newTestWebhook("slack", 20, nil)
+	action := permitAction("UPDATE")
+	action.PreviousStatus = "New"
+
+	require.NoError(t, notifier.Notify(context.Background(), []fileaction.FileAction{action}))
+
+	var payload slackPayload
+	require.NoError(t, json.Unmarshal((*posted)[0], &payload))
+	assert.Equal(t, "Updated: "+action.Title, payload.Text)
+	require.Len(t, payload.Blocks, 2)
+	assert.Equal(t, "*Updated:* <https://developmentmap.calgary.ca/?find=DP2026-01738|"+action.Title+">\nNEW: SIGN - CLASS B (FASCIA SIGN) *LIT* &lt;PYLON&gt;", payload.Blocks[0].Text.Text)
+	assert.Contains(t, payload.Blocks[1].Fields, slackText{Type: "mrkdwn", Text: "*Status*\nNew → Under Review"})
+	assert.Contains(t, payload.Blocks[1].Fields, slackText{Type: "mrkdwn", Text: "*Applicant*\nSMITH &amp; SONS (2021) LTD."})
+
+	// None of the Markdown message reaches Slack, which would show its headings and escapes as written
+	assert.NotContains(t, string((*posted)[0]), "##")
+	assert.NotContains(t, string((*posted)[0]), `\\(`)
+	assert.NotContains(t, string((*posted)[0]), "&amp;amp;")
+}
+
+func Test_WebhookNotifier_Discord(t *testing.T) {
+	notifier, posted := newTestWebhook("discord", 20, nil)
+	action := permitAction("CREATE")
+
+	require.NoError(t, notifier.Notify(context.Background(), []fileaction.FileAction{action}))
+
+	var payload discordPayload
+	require.NoError(t, json.Unmarshal((*posted)[0], &payload))
+	require.Len(t, payload.Embeds, 1)
+	embed := payload.Embeds[0]
+	assert.Equal(t, "New: "+action.Title, embed.Title)
+	assert.Equal(t, "https://developmentmap.calgary.ca/?find=DP2026-01738", embed.URL)
+	assert.Equal(t, `NEW: SIGN - CLASS B (FASCIA SIGN) \*LIT\* <PYLON\>`, embed.Description)
+	assert.Equal(t, 0x2ECC71, embed.Color)
+	assert.Contains(t, embed.Fields, discordField{Name: "Applicant", Value: "SMITH & SONS (2021) LTD."})
+	assert.Contains(t, embed.Fields, discordField{Name: "Ward", Value: "8", Inline: true})
+	assert.NotContains(t, string((*posted)[0]), "##")
+	assert.NotContains(t, string((*posted)[0]), "&amp;")
+}
+
+func Test_WebhookNotifier_RateLimit(t *testing.T) {
+	notifier, posted := newTestWebhook("json", 2, nil)
+	actions := []fileaction.FileAction{describedAction(), describedAction(), describedAction()}
+
+	require.NoError(t, notifier.Notify(context.Background(), actions))
+
+	assert.Len(t, *posted, 2)
+}
+
+func Test_WebhookNotifier_ReportsFailures(t *testing.T) {
+	notifier, posted := newTestWebhook("json", 20, errors.New("Http Status 500"))
+
+	err := notifier.Notify(context.Background(), []fileaction.FileAction{describedAction(), {PermitNum: "DP2026-01776", Action: "UPDATE"}})
+
+	assert.Len(t, *posted, 2)
+	assert.ErrorContains(t, err, "webhook test failed for 2 actions")
+}
+
+func Test_Truncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "abcd…", truncate("abcdefghij", 5))
+	assert.Equal(t, "🏗️…", truncate("🏗️🏗️🏗️", 3))
+}
@@ -0,0 +1,246 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/objects/community"
+	"github.com/jeffadavidson/development-bot/objects/fileaction"
+)
+
+// payloadFormatters - builds the request body for each webhook format
+var payloadFormatters = map[string]func(action fileaction.FileAction) ([]byte, error){
+	"slack":   formatSlack,
+	"discord": formatDiscord,
+	"json":    formatJSON,
+}
+
+// actionLabels - how each action type is described to people
+var actionLabels = map[string]string{
+	"CREATE": "New",
+	"UPDATE": "Updated",
+	"CLOSE":  "Closed",
+}
+
+// discordColors - embed accent colours for each action type
+var discordColors = map[string]int{
+	"CREATE": 0x2ECC71,
+	"UPDATE": 0x3498DB,
+	"CLOSE":  0x95A5A6,
+}
+
+// discordEscaper - the characters Discord gives meaning to in embed descriptions and field values
+var discordEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`, "[", `\[`, "]", `\]`)
+
+// jsonPayload - the raw JSON webhook body
+type jsonPayload struct {
+	Action    string   `json:"action"`
+	PermitNum string   `json:"permitnum"`
+	Dataset   string   `json:"dataset,omitempty"`
+	Title     string   `json:"title"`
+	Link      string   `json:"link,omitempty"`
+	Message   string   `json:"message"`
+	Status    string   `json:"status,omitempty"`
+	Category  string   `json:"category,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+	Address   string   `json:"address,omitempty"`
+	Ward      string   `json:"ward,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	// Position - the community and the distance and direction from the reference or subscriber's point
+	Position *community.Position `json:"position,omitempty"`
+	Updated  *time.Time          `json:"updated,omitempty"`
+}
+
+// slackPayload - a Slack incoming webhook message using Block Kit
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// discordPayload - a Discord webhook message with a single embed
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func formatJSON(action fileaction.FileAction) ([]byte, error) {
+	record := action.Record
+	payload := jsonPayload{
+		Action:    action.Action,
+		PermitNum: action.PermitNum,
+		Dataset:   record.Dataset,
+		Title:     actionTitle(action),
+		Link:      action.Link,
+		Message:   action.Message,
+		Status:    record.Status,
+		Category:  record.Category,
+		Summary:   record.Summary,
+		Address:   record.Address,
+		Ward:      record.Ward,
+		Position:  record.Position,
+	}
+	if record.Location != nil {
+		payload.Latitude = &record.Location.Latitude
+		payload.Longitude = &record.Location.Longitude
+	}
+	if !record.LastUpdated.IsZero() {
+		payload.Updated = &record.LastUpdated
+	}
+
+	return json.Marshal(payload)
+}
+
+func formatSlack(action fileaction.FileAction) ([]byte, error) {
+	title := escapeSlack(actionTitle(action))
+	if action.Link != "" {
+		title = fmt.Sprintf("<%s|%s>", action.Link, title)
+	}
+
+	// Built from the record rather than the Markdown message, as mrkdwn renders none of its headings, bold or links
+	text := fmt.Sprintf("*%s:* %s", actionLabel(action.Action), title)
+	if summary := strings.TrimSpace(action.Record.Summary); summary != "" {
+		text += "\n" + truncate(escapeSlack(summary), 2500)
+	}
+
+	payload := slackPayload{
+		Text: fmt.Sprintf("%s: %s", actionLabel(action.Action), actionTitle(action)),
+		Blocks: []slackBlock{
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}},
+		},
+	}
+	var fields []slackText
+	for _, fact := range recordFacts(action) {
+		// Slack allows up to 10 fields in a section
+		if len(fields) == 10 {
+			break
+		}
+		fields = append(fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", fact.label, truncate(escapeSlack(fact.value), 1900))})
+	}
+	if len(fields) > 0 {
+		payload.Blocks = append(payload.Blocks, slackBlock{Type: "section", Fields: fields})
+	}
+
+	return json.Marshal(payload)
+}
+
+func formatDiscord(action fileaction.FileAction) ([]byte, error) {
+	record := action.Record
+	embed := discordEmbed{
+		Title:       truncate(fmt.Sprintf("%s: %s", actionLabel(action.Action), actionTitle(action)), 256),
+		URL:         action.Link,
+		Description: truncate(escapeDiscord(strings.TrimSpace(record.Summary)), 4096),
+		Color:       discordColors[action.Action],
+	}
+	for _, fact := range recordFacts(action) {
+		// Discord allows up to 25 fields in an embed
+		if len(embed.Fields) == 25 {
+			break
+		}
+		embed.Fields = append(embed.Fields, discordField{Name: fact.label, Value: truncate(escapeDiscord(fact.value), 1024), Inline: fact.inline})
+	}
+	if !record.LastUpdated.IsZero() {
+		embed.Timestamp = record.LastUpdated.UTC().Format(time.RFC3339)
+	}
+
+	return json.Marshal(discordPayload{Embeds: []discordEmbed{embed}})
+}
+
+// fact - one labelled value about a record, a field in Slack and Discord messages
+type fact struct {
+	label string
+	value string
+	// inline - short enough to sit beside other fields in Discord
+	inline bool
+}
+
+// recordFacts - the facts chat webhooks show about an action's record, leaving out any that are empty
+func recordFacts(action fileaction.FileAction) []fact {
+	record := action.Record
+	status := record.Status
+	if action.PreviousStatus != "" && action.PreviousStatus != record.Status {
+		status = action.PreviousStatus + " → " + record.Status
+	}
+	location := ""
+	if position := community.TemplatePosition("", record.Position); position != nil {
+		location = position.Summary
+	}
+
+	var facts []fact
+	for _, candidate := range []fact{
+		{label: "Status", value: status, inline: true},
+		{label: "Decision", value: record.Decision, inline: true},
+		{label: "Category", value: record.Category, inline: true},
+		{label: "Ward", value: record.Ward, inline: true},
+		{label: "Address", value: record.Address},
+		{label: "Applicant", value: record.Applicant},
+		{label: "Location", value: location},
+	} {
+		if strings.TrimSpace(candidate.value) != "" {
+			facts = append(facts, candidate)
+		}
+	}
+	return facts
+}
+
+// actionLabel - describes an action type, falling back to the type itself
+func actionLabel(action string) string {
+	if label, found := actionLabels[action]; found {
+		return label
+	}
+	return action
+}
+
+// actionTitle - the feed item title of the action, or its permit number when it has none
+func actionTitle(action fileaction.FileAction) string {
+	if action.Title != "" {
+		return action.Title
+	}
+	return action.PermitNum
+}
+
+// escapeSlack - escapes the characters Slack treats as markup in mrkdwn text
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// escapeDiscord - backslash escapes the characters Discord treats as Markdown, so upstream text is shown as written
+func escapeDiscord(text string) string {
+	return discordEscaper.Replace(text)
+}
+
+// truncate - shortens text to a maximum number of characters, marking that it was cut
+func truncate(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return string(runes[:maxLength-1]) + "…"
+}
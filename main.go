@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/jeffadavidson/development-bot/logic/examinedata"
 	"github.com/jeffadavidson/development-bot/utilities/config"
@@ -9,13 +15,40 @@ import (
 )
 
 func main() {
+	serve := flag.Bool("serve", false, "Run as a server that refreshes development activity on an interval and serves the feed over HTTP")
+	record := flag.String("record", "", "Save the open data responses to this fixtures directory")
+	replay := flag.String("replay", "", "Answer upstream requests from this fixtures directory instead of the network")
+	flag.Parse()
+
 	err := ManualInits()
 	if err != nil {
 		exit.ExitError(err)
 	}
 
+	err = examinedata.UseFixtures(*record, *replay)
+	if err != nil {
+		exit.ExitError(err)
+	}
+
+	// Serve feeds until the server stops
+	if *serve {
+		err = examinedata.ServeDevelopmentActivity()
+		if err != nil {
+			exit.ExitError(err)
+		}
+		exit.ExitSuccess()
+	}
+
+	// Stop fetching and retrying when interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	//Process all development activity into combined RSS feed
-	err = examinedata.ProcessAllDevelopmentActivity()
+	_, err = examinedata.ProcessAllDevelopmentActivity(ctx)
+	var partialFailure *examinedata.PartialFailureError
+	if errors.As(err, &partialFailure) {
+		exit.ExitPartialFailure(err)
+	}
 	if err != nil {
 		exit.ExitError(err)
 	}
@@ -0,0 +1,57 @@
+package activity
+
+import (
+	"time"
+
+	"github.com/jeffadavidson/development-bot/objects/community"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+)
+
+// Dataset names, shared with the GUID namespaces used for each type of record
+const (
+	DevelopmentPermit   = "development-permit"
+	RezoningApplication = "rezoning-application"
+)
+
+// Record - a dataset independent view of a development permit or rezoning application, used for filtering and notifications
+type Record struct {
+	Dataset     string
+	PermitNum   string
+	Status      string
+	Category    string
+	Summary     string
+	Description string
+	Applicant   string
+	// ProposedLandUse - the district a rezoning asks for, empty for development permits
+	ProposedLandUse string
+	// CurrentDistricts - the land use districts today, parsed and explained from the glossary
+	CurrentDistricts []landuse.District
+	// ProposedDistricts - the districts a rezoning asks for, empty for development permits
+	ProposedDistricts []landuse.District
+	Decision          string
+	Address           string
+	// Parcels - the parcel addresses the record covers, from its address and location addresses without suites
+	Parcels []string
+	// CommunityCode - the community the record is in, from the dataset when it has one and the built-in boundaries otherwise
+	CommunityCode string
+	Ward          string
+	Location      *geo.Point
+	// Locations - every point the record covers, a rezoning can cover several parcels
+	Locations []geo.Point
+	// Position - the community the record is in and where it is from the reference point, nil without a location
+	Position    *community.Position
+	AppliedDate time.Time
+	// LastUpdated - the most recent date or status change, zero when the record has none
+	LastUpdated time.Time
+}
+
+// IncreasesAny - Checks if a rezoning raises any of the land use limits, such as max-height, development permits never do
+func (r Record) IncreasesAny(aspects []string) bool {
+	for _, aspect := range aspects {
+		if landuse.Increases(aspect, r.CurrentDistricts, r.ProposedDistricts) {
+			return true
+		}
+	}
+	return false
+}
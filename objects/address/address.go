@@ -0,0 +1,143 @@
+package address
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// quadrants - Calgary is divided into quadrants that end every street address
+var quadrants = map[string]bool{"NW": true, "NE": true, "SW": true, "SE": true}
+
+// streetTypes - the abbreviations the City of Calgary uses for street types, such as ST and AV
+var streetTypes = map[string]bool{
+	"AL": true, "AV": true, "BA": true, "BV": true, "CI": true, "CL": true, "CM": true, "CO": true,
+	"CR": true, "CT": true, "CV": true, "DR": true, "GA": true, "GD": true, "GR": true, "GT": true,
+	"GV": true, "HE": true, "HI": true, "HL": true, "HT": true, "HY": true, "LD": true, "LI": true,
+	"LN": true, "MR": true, "MT": true, "PA": true, "PH": true, "PL": true, "PR": true, "PS": true,
+	"PT": true, "PY": true, "RD": true, "RI": true, "RO": true, "SQ": true, "ST": true, "TC": true,
+	"TE": true, "TR": true, "VI": true, "VW": true, "WK": true, "WY": true,
+}
+
+// suitePrefixes - words written before a suite instead of #, such as UNIT 120
+var suitePrefixes = map[string]bool{"UNIT": true, "SUITE": true, "APT": true}
+
+// houseNumberPattern - a house number, which can end in a letter
+var houseNumberPattern = regexp.MustCompile(`^[0-9]+[A-Z]?$`)
+
+// Address - a Calgary street address such as #120 3003 37 ST SW
+type Address struct {
+	Suite    string `json:"suite,omitempty"`
+	Number   string `json:"number"`
+	Street   string `json:"street"`
+	Type     string `json:"type,omitempty"`
+	Quadrant string `json:"quadrant,omitempty"`
+}
+
+// Parse - Parses an address written the way the City of Calgary publishes them, ignoring case, spacing and commas
+func Parse(value string) (Address, error) {
+	fields := strings.Fields(strings.ToUpper(strings.ReplaceAll(value, ",", " ")))
+	if len(fields) == 0 {
+		return Address{}, fmt.Errorf("address is empty")
+	}
+
+	var address Address
+	switch {
+	case strings.HasPrefix(fields[0], "#"):
+		address.Suite = strings.TrimPrefix(fields[0], "#")
+		fields = fields[1:]
+		// A suite can be written with a space after the #
+		if address.Suite == "" && len(fields) > 0 {
+			address.Suite = fields[0]
+			fields = fields[1:]
+		}
+	case suitePrefixes[fields[0]] && len(fields) > 1:
+		address.Suite = strings.TrimPrefix(fields[1], "#")
+		fields = fields[2:]
+	}
+
+	if len(fields) == 0 || !houseNumberPattern.MatchString(fields[0]) {
+		return Address{}, fmt.Errorf("address '%s' does not start with a house number", strings.TrimSpace(value))
+	}
+	address.Number = fields[0]
+	fields = fields[1:]
+
+	if len(fields) > 0 && quadrants[fields[len(fields)-1]] {
+		address.Quadrant = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+	// The type is only split off when a street name is left, so a street named for its type is kept whole
+	if len(fields) > 1 && streetTypes[fields[len(fields)-1]] {
+		address.Type = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 {
+		return Address{}, fmt.Errorf("address '%s' has no street", strings.TrimSpace(value))
+	}
+	address.Street = strings.Join(fields, " ")
+
+	return address, nil
+}
+
+// String - Writes the address in the City of Calgary's form, such as #120 3003 37 ST SW
+func (a Address) String() string {
+	if a.Suite == "" {
+		return a.Parcel()
+	}
+	return "#" + a.Suite + " " + a.Parcel()
+}
+
+// Parcel - Writes the address without its suite, which every unit on the parcel shares
+func (a Address) Parcel() string {
+	parts := []string{a.Number, a.Street}
+	if a.Type != "" {
+		parts = append(parts, a.Type)
+	}
+	if a.Quadrant != "" {
+		parts = append(parts, a.Quadrant)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Normalize - Rewrites an address in the City of Calgary's form, an address that cannot be parsed is only upper cased and respaced
+func Normalize(value string) string {
+	if address, err := Parse(value); err == nil {
+		return address.String()
+	}
+	return strings.Join(strings.Fields(strings.ToUpper(value)), " ")
+}
+
+// ParcelOf - Returns the parcel an address is on, the normalized address when it cannot be parsed
+func ParcelOf(value string) string {
+	if address, err := Parse(value); err == nil {
+		return address.Parcel()
+	}
+	return Normalize(value)
+}
+
+// NormalizeList - Normalizes semicolon separated addresses, such as locationaddresses, dropping blanks and duplicates
+func NormalizeList(values ...string) []string {
+	return unique(values, Normalize)
+}
+
+// Parcels - Lists the parcels semicolon separated addresses are on, without duplicates
+func Parcels(values ...string) []string {
+	return unique(values, ParcelOf)
+}
+
+// unique - splits each value on semicolons and keeps the first of each rewritten address, in order
+func unique(values []string, rewrite func(string) string) []string {
+	var addresses []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ";") {
+			address := rewrite(part)
+			if address == "" || seen[address] {
+				continue
+			}
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,71 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Address
+	}{
+		{value: "#120 3003 37 ST SW", expected: Address{Suite: "120", Number: "3003", Street: "37", Type: "ST", Quadrant: "SW"}},
+		{value: "#A 3214 28 ST SW", expected: Address{Suite: "A", Number: "3214", Street: "28", Type: "ST", Quadrant: "SW"}},
+		{value: "2035 26A ST SW", expected: Address{Number: "2035", Street: "26A", Type: "ST", Quadrant: "SW"}},
+		{value: "3435 CROWCHILD TR SW", expected: Address{Number: "3435", Street: "CROWCHILD", Type: "TR", Quadrant: "SW"}},
+		{value: "3627 kildare cr sw", expected: Address{Number: "3627", Street: "KILDARE", Type: "CR", Quadrant: "SW"}},
+		{value: " unit 5,  2812 33 ST SW ", expected: Address{Suite: "5", Number: "2812", Street: "33", Type: "ST", Quadrant: "SW"}},
+		{value: "# 2 2812 33 ST SW", expected: Address{Suite: "2", Number: "2812", Street: "33", Type: "ST", Quadrant: "SW"}},
+		{value: "100 STEPHEN AV", expected: Address{Number: "100", Street: "STEPHEN", Type: "AV"}},
+		{value: "12 TERRACE SW", expected: Address{Number: "12", Street: "TERRACE", Quadrant: "SW"}},
+	}
+
+	for _, test := range tests {
+		parsed, err := Parse(test.value)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.expected, parsed, test.value)
+	}
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":             "address is empty",
+		"#120":         "address '#120' does not start with a house number",
+		"CROWCHILD TR": "address 'CROWCHILD TR' does not start with a house number",
+		"3003 SW":      "address '3003 SW' has no street",
+	}
+
+	for value, expected := range tests {
+		_, err := Parse(value)
+		assert.EqualError(t, err, expected, value)
+	}
+}
+
+func Test_StringAndParcel(t *testing.T) {
+	parsed, err := Parse("#120  3003 37 st sw")
+	assert.NoError(t, err)
+	assert.Equal(t, "#120 3003 37 ST SW", parsed.String())
+	assert.Equal(t, "3003 37 ST SW", parsed.Parcel())
+
+	assert.Equal(t, "3003 37 ST SW", ParcelOf("#120 3003 37 ST SW"))
+	assert.Equal(t, "CROWCHILD TR", Normalize(" crowchild  tr "))
+}
+
+func Test_NormalizeList_RealValues(t *testing.T) {
+	// locationaddresses from data/development-permits.json
+	assert.Equal(t, []string{"#120 3003 37 ST SW", "3003 37 ST SW"},
+		NormalizeList("#120 3003 37 ST SW", "3003 37 ST SW;3003 37 ST SW;#120 3003 37 ST SW"))
+	assert.Equal(t, []string{"2039 26A ST SW", "2035 26A ST SW"},
+		NormalizeList("2039 26A ST SW;2039 26A ST SW;2035 26A ST SW"))
+	assert.Equal(t, []string{"2415 32 ST SW"}, NormalizeList("2415 32 ST SW", "2415 32 ST SW;2415 32 ST SW"))
+	assert.Empty(t, NormalizeList("", " ; "))
+}
+
+func Test_Parcels(t *testing.T) {
+	assert.Equal(t, []string{"3214 28 ST SW"},
+		Parcels("#A 3214 28 ST SW", "3214 28 ST SW;3214 28 ST SW;#A 3214 28 ST SW;#1 3214 28 ST SW"))
+	assert.Equal(t, []string{"2719 17 AV SW", "1909 26A ST SW"},
+		Parcels("#101 2719 17 AV SW", "2719 17 AV SW;1909 26A ST SW;#436 2719 17 AV SW;#431 2719 17 AV SW"))
+}
@@ -0,0 +1,376 @@
+package community
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/jeffadavidson/development-bot/utilities/templates"
+	"golang.org/x/exp/slices"
+)
+
+// Where an item is relative to the home community
+const (
+	PlacementInCommunity = "in-community"
+	PlacementAdjacent    = "adjacent"
+	PlacementOutside     = "outside"
+)
+
+// AdjacentMeters - items outside the home community but this close to its boundary are adjacent to it
+const AdjacentMeters = 1000
+
+// boundariesFile - community district boundaries as a GeoJSON feature collection with comm_code and name properties, copied
+// from the City's dataset with cmd/communityboundaries. Items are only placed by community once it holds boundaries
+//
+//go:embed boundaries/communities.geojson
+var boundariesFile []byte
+
+// communities, source - the parsed boundaries in file order, and where they come from
+var communities, source = mustLoadBoundaries()
+
+// settings - the home community and reference point, set from the config at startup
+var settings struct {
+	home          string
+	reference     *geo.Point
+	referenceName string
+}
+
+// Source - where a boundaries file was copied from
+type Source struct {
+	// Title - the dataset's name, such as Community District Boundaries
+	Title string `json:"title"`
+	// Dataset - the page of the dataset the boundaries were copied from
+	Dataset string `json:"dataset,omitempty"`
+	// Version - when the dataset's rows were last changed before they were copied
+	Version *time.Time `json:"version,omitempty"`
+}
+
+// Community - a community district and its boundary
+type Community struct {
+	Code     string
+	Name     string
+	polygons []geo.Polygon
+}
+
+// Position - where an item is, by community and from a reference point, worked out without an online geocoder
+type Position struct {
+	CommunityCode string `json:"community_code,omitempty"`
+	Community     string `json:"community,omitempty"`
+	// Placement - in-community, adjacent or outside, empty when no home community is configured
+	Placement string `json:"placement,omitempty"`
+	// DistanceMeters, Direction, Reference - how far and which way the item is from the reference point, empty without one
+	DistanceMeters *float64 `json:"distance_m,omitempty"`
+	Direction      string   `json:"direction,omitempty"`
+	Reference      string   `json:"reference,omitempty"`
+}
+
+// Configure - Sets the home community items are placed relative to and the reference point distances are measured from, either may be empty
+func Configure(home string, reference *geo.Point, referenceName string) error {
+	home = strings.ToUpper(strings.TrimSpace(home))
+	if home != "" {
+		if len(communities) == 0 {
+			return fmt.Errorf("no community boundaries are built in to place items relative to '%s', copy the City's with go run ./cmd/communityboundaries", home)
+		}
+		if _, found := Lookup(home); !found {
+			return fmt.Errorf("unknown community '%s', expected one of %s", home, strings.Join(Codes(), ", "))
+		}
+	}
+
+	settings.home = home
+	settings.reference = reference
+	settings.referenceName = referenceName
+	return nil
+}
+
+// Codes - Lists the community codes with a boundary
+func Codes() []string {
+	codes := make([]string, 0, len(communities))
+	for _, community := range communities {
+		codes = append(codes, community.Code)
+	}
+	return codes
+}
+
+// Lookup - Finds a community by its code, ignoring case
+func Lookup(code string) (Community, bool) {
+	for _, community := range communities {
+		if strings.EqualFold(community.Code, code) {
+			return community, true
+		}
+	}
+	return Community{}, false
+}
+
+// Locate - Finds the community a point is in
+func Locate(point geo.Point) (Community, bool) {
+	for _, community := range communities {
+		if community.Contains(point) {
+			return community, true
+		}
+	}
+	return Community{}, false
+}
+
+// Contains - Checks if a point is inside the community
+func (c Community) Contains(point geo.Point) bool {
+	for _, polygon := range c.polygons {
+		if polygon.Contains(point) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds - Returns the south west and north east corners of the box around the community
+func (c Community) Bounds() (geo.Point, geo.Point) {
+	southWest := geo.Point{Latitude: math.Inf(1), Longitude: math.Inf(1)}
+	northEast := geo.Point{Latitude: math.Inf(-1), Longitude: math.Inf(-1)}
+	for _, polygon := range c.polygons {
+		for _, ring := range polygon {
+			for _, point := range ring {
+				southWest.Latitude = math.Min(southWest.Latitude, point.Latitude)
+				southWest.Longitude = math.Min(southWest.Longitude, point.Longitude)
+				northEast.Latitude = math.Max(northEast.Latitude, point.Latitude)
+				northEast.Longitude = math.Max(northEast.Longitude, point.Longitude)
+			}
+		}
+	}
+	return southWest, northEast
+}
+
+// distanceToBoundary - how far a point is from the nearest edge of the community
+func (c Community) distanceToBoundary(point geo.Point) float64 {
+	closest := math.Inf(1)
+	for _, polygon := range c.polygons {
+		closest = math.Min(closest, polygon.DistanceToEdgeMeters(point))
+	}
+	return closest
+}
+
+// Annotate - Places a point relative to the configured home community and reference point, nil without a point
+func Annotate(point *geo.Point) *Position {
+	return annotate(point, settings.reference, settings.referenceName)
+}
+
+// AnnotateFrom - Places a point relative to the configured home community and measures it from another reference point, such as a subscriber's
+func AnnotateFrom(point *geo.Point, reference geo.Point, referenceName string) *Position {
+	return annotate(point, &reference, referenceName)
+}
+
+// annotate - places a point and measures it from the reference when there is one
+func annotate(point *geo.Point, reference *geo.Point, referenceName string) *Position {
+	if point == nil {
+		return nil
+	}
+
+	position := &Position{}
+	if found, ok := Locate(*point); ok {
+		position.CommunityCode = found.Code
+		position.Community = found.Name
+	}
+
+	if home, ok := Lookup(settings.home); ok {
+		switch {
+		case home.Contains(*point):
+			position.Placement = PlacementInCommunity
+		case home.distanceToBoundary(*point) <= AdjacentMeters:
+			position.Placement = PlacementAdjacent
+		default:
+			position.Placement = PlacementOutside
+		}
+	}
+
+	if reference != nil {
+		distance := math.Round(geo.DistanceMeters(*reference, *point))
+		position.DistanceMeters = &distance
+		position.Direction = geo.CompassDirection(geo.BearingDegrees(*reference, *point))
+		position.Reference = referenceName
+	}
+
+	return position
+}
+
+// TemplatePosition - Describes a position for the description and message templates, nil when there is nothing to show
+func TemplatePosition(language string, position *Position) *templates.Position {
+	if position == nil || (position.Community == "" && position.Placement == "" && position.DistanceMeters == nil) {
+		return nil
+	}
+
+	described := &templates.Position{Community: position.Community, Reference: position.Reference}
+	var parts []string
+	if position.Placement != "" {
+		described.Placement = i18n.T(language, position.Placement)
+		parts = append(parts, described.Placement)
+	}
+	if position.Community != "" {
+		parts = append(parts, position.Community)
+	}
+	if position.DistanceMeters != nil {
+		described.Distance = FormatDistance(*position.DistanceMeters)
+		described.Direction = i18n.T(language, "direction-"+strings.ToLower(position.Direction))
+		if described.Reference == "" {
+			described.Reference = i18n.T(language, "reference-point")
+		}
+		parts = append(parts, i18n.Tf(language, "distance-from", described.Distance, described.Direction, described.Reference))
+	}
+	described.Summary = strings.Join(parts, " · ")
+	return described
+}
+
+// FormatDistance - Writes a distance in metres under a kilometre and in tenths of a kilometre above, such as 350 m or 1.2 km
+func FormatDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
+
+// feature - one community in the boundaries file
+type feature struct {
+	Properties struct {
+		Code string `json:"comm_code"`
+		Name string `json:"name"`
+	} `json:"properties"`
+	Geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// BoundariesSource - Returns where the built-in boundaries come from
+func BoundariesSource() Source {
+	return source
+}
+
+// SetBoundaries - Replaces the built-in boundaries with a boundaries file, such as a test's
+func SetBoundaries(content []byte) error {
+	loaded, from, err := parseBoundaries(content)
+	if err != nil {
+		return err
+	}
+	communities, source = loaded, from
+	return nil
+}
+
+// ResetBoundaries - Goes back to the built-in boundaries
+func ResetBoundaries() {
+	communities, source = mustLoadBoundaries()
+}
+
+// BuildBoundaries - Makes a boundaries file from the City's community district GeoJSON, keeping each community's code, name and
+// outline and noting the dataset and version they were copied from. Every one of the codes must be in the GeoJSON
+func BuildBoundaries(geojson []byte, codes []string, from Source) ([]byte, error) {
+	var collection struct {
+		Features []struct {
+			Properties map[string]any  `json:"properties"`
+			Geometry   json.RawMessage `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(geojson, &collection); err != nil {
+		return nil, fmt.Errorf("failed to parse community boundaries: %v", err)
+	}
+
+	type builtFeature struct {
+		Type       string            `json:"type"`
+		Properties map[string]string `json:"properties"`
+		Geometry   json.RawMessage   `json:"geometry"`
+	}
+	built := struct {
+		Type     string         `json:"type"`
+		Name     string         `json:"name"`
+		Source   Source         `json:"source"`
+		Features []builtFeature `json:"features"`
+	}{Type: "FeatureCollection", Name: from.Title, Source: from, Features: []builtFeature{}}
+	for _, feature := range collection.Features {
+		code, _ := feature.Properties["comm_code"].(string)
+		name, _ := feature.Properties["name"].(string)
+		built.Features = append(built.Features, builtFeature{
+			Type:       "Feature",
+			Properties: map[string]string{"comm_code": code, "name": name},
+			Geometry:   feature.Geometry,
+		})
+	}
+
+	content, err := json.MarshalIndent(built, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// Checked the same way the built-in file is loaded, so a file that is written can be embedded
+	loaded, _, err := parseBoundaries(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if !slices.ContainsFunc(loaded, func(community Community) bool { return community.Code == strings.ToUpper(code) }) {
+			return nil, fmt.Errorf("the community boundaries have no community %s", code)
+		}
+	}
+	return append(content, '\n'), nil
+}
+
+// mustLoadBoundaries - parses the built-in boundaries, which are tested so any failure is a programming error
+func mustLoadBoundaries() ([]Community, Source) {
+	loaded, from, err := parseBoundaries(boundariesFile)
+	if err != nil {
+		panic(err)
+	}
+	return loaded, from
+}
+
+// parseBoundaries - parses a GeoJSON feature collection of Polygon and MultiPolygon community boundaries and its source
+func parseBoundaries(content []byte) ([]Community, Source, error) {
+	var collection struct {
+		Source   Source    `json:"source"`
+		Features []feature `json:"features"`
+	}
+	if err := json.Unmarshal(content, &collection); err != nil {
+		return nil, Source{}, fmt.Errorf("failed to parse community boundaries: %v", err)
+	}
+
+	var loaded []Community
+	for _, feature := range collection.Features {
+		community := Community{Code: strings.ToUpper(feature.Properties.Code), Name: feature.Properties.Name}
+		if community.Code == "" {
+			return nil, Source{}, fmt.Errorf("community boundary '%s' has no comm_code", community.Name)
+		}
+
+		var multipolygon [][][][]float64
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var polygon [][][]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &polygon); err != nil {
+				return nil, Source{}, fmt.Errorf("failed to parse boundary of %s: %v", community.Code, err)
+			}
+			multipolygon = [][][][]float64{polygon}
+		case "MultiPolygon":
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &multipolygon); err != nil {
+				return nil, Source{}, fmt.Errorf("failed to parse boundary of %s: %v", community.Code, err)
+			}
+		default:
+			return nil, Source{}, fmt.Errorf("boundary of %s is a %s, expected a Polygon or MultiPolygon", community.Code, feature.Geometry.Type)
+		}
+
+		for _, rings := range multipolygon {
+			var polygon geo.Polygon
+			for _, ring := range rings {
+				var points []geo.Point
+				for _, coordinate := range ring {
+					if len(coordinate) < 2 {
+						return nil, Source{}, fmt.Errorf("boundary of %s has a coordinate without a longitude and latitude", community.Code)
+					}
+					points = append(points, geo.Point{Latitude: coordinate[1], Longitude: coordinate[0]})
+				}
+				polygon = append(polygon, points)
+			}
+			community.polygons = append(community.polygons, polygon)
+		}
+		loaded = append(loaded, community)
+	}
+
+	return loaded, collection.Source, nil
+}
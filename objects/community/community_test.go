@@ -0,0 +1,200 @@
+package community
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestBoundaries - places items by the hand-drawn outlines in testdata for one test
+func useTestBoundaries(t *testing.T) {
+	content, err := os.ReadFile("testdata/communities.geojson")
+	require.NoError(t, err)
+	require.NoError(t, SetBoundaries(content))
+	t.Cleanup(ResetBoundaries)
+}
+
+// configure - sets the home community and reference point for one test
+func configure(t *testing.T, home string, reference *geo.Point, referenceName string) {
+	previous := settings
+	t.Cleanup(func() { settings = previous })
+	require.NoError(t, Configure(home, reference, referenceName))
+}
+
+func Test_Boundaries(t *testing.T) {
+	useTestBoundaries(t)
+	assert.Contains(t, Codes(), "KIL")
+
+	found, ok := Lookup("kil")
+	assert.True(t, ok)
+	assert.Equal(t, "KILLARNEY/GLENGARRY", found.Name)
+}
+
+func Test_Community_Bounds(t *testing.T) {
+	useTestBoundaries(t)
+	found, ok := Lookup("KIL")
+	require.True(t, ok)
+
+	southWest, northEast := found.Bounds()
+	assert.Equal(t, geo.Point{Latitude: 51.0272, Longitude: -114.1405}, southWest)
+	assert.Equal(t, geo.Point{Latitude: 51.038, Longitude: -114.124}, northEast)
+}
+
+func Test_Locate_RealRecords(t *testing.T) {
+	useTestBoundaries(t)
+	// Points and community codes of records in data/development-permits.json
+	tests := []struct {
+		address  string
+		point    geo.Point
+		expected string
+	}{
+		{address: "2823 30 ST SW", point: geo.Point{Latitude: 51.02919670018869, Longitude: -114.13142364827681}, expected: "KIL"},
+		{address: "2719 17 AV SW", point: geo.Point{Latitude: 51.03765, Longitude: -114.12546}, expected: "KIL"},
+		{address: "3015 26A ST SW", point: geo.Point{Latitude: 51.02794, Longitude: -114.12536}, expected: "KIL"},
+		{address: "#A 3214 28 ST SW", point: geo.Point{Latitude: 51.02617, Longitude: -114.1278}, expected: "RIC"},
+		{address: "2025 25 ST SW", point: geo.Point{Latitude: 51.03532, Longitude: -114.12101}, expected: "RIC"},
+		{address: "#120 3003 37 ST SW", point: geo.Point{Latitude: 51.02865, Longitude: -114.14134}, expected: "GBK"},
+		{address: "2619 37 ST SW", point: geo.Point{Latitude: 51.0308, Longitude: -114.14148}, expected: "GDL"},
+		{address: "3519 34 AV SW", point: geo.Point{Latitude: 51.02314, Longitude: -114.13665}, expected: "RUT"},
+	}
+
+	for _, test := range tests {
+		found, ok := Locate(test.point)
+		assert.True(t, ok, test.address)
+		assert.Equal(t, test.expected, found.Code, test.address)
+	}
+
+	_, ok := Locate(geo.Point{Latitude: 51.0447, Longitude: -114.0719})
+	assert.False(t, ok)
+}
+
+func Test_Annotate_Placement(t *testing.T) {
+	useTestBoundaries(t)
+	configure(t, "KIL", nil, "")
+
+	inside := Annotate(&geo.Point{Latitude: 51.0292, Longitude: -114.1314})
+	assert.Equal(t, &Position{CommunityCode: "KIL", Community: "KILLARNEY/GLENGARRY", Placement: PlacementInCommunity}, inside)
+
+	adjacent := Annotate(&geo.Point{Latitude: 51.02657, Longitude: -114.12937})
+	assert.Equal(t, "RIC", adjacent.CommunityCode)
+	assert.Equal(t, PlacementAdjacent, adjacent.Placement)
+
+	// Downtown is well beyond the test boundaries
+	outside := Annotate(&geo.Point{Latitude: 51.0447, Longitude: -114.0719})
+	assert.Equal(t, &Position{Placement: PlacementOutside}, outside)
+
+	assert.Nil(t, Annotate(nil))
+}
+
+func Test_Annotate_Distance(t *testing.T) {
+	reference := geo.Point{Latitude: 51.0326, Longitude: -114.13225}
+	configure(t, "", &reference, "the community centre")
+
+	position := Annotate(&geo.Point{Latitude: 51.02657, Longitude: -114.12937})
+	require.NotNil(t, position.DistanceMeters)
+	assert.Equal(t, 700.0, *position.DistanceMeters)
+	assert.Equal(t, "S", position.Direction)
+	assert.Equal(t, "the community centre", position.Reference)
+	assert.Empty(t, position.Placement)
+
+	subscriber := AnnotateFrom(&geo.Point{Latitude: 51.02657, Longitude: -114.12937}, geo.Point{Latitude: 51.02657, Longitude: -114.13}, "my-block")
+	assert.Equal(t, 44.0, *subscriber.DistanceMeters)
+	assert.Equal(t, "E", subscriber.Direction)
+	assert.Equal(t, "my-block", subscriber.Reference)
+}
+
+func Test_Configure_UnknownCommunity(t *testing.T) {
+	useTestBoundaries(t)
+	configure(t, "", nil, "")
+	assert.EqualError(t, Configure("XYZ", nil, ""), "unknown community 'XYZ', expected one of KIL, RIC, RUT, GBK, GDL, SHG")
+}
+
+func Test_TemplatePosition(t *testing.T) {
+	distance := 724.0
+	position := &Position{CommunityCode: "RIC", Community: "RICHMOND", Placement: PlacementAdjacent, DistanceMeters: &distance, Direction: "SE", Reference: "the community centre"}
+
+	described := TemplatePosition("en", position)
+	assert.Equal(t, "Adjacent · RICHMOND · 724 m southeast of the community centre", described.Summary)
+	assert.Equal(t, "southeast", described.Direction)
+
+	assert.Equal(t, "Adjacent · RICHMOND · 724 m au sud-est de the community centre", TemplatePosition("fr", position).Summary)
+
+	assert.Nil(t, TemplatePosition("en", nil))
+	assert.Nil(t, TemplatePosition("en", &Position{}))
+}
+
+func Test_FormatDistance(t *testing.T) {
+	assert.Equal(t, "0 m", FormatDistance(0))
+	assert.Equal(t, "999 m", FormatDistance(999))
+	assert.Equal(t, "1.2 km", FormatDistance(1249))
+}
+
+func Test_ParseBoundaries_Errors(t *testing.T) {
+	_, _, err := parseBoundaries([]byte(`{"features":[{"properties":{"comm_code":"X"},"geometry":{"type":"Point","coordinates":[-114,51]}}]}`))
+	assert.EqualError(t, err, "boundary of X is a Point, expected a Polygon or MultiPolygon")
+
+	_, _, err = parseBoundaries([]byte(`{"features":[{"properties":{"name":"Nowhere"},"geometry":{"type":"Polygon","coordinates":[]}}]}`))
+	assert.EqualError(t, err, "community boundary 'Nowhere' has no comm_code")
+
+	loaded, _, err := parseBoundaries([]byte(`{"features":[{"properties":{"comm_code":"x"},"geometry":{"type":"MultiPolygon","coordinates":[[[[-114,51],[-113,51],[-113,52]]]]}}]}`))
+	assert.NoError(t, err)
+	assert.True(t, loaded[0].Contains(geo.Point{Latitude: 51.2, Longitude: -113.5}))
+}
+
+func Test_BoundariesSource(t *testing.T) {
+	// Built-in boundaries are only ever the City's, copied with cmd/communityboundaries, never drawn by hand
+	if len(Codes()) > 0 {
+		assert.NotEmpty(t, BoundariesSource().Dataset)
+		assert.NotNil(t, BoundariesSource().Version)
+	}
+}
+
+func Test_NoBoundaries(t *testing.T) {
+	configure(t, "", nil, "")
+	require.NoError(t, SetBoundaries([]byte(`{"type":"FeatureCollection","features":[]}`)))
+	t.Cleanup(ResetBoundaries)
+
+	assert.EqualError(t, Configure("KIL", nil, ""), "no community boundaries are built in to place items relative to 'KIL', copy the City's with go run ./cmd/communityboundaries")
+	reference := geo.Point{Latitude: 51.0326, Longitude: -114.13225}
+	require.NoError(t, Configure("", &reference, ""))
+
+	// Only the distance from the reference point is known
+	position := Annotate(&geo.Point{Latitude: 51.02657, Longitude: -114.12937})
+	assert.Empty(t, position.Community)
+	assert.Empty(t, position.Placement)
+	assert.Equal(t, 700.0, *position.DistanceMeters)
+}
+
+func Test_BuildBoundaries(t *testing.T) {
+	// As the City's dataset writes it, with every column as a property
+	dataset := []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"comm_code":"KIL","name":"KILLARNEY/GLENGARRY","sector":"CENTRE","class":"Residential"},
+		 "geometry":{"type":"MultiPolygon","coordinates":[[[[-114.14,51.02],[-114.12,51.02],[-114.12,51.04],[-114.14,51.04],[-114.14,51.02]]]]}}
+	]}`)
+	version := time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC)
+	from := Source{Title: "Community District Boundaries", Dataset: "https://data.calgary.ca/d/surr-xmvs", Version: &version}
+
+	content, err := BuildBoundaries(dataset, []string{"kil"}, from)
+	require.NoError(t, err)
+
+	loaded, loadedFrom, err := parseBoundaries(content)
+	require.NoError(t, err)
+	assert.Equal(t, from.Dataset, loadedFrom.Dataset)
+	require.NotNil(t, loadedFrom.Version)
+	assert.True(t, version.Equal(*loadedFrom.Version))
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "KIL", loaded[0].Code)
+	assert.Equal(t, "KILLARNEY/GLENGARRY", loaded[0].Name)
+	assert.True(t, loaded[0].Contains(geo.Point{Latitude: 51.03, Longitude: -114.13}))
+	assert.NotContains(t, string(content), "sector")
+
+	_, err = BuildBoundaries(dataset, []string{"KIL", "RIC"}, from)
+	assert.EqualError(t, err, "the community boundaries have no community RIC")
+
+	_, err = BuildBoundaries([]byte(`{"features":[{"properties":{"name":"NOWHERE"},"geometry":{"type":"Polygon","coordinates":[]}}]}`), nil, from)
+	assert.EqualError(t, err, "community boundary 'NOWHERE' has no comm_code")
+}
@@ -1,18 +1,31 @@
 package developmentpermit
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/jeffadavidson/development-bot/interactions/calgaryopendata"
 	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/address"
+	"github.com/jeffadavidson/development-bot/objects/community"
 	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
+	"github.com/jeffadavidson/development-bot/objects/related"
+	"github.com/jeffadavidson/development-bot/objects/selection"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
 	"github.com/jeffadavidson/development-bot/utilities/fileio"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/jeffadavidson/development-bot/utilities/sanitize"
+	"github.com/jeffadavidson/development-bot/utilities/templates"
 	"github.com/jeffadavidson/development-bot/utilities/toolbox"
 	"golang.org/x/exp/slices"
 )
@@ -33,7 +46,7 @@ func updateStateHistory(fetchedPermit *DevelopmentPermit, storedPermit *Developm
 
 	// Check if this is a new state we haven't seen before
 	currentStatus := strings.ToLower(strings.TrimSpace(fetchedPermit.StatusCurrent))
-	timestamp := time.Now().Format(time.RFC3339)
+	timestamp := citytime.FormatTimestamp(citytime.Now())
 
 	// If this is the first time we're seeing this permit, add initial state
 	if len(fetchedPermit.StateHistory) == 0 {
@@ -64,6 +77,22 @@ func updateStateHistory(fetchedPermit *DevelopmentPermit, storedPermit *Developm
 	}
 }
 
+// normalizeStateHistory rewrites stored state history timestamps in the city's timezone
+// Older runs recorded them in the runner's zone, so history mixed Z and -06:00. Returns the number rewritten
+func normalizeStateHistory(permits []DevelopmentPermit) int {
+	normalized := 0
+	for i := range permits {
+		for j := range permits[i].StateHistory {
+			timestamp, ok := citytime.NormalizeTimestamp(permits[i].StateHistory[j].Timestamp)
+			if ok && timestamp != permits[i].StateHistory[j].Timestamp {
+				permits[i].StateHistory[j].Timestamp = timestamp
+				normalized++
+			}
+		}
+	}
+	return normalized
+}
+
 // GetStateHistorySummary returns a human-readable summary of the permit's lifecycle
 func (dp DevelopmentPermit) GetStateHistorySummary() string {
 	if len(dp.StateHistory) == 0 {
@@ -72,7 +101,7 @@ func (dp DevelopmentPermit) GetStateHistorySummary() string {
 
 	summary := fmt.Sprintf("Permit %s lifecycle:\n", dp.PermitNum)
 	for i, state := range dp.StateHistory {
-		timestamp, _ := time.Parse(time.RFC3339, state.Timestamp)
+		timestamp, _ := citytime.ParseTimestamp(state.Timestamp)
 		summary += fmt.Sprintf("  %d. %s - %s", i+1,
 			strings.Title(state.Status),
 			timestamp.Format("Jan 2, 2006 3:04 PM"))
@@ -116,6 +145,15 @@ type DevelopmentPermit struct {
 	ReleaseDate         *string       `json:"releasedate"`
 	RSSGuid             string        `json:"rss_guid"`
 	StateHistory        []StateChange `json:"state_history"`
+	// LandUse - the land use district explained from the glossary, filled in when permits are parsed
+	LandUse []landuse.District `json:"land_use,omitempty"`
+	// Addresses, Parcels - the address and location addresses normalized without duplicates, and the parcels they are on
+	Addresses []string `json:"addresses,omitempty"`
+	Parcels   []string `json:"parcels,omitempty"`
+	// Position - the community the item is in and where it is from the reference point, worked out when items are parsed
+	Position *community.Position `json:"position,omitempty"`
+	// Related - rezoning applications at the same address or parcel, linked after each run
+	Related []related.Link `json:"related,omitempty"`
 }
 
 type StateChange struct {
@@ -129,203 +167,186 @@ type Point struct {
 	Coordinates []float64 `json:"coordinates"`
 }
 
-// CreateInformationMessage - Builds an information message from the development permit
-func (dp DevelopmentPermit) CreateInformationMessage() string {
-	var lineSeparator string = "\n"
-	var message string = ""
-
-	// Markdown
-	message += "## About\n\n"
-	message += fmt.Sprintf("**Permit Number:** %v", dp.PermitNum)
-	if dp.AppliedDate != nil {
-		appliedDate, err := time.Parse("2006-01-02T15:04:05.000", *dp.AppliedDate)
+// templateItem - collects the values the title, description and message templates show, an empty language uses the default
+func (dp DevelopmentPermit) templateItem(language string) templates.Item {
+	data := templates.Item{
+		Language:         language,
+		Dataset:          activity.DevelopmentPermit,
+		PermitNum:        dp.PermitNum,
+		Status:           dp.StatusCurrent,
+		Category:         toolbox.StringValue(dp.Category),
+		AppliedDate:      toolbox.StringValue(dp.AppliedDate),
+		Address:          toolbox.StringValue(dp.Address),
+		Community:        toolbox.StringValue(dp.CommunityName),
+		Ward:             toolbox.StringValue(dp.Ward),
+		Applicant:        toolbox.StringValue(dp.Applicant),
+		Description:      toolbox.StringValue(dp.Description),
+		CurrentLandUse:   toolbox.StringValue(dp.LandUseDistrictDesc),
+		ApplicationType:  toolbox.StringValue(dp.PermittedDiscretion),
+		MustCommenceDate: toolbox.StringValue(dp.MustCommenceDate),
+		Decision:         toolbox.StringValue(dp.Decision),
+		DecisionBy:       toolbox.StringValue(dp.DecisionBy),
+		ReleaseDate:      toolbox.StringValue(dp.ReleaseDate),
+		CurrentDistricts: landuse.TemplateDistricts(language, toolbox.StringValue(dp.LandUseDistrict)),
+		Position:         community.TemplatePosition(language, dp.ActivityRecord().Position),
+		Related:          related.TemplateItems(language, dp.Related),
+		Links:            templates.Links{DevelopmentMap: dp.rssLink()},
+	}
+
+	if appliedDate, err := citytime.ParseFloating(data.AppliedDate); err == nil {
+		data.AppliedDate = appliedDate.Format("2006-01-02")
+	}
+	if data.Address != "" {
+		data.Links.GoogleMaps = sanitize.URL("https://maps.google.com/?q=" + sanitize.QueryValue(data.Address+", Calgary, Alberta"))
+	}
+
+	// Timeline from the state history
+	for _, state := range dp.StateHistory {
+		// Dates are shown as they were in Calgary, whichever zone the timestamp was recorded in
+		parsedTime, err := citytime.ParseTimestamp(state.Timestamp)
 		if err != nil {
-			message += fmt.Sprintf("%v**Date Applied:** %v", lineSeparator, *dp.AppliedDate)
-		} else {
-			dateStr := appliedDate.Format("2006-01-02")
-			message += fmt.Sprintf("%v**Date Applied:** %v", lineSeparator, dateStr)
+			continue // Skip if we can't parse the timestamp
 		}
-	}
-	if dp.Address != nil {
-		message += fmt.Sprintf("%v**Address:** %v", lineSeparator, *dp.Address)
-	}
-	if dp.CommunityName != nil {
-		message += fmt.Sprintf("%v**Community:** %v", lineSeparator, *dp.CommunityName)
-	}
-	if dp.Applicant != nil {
-		message += fmt.Sprintf("%v**Applicant:** %v", lineSeparator, *dp.Applicant)
-	}
-	if dp.Description != nil {
-		message += fmt.Sprintf("%v**Description:** %v", lineSeparator, *dp.Description)
-	}
-	if dp.LandUseDistrictDesc != nil {
-		message += fmt.Sprintf("%v**Current Land Use:** %v", lineSeparator, *dp.LandUseDistrictDesc)
-	}
-	if dp.StatusCurrent != "" {
-		message += fmt.Sprintf("%v**Permit Status:** %v", lineSeparator, dp.StatusCurrent)
-	}
-	if dp.MustCommenceDate != nil {
-		message += fmt.Sprintf("%v**Must Comment By Date:** %v", lineSeparator, *dp.MustCommenceDate)
-	}
-	if dp.PermittedDiscretion != nil {
-		message += fmt.Sprintf("%v**Application Type:** %v", lineSeparator, *dp.PermittedDiscretion)
-	}
-	if dp.Decision != nil {
-		message += fmt.Sprintf("%v**Decision:** %v", lineSeparator, *dp.Decision)
-	}
-	if dp.ReleaseDate != nil {
-		message += fmt.Sprintf("%v**Release Date:** %v", lineSeparator, *dp.ReleaseDate)
+
+		// Format status name for display
+		data.Timeline = append(data.Timeline, templates.TimelineEntry{
+			Label:  strings.Title(strings.Replace(state.Status, "_", " ", -1)),
+			Date:   i18n.FormatDate(language, parsedTime),
+			Detail: state.Decision,
+		})
 	}
 
-	// Add google and dmap links
-	message += "\n## Links\n\n"
-	message += lineSeparator + lineSeparator
-	message += fmt.Sprintf("%v[Development Map](https://dmap.calgary.ca/?find=%v)", lineSeparator, dp.PermitNum)
-	if dp.Address != nil {
-		message += fmt.Sprintf("%v [Google Maps](https://maps.google.com/?q=%v)", lineSeparator, url.QueryEscape(fmt.Sprintf("%v, Calgary, Alberta", *dp.Address)))
+	// Add must commence date if available
+	if parsedDate, err := citytime.ParseFloating(data.MustCommenceDate); err == nil {
+		data.Timeline = append(data.Timeline, templates.TimelineEntry{Label: i18n.T(language, "must-commence-by"), Date: i18n.FormatDate(language, parsedDate)})
 	}
-	message += lineSeparator
 
-	return message
+	return data
+}
+
+// CreateInformationMessage - Builds an information message from the development permit
+func (dp DevelopmentPermit) CreateInformationMessage() string {
+	return templates.Message(dp.templateItem(""))
 }
 
 // generateRSSDescription creates a self-contained HTML description for RSS feeds
 func (dp *DevelopmentPermit) generateRSSDescription() string {
-	var html strings.Builder
-
-	// Header with permit number and status
-	html.WriteString(fmt.Sprintf("<h3>🏗️ DEVELOPMENT PERMIT %s</h3>", dp.PermitNum))
-	html.WriteString(fmt.Sprintf("<p><strong>Status:</strong> %s</p>", dp.StatusCurrent))
-
-	// Address and location details with map links
-	if dp.Address != nil {
-		html.WriteString(fmt.Sprintf("<p>📍 <strong>Address:</strong> %s</p>", *dp.Address))
+	return templates.Description(dp.templateItem(""))
+}
 
-		// Map links right under address
-		html.WriteString("<ul style='margin-top: 5px; margin-bottom: 15px;'>")
-		googleMapsURL := fmt.Sprintf("https://maps.google.com/?q=%s", url.QueryEscape(fmt.Sprintf("%s, Calgary, Alberta", *dp.Address)))
-		html.WriteString(fmt.Sprintf("<li>📍 <a href='%s' target='_blank'>View on Google Maps</a></li>", googleMapsURL))
-		dmapURL := "https://developmentmap.calgary.ca/?find=" + dp.PermitNum
-		html.WriteString(fmt.Sprintf("<li>📋 <a href='%s' target='_blank'>View on Calgary Development Map</a></li>", dmapURL))
-		html.WriteString("</ul>")
-	}
+// rssTitle - a consistent title, without the status, so the feed item is updated in place as the development permit changes
+func (dp DevelopmentPermit) rssTitle() string {
+	return templates.Title(dp.templateItem(""))
+}
 
-	if dp.CommunityName != nil {
-		html.WriteString(fmt.Sprintf("<p>🏘️ <strong>Community:</strong> %s", *dp.CommunityName))
-		if dp.Ward != nil {
-			html.WriteString(fmt.Sprintf(" (Ward %s)", *dp.Ward))
-		}
-		html.WriteString("</p>")
-	}
+// rssLink - the development permit on the City of Calgary development map
+func (dp DevelopmentPermit) rssLink() string {
+	return sanitize.URL("https://developmentmap.calgary.ca/?find=" + sanitize.QueryValue(dp.PermitNum))
+}
 
-	// Project details
-	if dp.Description != nil {
-		html.WriteString(fmt.Sprintf("<p>🏗️ <strong>Project:</strong> %s</p>", *dp.Description))
-	}
+// DescribeAction - Fills in the details notifications need about the development permit an action applies to
+func (dp DevelopmentPermit) DescribeAction(action *fileaction.FileAction) {
+	action.Title = dp.rssTitle()
+	action.Link = dp.rssLink()
+	action.Description = dp.generateRSSDescription()
+	action.GUID = dp.RSSGuid
+	action.Record = dp.ActivityRecord()
+}
 
-	if dp.LandUseDistrictDesc != nil {
-		html.WriteString(fmt.Sprintf("<p>🏘️ <strong>Land Use:</strong> %s</p>", *dp.LandUseDistrictDesc))
-	}
+// AddToRSSFeed - Adds the permit to an RSS feed or refreshes its existing item, returns true if the feed changed
+func (dp *DevelopmentPermit) AddToRSSFeed(rss *rssfeed.RSS) bool {
+	// Use the most recent timestamp from permit data
+	pubDate := dp.getMostRecentTimestamp()
 
-	if dp.PermittedDiscretion != nil {
-		html.WriteString(fmt.Sprintf("<p>📋 <strong>Application Type:</strong> %s</p>", *dp.PermittedDiscretion))
-	}
+	// Render in the feed's language so each language's feed can be built from the same records
+	item := dp.templateItem(i18n.FromFeedLanguage(rss.Channel.Language))
+	title := templates.Title(item)
+	link := dp.rssLink()
 
-	// Applicant information
+	// Enhanced RSS metadata
+	category := "Development Permit"
+	author := "Unknown"
 	if dp.Applicant != nil {
-		html.WriteString(fmt.Sprintf("<p>👤 <strong>Applicant:</strong> %s</p>", *dp.Applicant))
+		author = *dp.Applicant
 	}
+	source := "City of Calgary Open Data"
+	comments := link + "#comments"
 
-	// Timeline information - include state history
-	html.WriteString("<h4>📅 TIMELINE:</h4><ul>")
-
-	// Add state history timeline
-	for _, state := range dp.StateHistory {
-		// Parse timestamp (handle both Z and timezone formats)
-		var parsedTime time.Time
-		var err error
-
-		// Try parsing with Z suffix first
-		if parsedTime, err = time.Parse("2006-01-02T15:04:05Z", state.Timestamp); err != nil {
-			// Try parsing with timezone offset
-			if parsedTime, err = time.Parse("2006-01-02T15:04:05-07:00", state.Timestamp); err != nil {
-				// Try parsing with .000 milliseconds
-				if parsedTime, err = time.Parse("2006-01-02T15:04:05.000", state.Timestamp); err != nil {
-					continue // Skip if we can't parse the timestamp
-				}
-			}
-		}
+	// Use full content in both description and content:encoded for maximum compatibility
+	fullContent := templates.Description(item)
 
-		// Format status name for display
-		statusDisplay := strings.Title(strings.Replace(state.Status, "_", " ", -1))
-		html.WriteString(fmt.Sprintf("<li>%s: %s", statusDisplay, parsedTime.Format("January 2, 2006")))
+	return rss.UpdateItem(title, fullContent, link, dp.RSSGuid, pubDate, category, author, source, comments, fullContent)
+}
 
-		// Add decision info if present
-		if state.Decision != "" {
-			html.WriteString(fmt.Sprintf(" (%s)", state.Decision))
-		}
-		html.WriteString("</li>")
+// ActivityRecord - Builds the dataset independent view of the permit used for filtering
+func (dp DevelopmentPermit) ActivityRecord() activity.Record {
+	record := activity.Record{
+		Dataset:     activity.DevelopmentPermit,
+		PermitNum:   dp.PermitNum,
+		Status:      dp.StatusCurrent,
+		LastUpdated: dp.lastRecordedTimestamp(),
 	}
-
-	// Add must commence date if available
-	if dp.MustCommenceDate != nil && *dp.MustCommenceDate != "" {
-		if parsedDate, err := time.Parse("2006-01-02T15:04:05.000", *dp.MustCommenceDate); err == nil {
-			html.WriteString(fmt.Sprintf("<li>Must Commence By: %s</li>", parsedDate.Format("January 2, 2006")))
+	if dp.Category != nil {
+		record.Category = *dp.Category
+	}
+	if dp.Description != nil {
+		record.Summary = *dp.Description
+		record.Description = *dp.Description
+	}
+	if dp.Applicant != nil {
+		record.Applicant = *dp.Applicant
+	}
+	if dp.Decision != nil {
+		record.Decision = *dp.Decision
+	}
+	if dp.Address != nil {
+		record.Address = *dp.Address
+	}
+	if dp.AppliedDate != nil {
+		if appliedDate, err := citytime.ParseFloating(*dp.AppliedDate); err == nil {
+			record.AppliedDate = appliedDate
 		}
 	}
-	html.WriteString("</ul>")
+	if dp.Ward != nil {
+		record.Ward = *dp.Ward
+	}
+	record.Parcels = address.Parcels(toolbox.StringValue(dp.Address), toolbox.StringValue(dp.LocationAddresses))
+	record.CurrentDistricts = landuse.Explain(toolbox.StringValue(dp.LandUseDistrict))
 
-	// Decision information
-	if dp.Decision != nil && *dp.Decision != "" {
-		html.WriteString("<div style='background-color: #d4edda; padding: 10px; margin: 10px 0; border-left: 4px solid #28a745;'>")
-		html.WriteString(fmt.Sprintf("<strong>✅ DECISION:</strong> %s", *dp.Decision))
-		if dp.DecisionBy != nil && *dp.DecisionBy != "" {
-			html.WriteString(fmt.Sprintf(" (by %s)", *dp.DecisionBy))
+	// The point holds full precision coordinates, the latitude and longitude fields are rounded
+	if len(dp.Point.Coordinates) == 2 {
+		record.Location = &geo.Point{Latitude: dp.Point.Coordinates[1], Longitude: dp.Point.Coordinates[0]}
+	} else if dp.Latitude != nil && dp.Longitude != nil {
+		if point, err := geo.ParsePoint(*dp.Latitude + "," + *dp.Longitude); err == nil {
+			record.Location = &point
 		}
-		html.WriteString("</div>")
+	}
+	if record.Location != nil {
+		record.Locations = []geo.Point{*record.Location}
+	}
+	record.Position = community.Annotate(record.Location)
+	record.CommunityCode = toolbox.StringValue(dp.CommunityCode)
+	if record.CommunityCode == "" && record.Position != nil {
+		record.CommunityCode = record.Position.CommunityCode
 	}
 
-	return html.String()
+	return record
 }
 
-func EvaluateDevelopmentPermits(rss *rssfeed.RSS) ([]fileaction.FileAction, error) {
-	fetchedDevelopmentPermits, storedDevelopmentPermits, err := loadDevelopmentPermits()
-	if err != nil {
-		return nil, err
-	}
+// EvaluateDevelopmentPermits - Evaluates fetched development permits against the stored ones into the RSS feed, returns the actions taken
+// The fetched permits are not saved, so the caller saves them once the feed is written and a failed run is retried in full
+func EvaluateDevelopmentPermits(rss *rssfeed.RSS, fetchedDevelopmentPermits []DevelopmentPermit, storedDevelopmentPermits []DevelopmentPermit) []fileaction.FileAction {
 	fileActions := getDevelopmentPermitActions(fetchedDevelopmentPermits, storedDevelopmentPermits)
 
 	// Process actions for Development Permits
-	for _, val := range fileActions {
+	for i, val := range fileActions {
 		if val.Action == "CREATE" {
 			// Add new RSS item
 			dp := findDevelopmentPermitByPermitNum(fetchedDevelopmentPermits, val.PermitNum)
 			if dp != nil {
-				// Use the most recent timestamp from permit data
-				pubDate := dp.getMostRecentTimestamp()
-
-				// Generate consistent title without status
-				title := fmt.Sprintf("🏗️ Development Permit: %s", val.PermitNum)
-				if dp.Address != nil {
-					title = fmt.Sprintf("🏗️ Development Permit: %s - %s", val.PermitNum, *dp.Address)
-				}
-
-				link := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s", val.PermitNum)
-
-				// Enhanced RSS metadata
-				category := "Development Permit"
-				author := "Unknown"
-				if dp.Applicant != nil {
-					author = *dp.Applicant
-				}
-				source := "City of Calgary Open Data"
-				comments := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s#comments", val.PermitNum)
-
-				// Use full content in both description and content:encoded for maximum compatibility
-				fullContent := dp.generateRSSDescription()
-
-				// Only update RSS and print messages if actual changes were made
-				wasUpdated := rss.UpdateItem(title, fullContent, link, dp.RSSGuid, pubDate, category, author, source, comments, fullContent)
+				dp.DescribeAction(&fileActions[i])
+				// Only print messages if actual changes were made
+				wasUpdated := dp.AddToRSSFeed(rss)
 				if wasUpdated {
 					fmt.Printf("Development Permit %s:\n\tCreating RSS feed entry...\n", val.PermitNum)
 					fmt.Printf("\tCreated RSS feed entry!\n")
@@ -337,31 +358,12 @@ func EvaluateDevelopmentPermits(rss *rssfeed.RSS) ([]fileaction.FileAction, erro
 			// Update existing RSS item
 			dp := findDevelopmentPermitByPermitNum(fetchedDevelopmentPermits, val.PermitNum)
 			if dp != nil {
-				// Use the most recent timestamp from permit data
-				pubDate := dp.getMostRecentTimestamp()
-
-				// Generate consistent title without status
-				title := fmt.Sprintf("🏗️ Development Permit: %s", val.PermitNum)
-				if dp.Address != nil {
-					title = fmt.Sprintf("🏗️ Development Permit: %s - %s", val.PermitNum, *dp.Address)
+				dp.DescribeAction(&fileActions[i])
+				if stored := findDevelopmentPermitByPermitNum(storedDevelopmentPermits, val.PermitNum); stored != nil {
+					fileActions[i].PreviousStatus = stored.StatusCurrent
 				}
-
-				link := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s", val.PermitNum)
-
-				// Enhanced RSS metadata
-				category := "Development Permit"
-				author := "Unknown"
-				if dp.Applicant != nil {
-					author = *dp.Applicant
-				}
-				source := "City of Calgary Open Data"
-				comments := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s#comments", val.PermitNum)
-
-				// Use full content in both description and content:encoded for maximum compatibility
-				fullContent := dp.generateRSSDescription()
-
-				// Only update RSS and print messages if actual changes were made
-				wasUpdated := rss.UpdateItem(title, fullContent, link, dp.RSSGuid, pubDate, category, author, source, comments, fullContent)
+				// Only print messages if actual changes were made
+				wasUpdated := dp.AddToRSSFeed(rss)
 				if wasUpdated {
 					fmt.Printf("Development Permit %s:\n\tUpdating RSS feed entry...\n", val.PermitNum)
 					fmt.Printf("\tUpdated RSS feed entry!\n")
@@ -370,32 +372,51 @@ func EvaluateDevelopmentPermits(rss *rssfeed.RSS) ([]fileaction.FileAction, erro
 		}
 	}
 
-	// Save Development Permits (save the fetched data so we can compare next time)
-	saveDevelopmentPermits(fetchedDevelopmentPermits)
+	return fileActions
+}
+
+// SetRelatedApplications - Sets the related applications of each permit by permit number and refreshes the feed items of permits whose links changed
+// Returns the number of permits whose links changed
+func SetRelatedApplications(permits []DevelopmentPermit, links map[string][]related.Link, rss *rssfeed.RSS) int {
+	changed := 0
+	for i := range permits {
+		if related.Equal(permits[i].Related, links[permits[i].PermitNum]) {
+			continue
+		}
+		permits[i].Related = links[permits[i].PermitNum]
+		changed++
+
+		// Only permits already in the feed are refreshed, linking alone does not add an item
+		if rss.FindItemByGUID(permits[i].RSSGuid) != nil {
+			permits[i].AddToRSSFeed(rss)
+		}
+	}
 
-	return fileActions, nil
+	return changed
 }
 
-// loadDevelopmentPermits - Gets fetched development permits, gets stored development permits
-func loadDevelopmentPermits() ([]DevelopmentPermit, []DevelopmentPermit, error) {
+// LoadDevelopmentPermits - Fetches development permits from Calgary Open Data and loads the stored permits they are compared against
+// With updatedSince only the permits changed since then are fetched and the rest are kept as stored. Also returns the latest :updated_at fetched
+// Permits are ordered newest applied first, then by permit number, so runs add feed items in the same order whatever order they arrive in
+func LoadDevelopmentPermits(ctx context.Context, updatedSince string) ([]DevelopmentPermit, []DevelopmentPermit, string, error) {
 	// Load existing development permits
-	storedDevelopmentPermitsBytes, loadErr := fileio.GetFileContents("./data/development-permits.json")
-	if loadErr != nil {
-		return nil, nil, loadErr
-	}
-	storedDevelopmentPermits, parseErr := parseDevelopmentPermits(storedDevelopmentPermitsBytes)
+	storedDevelopmentPermits, parseErr := LoadStoredDevelopmentPermits()
 	if parseErr != nil {
-		return nil, nil, parseErr
+		return nil, nil, "", parseErr
+	}
+	// Saved with the fetched data at the end of the run, so this only rewrites anything once
+	if normalized := normalizeStateHistory(storedDevelopmentPermits); normalized > 0 {
+		fmt.Printf("Normalized %d stored state history timestamps to %s\n", normalized, citytime.Location())
 	}
 
 	//Get development Permits from calgary open data
-	fetchedDevelopmentPermitsRaw, fetchErr := calgaryopendata.GetDevelopmentPermits()
+	fetchedDevelopmentPermitsRaw, fetchErr := calgaryopendata.GetDevelopmentPermits(ctx, selection.FromConfig(config.Config.Neighborhood).Where(true), updatedSince)
 	if fetchErr != nil {
-		return nil, nil, fetchErr
+		return nil, nil, "", fetchErr
 	}
 	fetchedDevelopmentPermits, parseErr2 := parseDevelopmentPermits(fetchedDevelopmentPermitsRaw)
 	if parseErr2 != nil {
-		return nil, nil, parseErr
+		return nil, nil, "", parseErr2
 	}
 
 	// Ensure all fetched permits have GUIDs (generate if new, preserve if existing)
@@ -409,14 +430,43 @@ func loadDevelopmentPermits() ([]DevelopmentPermit, []DevelopmentPermit, error)
 			fetchedDevelopmentPermits[i].RSSGuid = generateUniqueGUID(fetchedDevelopmentPermits[i].PermitNum, "development-permit")
 		}
 
+		// Keep the links from the last run, they are refreshed once both datasets are loaded
+		if storedPermit != nil {
+			fetchedDevelopmentPermits[i].Related = storedPermit.Related
+		}
+
 		// Update state history if status changed
 		updateStateHistory(&fetchedDevelopmentPermits[i], storedPermit)
 	}
 
-	return fetchedDevelopmentPermits, storedDevelopmentPermits, nil
+	// Only changed permits were fetched, so the rest carry over as stored until the next full fetch
+	if updatedSince != "" {
+		fetchedDevelopmentPermits = mergeChangedDevelopmentPermits(storedDevelopmentPermits, fetchedDevelopmentPermits)
+	}
+
+	sort.SliceStable(fetchedDevelopmentPermits, func(i, j int) bool {
+		iApplied, jApplied := toolbox.StringValue(fetchedDevelopmentPermits[i].AppliedDate), toolbox.StringValue(fetchedDevelopmentPermits[j].AppliedDate)
+		if iApplied != jApplied {
+			return iApplied > jApplied
+		}
+		return fetchedDevelopmentPermits[i].PermitNum < fetchedDevelopmentPermits[j].PermitNum
+	})
+
+	return fetchedDevelopmentPermits, storedDevelopmentPermits, calgaryopendata.LatestUpdate(fetchedDevelopmentPermitsRaw), nil
+}
+
+// LoadStoredDevelopmentPermits - Loads the development permits saved by the last run
+func LoadStoredDevelopmentPermits() ([]DevelopmentPermit, error) {
+	storedDevelopmentPermitsBytes, loadErr := fileio.GetFileContents("./data/development-permits.json")
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	return parseDevelopmentPermits(storedDevelopmentPermitsBytes)
 }
 
-func saveDevelopmentPermits(permits []DevelopmentPermit) error {
+// SaveDevelopmentPermits - Saves the development permits so the next run compares against them
+func SaveDevelopmentPermits(permits []DevelopmentPermit) error {
 	// Encode permits as JSON
 	permitsBytes, encodeErr := json.MarshalIndent(permits, "", "  ")
 	if encodeErr != nil {
@@ -441,13 +491,21 @@ func findDevelopmentPermitByPermitNum(searchSlice []DevelopmentPermit, permitNum
 	return &searchSlice[foundIndex]
 }
 
-// getMostRecentTimestamp finds the most recent timestamp from a development permit's data
+// getMostRecentTimestamp finds the most recent timestamp from a development permit's data, the current time when it has none
 func (dp *DevelopmentPermit) getMostRecentTimestamp() time.Time {
+	if mostRecent := dp.lastRecordedTimestamp(); !mostRecent.IsZero() {
+		return mostRecent
+	}
+	return citytime.Now()
+}
+
+// lastRecordedTimestamp - the most recent date or status change recorded for the permit, zero when it has none
+func (dp *DevelopmentPermit) lastRecordedTimestamp() time.Time {
 	var mostRecent time.Time
 
 	// Check applied date
 	if dp.AppliedDate != nil {
-		if appliedDate, err := time.Parse("2006-01-02T15:04:05.000", *dp.AppliedDate); err == nil {
+		if appliedDate, err := citytime.ParseFloating(*dp.AppliedDate); err == nil {
 			if appliedDate.After(mostRecent) {
 				mostRecent = appliedDate
 			}
@@ -456,7 +514,7 @@ func (dp *DevelopmentPermit) getMostRecentTimestamp() time.Time {
 
 	// Check decision date
 	if dp.DecisionDate != nil {
-		if decisionDate, err := time.Parse("2006-01-02T15:04:05.000", *dp.DecisionDate); err == nil {
+		if decisionDate, err := citytime.ParseFloating(*dp.DecisionDate); err == nil {
 			if decisionDate.After(mostRecent) {
 				mostRecent = decisionDate
 			}
@@ -465,7 +523,7 @@ func (dp *DevelopmentPermit) getMostRecentTimestamp() time.Time {
 
 	// Check release date
 	if dp.ReleaseDate != nil {
-		if releaseDate, err := time.Parse("2006-01-02T15:04:05.000", *dp.ReleaseDate); err == nil {
+		if releaseDate, err := citytime.ParseFloating(*dp.ReleaseDate); err == nil {
 			if releaseDate.After(mostRecent) {
 				mostRecent = releaseDate
 			}
@@ -474,18 +532,13 @@ func (dp *DevelopmentPermit) getMostRecentTimestamp() time.Time {
 
 	// Check state history for most recent status change
 	for _, state := range dp.StateHistory {
-		if stateTime, err := time.Parse(time.RFC3339, state.Timestamp); err == nil {
+		if stateTime, err := citytime.ParseTimestamp(state.Timestamp); err == nil {
 			if stateTime.After(mostRecent) {
 				mostRecent = stateTime
 			}
 		}
 	}
 
-	// If no valid timestamp found, use current time
-	if mostRecent.IsZero() {
-		mostRecent = time.Now()
-	}
-
 	return mostRecent
 }
 
@@ -526,6 +579,15 @@ func getDevelopmentPermitActions(fetchedDevelopmentPermits []DevelopmentPermit,
 	return fileActions
 }
 
+// mergeChangedDevelopmentPermits - Copies the stored permits with the changed permits upserted into them, the stored permits are left as they were
+func mergeChangedDevelopmentPermits(stored []DevelopmentPermit, changed []DevelopmentPermit) []DevelopmentPermit {
+	merged := append([]DevelopmentPermit(nil), stored...)
+	for _, permit := range changed {
+		merged = upsertDevelopmentPermit(merged, permit)
+	}
+	return merged
+}
+
 // upsertDevelopmentPermit - updates or inserts a development permet to a list of permits
 func upsertDevelopmentPermit(permits []DevelopmentPermit, thePermit DevelopmentPermit) []DevelopmentPermit {
 	//Search the permits for the index of the permit to add. If found update, if not append
@@ -547,6 +609,13 @@ func parseDevelopmentPermits(developmentPermitByte []byte) ([]DevelopmentPermit,
 		return developmentPermits, fmt.Errorf("failed to parse development permit json. Error: %s", err.Error())
 	}
 
+	for i := range developmentPermits {
+		developmentPermits[i].LandUse = landuse.Explain(toolbox.StringValue(developmentPermits[i].LandUseDistrict))
+		developmentPermits[i].Addresses = address.NormalizeList(toolbox.StringValue(developmentPermits[i].Address), toolbox.StringValue(developmentPermits[i].LocationAddresses))
+		developmentPermits[i].Parcels = address.Parcels(developmentPermits[i].Addresses...)
+		developmentPermits[i].Position = developmentPermits[i].ActivityRecord().Position
+	}
+
 	return developmentPermits, nil
 }
 
@@ -1,10 +1,14 @@
 package developmentpermit
 
 import (
+	"os"
 	"testing"
 	"time"
 
+	"github.com/jeffadavidson/development-bot/objects/community"
 	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/objects/related"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +43,34 @@ func Test_FindDevelopmentPermit_EmptySlice(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+func Test_MergeChangedDevelopmentPermits(t *testing.T) {
+	stored := []DevelopmentPermit{
+		{PermitNum: "DP2026-00867", StatusCurrent: "Hold"},
+		{PermitNum: "DP2026-01738", StatusCurrent: "New"},
+	}
+	changed := []DevelopmentPermit{
+		{PermitNum: "DP2026-01738", StatusCurrent: "Under Review"},
+		{PermitNum: "DP2026-01757", StatusCurrent: "New"},
+	}
+
+	merged := mergeChangedDevelopmentPermits(stored, changed)
+	assert.Equal(t, []DevelopmentPermit{
+		{PermitNum: "DP2026-00867", StatusCurrent: "Hold"},
+		{PermitNum: "DP2026-01738", StatusCurrent: "Under Review"},
+		{PermitNum: "DP2026-01757", StatusCurrent: "New"},
+	}, merged)
+	// The stored permits are still compared against, so they are left unchanged
+	assert.Equal(t, "New", stored[1].StatusCurrent)
+
+	// Unchanged permits carried over from storage take no action
+	actions := getDevelopmentPermitActions(merged, stored)
+	assert.Len(t, actions, 2)
+	assert.Equal(t, "DP2026-01738", actions[0].PermitNum)
+	assert.Equal(t, "UPDATE", actions[0].Action)
+	assert.Equal(t, "DP2026-01757", actions[1].PermitNum)
+	assert.Equal(t, "CREATE", actions[1].Action)
+}
+
 func Test_ParseDevelopmentPermit_Valid(t *testing.T) {
 	dpJson := []byte(`
 	[
@@ -83,6 +115,8 @@ func Test_ParseDevelopmentPermit_Valid(t *testing.T) {
 	permits, err := parseDevelopmentPermits(dpJson)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, 1, len(permits))
+	assert.Equal(t, []string{"2819 36 ST SW"}, permits[0].Addresses)
+	assert.Equal(t, []string{"2819 36 ST SW"}, permits[0].Parcels)
 }
 
 func Test_ParseDevelopmentPermit_MalformedJson(t *testing.T) {
@@ -842,8 +876,8 @@ func TestGetMostRecentTimestamp_WithoutStateHistory(t *testing.T) {
 
 	mostRecent := dp.getMostRecentTimestamp()
 
-	// Should use the release date as the most recent
-	expected, _ := time.Parse("2006-01-02T15:04:05.000", "2025-01-20T16:45:00.000")
+	// Should use the release date as the most recent, upstream dates are Calgary local time
+	expected, _ := time.Parse(time.RFC3339, "2025-01-20T16:45:00-07:00")
 	assert.Equal(t, expected.Unix(), mostRecent.Unix())
 }
 
@@ -1027,7 +1061,7 @@ func TestGenerateRSSDescription_WithStateHistory(t *testing.T) {
 	assert.Contains(t, rssDesc, "Pending Release")
 	assert.Contains(t, rssDesc, "2212 35 ST SW")
 	assert.Contains(t, rssDesc, "KILLARNEY/GLENGARRY")
-	assert.Contains(t, rssDesc, "JOHN TRINH & ASSOCIATES")
+	assert.Contains(t, rssDesc, "JOHN TRINH &amp; ASSOCIATES")
 }
 
 func TestGenerateRSSDescription_EmptyStateHistory(t *testing.T) {
@@ -1105,3 +1139,107 @@ func TestGenerateRSSDescription_StatusDisplayFormatting(t *testing.T) {
 	assert.Contains(t, rssDesc, "In Circulation: July 28, 2025")
 	assert.Contains(t, rssDesc, "Pending Decision: August 14, 2025 (Approval)")
 }
+
+func TestGenerateRSSDescription_EscapesHostileInput(t *testing.T) {
+	address := `2807 27 ST SW'><script>alert(1)</script>`
+	applicant := `SMITH & SONS <img src=x onerror=alert(1)>`
+	description := "NEW: DWELLING ]]> <b>bold</b>"
+	dp := DevelopmentPermit{
+		PermitNum:     "DP2026-01776",
+		Address:       &address,
+		Applicant:     &applicant,
+		Description:   &description,
+		StatusCurrent: `"In Circulation"`,
+	}
+
+	rssDesc := dp.generateRSSDescription()
+
+	assert.NotContains(t, rssDesc, "<script>")
+	assert.NotContains(t, rssDesc, "<img")
+	assert.NotContains(t, rssDesc, "<b>")
+	// The description is stored in CDATA, which must not be closed early
+	assert.NotContains(t, rssDesc, "]]>")
+	assert.Contains(t, rssDesc, "SMITH &amp; SONS &lt;img src=x onerror=alert(1)&gt;")
+	assert.Contains(t, rssDesc, "&#34;In Circulation&#34;")
+	assert.Contains(t, rssDesc, "<a href='https://maps.google.com/?q=2807%2027%20ST%20SW%27%3E%3Cscript%3Ealert%281%29%3C%2Fscript%3E%2C%20Calgary%2C%20Alberta' target='_blank'>")
+}
+
+func TestGenerateRSSDescription_EscapesPermitNumInLinks(t *testing.T) {
+	address := "2807 27 ST SW"
+	dp := DevelopmentPermit{
+		PermitNum: `DP1' onmouseover='alert(1)`,
+		Address:   &address,
+	}
+
+	rssDesc := dp.generateRSSDescription()
+
+	assert.NotContains(t, rssDesc, "' onmouseover='")
+	assert.Contains(t, rssDesc, "href='https://developmentmap.calgary.ca/?find=DP1%27%20onmouseover%3D%27alert%281%29'")
+}
+
+func TestCreateInformationMessage_EscapesMarkdown(t *testing.T) {
+	address := "2807 27 ST SW"
+	applicant := "[Click here](javascript:alert(1))"
+	description := "**NEW**\n## Injected heading <script>"
+	dp := DevelopmentPermit{
+		PermitNum:     "DP2026-01776",
+		Address:       &address,
+		Applicant:     &applicant,
+		Description:   &description,
+		StatusCurrent: "In Circulation",
+	}
+
+	message := dp.CreateInformationMessage()
+
+	assert.Contains(t, message, "**Permit Number:** DP2026-01776\n**Address:** 2807 27 ST SW\n")
+	assert.Contains(t, message, `**Applicant:** \[Click here\](javascript:alert(1))`)
+	assert.Contains(t, message, `**Description:** \*\*NEW\*\* ## Injected heading \<script>`)
+	assert.Contains(t, message, "[Development Map](https://developmentmap.calgary.ca/?find=DP2026-01776)")
+	assert.Contains(t, message, "[Google Maps](https://maps.google.com/?q=2807%2027%20ST%20SW%2C%20Calgary%2C%20Alberta)")
+}
+
+func TestGenerateRSSDescription_ExplainsLandUseDistrict(t *testing.T) {
+	landUse := "MU-1 f3.0h16"
+	landUseDesc := "Mixed Use - General"
+	dp := DevelopmentPermit{PermitNum: "DP2026-00001", StatusCurrent: "In Progress", LandUseDistrict: &landUse, LandUseDistrictDesc: &landUseDesc}
+
+	rssDesc := dp.generateRSSDescription()
+
+	assert.Contains(t, rssDesc, "<p>🏘️ <strong>Land Use:</strong> Mixed Use - General</p>")
+	assert.Contains(t, rssDesc, "<p>ℹ️ <strong>MU-1 f3.0h16</strong> (max height 16 m, max FAR 3.0): Buildings that may combine homes")
+
+	message := dp.CreateInformationMessage()
+	assert.Contains(t, message, "## Land Use Districts\n\n- **MU-1 f3.0h16** – Mixed Use – General (max height 16 m, max FAR 3.0): ")
+}
+
+func TestGenerateRSSDescription_ListsRelatedApplications(t *testing.T) {
+	dp := DevelopmentPermit{PermitNum: "DP2026-00867", StatusCurrent: "Hold", Related: []related.Link{
+		{Dataset: "rezoning-application", PermitNum: "LOC2026-0009", Address: "2823 30 ST SW", Status: "Miscellaneous", Reason: related.ReasonSameAddress},
+	}}
+
+	rssDesc := dp.generateRSSDescription()
+	assert.Contains(t, rssDesc, "<h4>🔗 RELATED APPLICATIONS:</h4>")
+	assert.Contains(t, rssDesc, "<li><a href='https://developmentmap.calgary.ca/?find=LOC2026-0009' target='_blank'>Rezoning Application LOC2026-0009</a> - 2823 30 ST SW (same address, Miscellaneous)</li>")
+
+	message := dp.CreateInformationMessage()
+	assert.Contains(t, message, "## Related Applications\n\n- [Rezoning Application LOC2026-0009](https://developmentmap.calgary.ca/?find=LOC2026-0009) - 2823 30 ST SW (same address, Miscellaneous)\n")
+
+	dp.Related = nil
+	assert.NotContains(t, dp.generateRSSDescription(), "RELATED APPLICATIONS")
+}
+
+func TestGenerateRSSDescription_ShowsPosition(t *testing.T) {
+	boundaries, err := os.ReadFile("../community/testdata/communities.geojson")
+	assert.NoError(t, err)
+	assert.NoError(t, community.SetBoundaries(boundaries))
+	t.Cleanup(community.ResetBoundaries)
+	assert.NoError(t, community.Configure("KIL", &geo.Point{Latitude: 51.0326, Longitude: -114.13225}, "the centre of Killarney/Glengarry"))
+	t.Cleanup(func() { community.Configure("", nil, "") })
+
+	dp := DevelopmentPermit{PermitNum: "DP2026-01738", StatusCurrent: "New",
+		Point: Point{Type: "Point", Coordinates: []float64{-114.12780304757428, 51.02616576239821}}}
+
+	assert.Contains(t, dp.generateRSSDescription(), "<p>🧭 <strong>Location:</strong> Adjacent · RICHMOND · 780 m southeast of the centre of Killarney/Glengarry</p>")
+	assert.Contains(t, dp.CreateInformationMessage(), "**Location:** Adjacent · RICHMOND · 780 m southeast of the centre of Killarney/Glengarry\n")
+	assert.Equal(t, community.PlacementAdjacent, dp.ActivityRecord().Position.Placement)
+}
@@ -160,3 +160,24 @@ func TestGetStateHistorySummary_Empty(t *testing.T) {
 func stringPointer(s string) *string {
 	return &s
 }
+
+func TestNormalizeStateHistory(t *testing.T) {
+	permits := []DevelopmentPermit{{
+		PermitNum: "DP2025-12345",
+		StateHistory: []StateChange{
+			{Status: "submitted", Timestamp: "2025-01-10T17:00:00Z"},
+			{Status: "under review", Timestamp: "2025-01-15T10:00:00-07:00"},
+			{Status: "approved", Timestamp: "2025-07-20T09:30:00-06:00"},
+			{Status: "released", Timestamp: "not a timestamp"},
+		},
+	}}
+
+	assert.Equal(t, 1, normalizeStateHistory(permits))
+	assert.Equal(t, "2025-01-10T10:00:00-07:00", permits[0].StateHistory[0].Timestamp)
+	assert.Equal(t, "2025-01-15T10:00:00-07:00", permits[0].StateHistory[1].Timestamp)
+	assert.Equal(t, "2025-07-20T09:30:00-06:00", permits[0].StateHistory[2].Timestamp)
+	assert.Equal(t, "not a timestamp", permits[0].StateHistory[3].Timestamp)
+
+	// Normalized history is left alone on the next run
+	assert.Equal(t, 0, normalizeStateHistory(permits))
+}
@@ -0,0 +1,80 @@
+package fetchstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/utilities/fileio"
+)
+
+// Path - where the fetch state is stored between runs
+const Path = "./data/fetch-state.json"
+
+// Dataset - how far a dataset has been fetched
+type Dataset struct {
+	// UpdatedAt - the latest Socrata :updated_at seen, rows changed since it are fetched next run
+	UpdatedAt string `json:"updated_at,omitempty"`
+	// Query - the selection the dataset was last fetched in full with, rows changed since the mark only follow on from the same selection
+	Query string `json:"query,omitempty"`
+	// LastFull - when the whole window was last fetched, which catches rows that were deleted or left the window
+	LastFull *time.Time `json:"last_full,omitempty"`
+}
+
+// State - how far each dataset has been fetched, by dataset name
+type State map[string]Dataset
+
+// Load - Loads the fetch state, a state that has not been saved yet is empty so every dataset is fetched in full
+func Load(path string) (State, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return State{}, nil
+	}
+
+	content, err := fileio.GetFileContents(path)
+	if err != nil {
+		return nil, err
+	}
+	state := State{}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse fetch state: %v", err)
+	}
+	return state, nil
+}
+
+// Save - Saves the fetch state
+func (s State) Save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileio.WriteFileContents(path, content)
+}
+
+// Since - Returns the :updated_at to fetch a dataset's changed rows from, empty when the whole window should be fetched
+// The whole window is fetched when fetching is not incremental, nothing has been seen yet, the selection's query has changed
+// or the last full fetch is older than fullEvery
+func (s State) Since(dataset string, query string, incremental bool, fullEvery time.Duration, now time.Time) string {
+	recorded, found := s[dataset]
+	if !incremental || !found || recorded.UpdatedAt == "" || recorded.Query != query {
+		return ""
+	}
+	if recorded.LastFull == nil || now.Sub(*recorded.LastFull) >= fullEvery {
+		return ""
+	}
+	return recorded.UpdatedAt
+}
+
+// Record - Notes a successful fetch with the selection's query, the high-water mark only moves forward between full fetches
+// A full fetch saw every row the query selects, so its latest :updated_at replaces the mark
+func (s State) Record(dataset string, query string, updatedAt string, full bool, now time.Time) {
+	recorded := s[dataset]
+	if full {
+		recorded.UpdatedAt = updatedAt
+		recorded.Query = query
+		recorded.LastFull = &now
+	} else if updatedAt > recorded.UpdatedAt {
+		recorded.UpdatedAt = updatedAt
+	}
+	s[dataset] = recorded
+}
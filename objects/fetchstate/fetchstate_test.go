@@ -0,0 +1,82 @@
+package fetchstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selected - the query the tests fetch with
+const selected = "communitycode IN ('KIL')"
+
+func Test_Since(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-2 * time.Hour)
+	old := now.Add(-30 * time.Hour)
+	state := State{
+		"development-permit":   {UpdatedAt: "2026-04-01T06:00:00.000Z", Query: selected, LastFull: &recent},
+		"rezoning-application": {UpdatedAt: "2026-03-30T06:00:00.000Z", Query: selected, LastFull: &old},
+		"never-full":           {UpdatedAt: "2026-03-30T06:00:00.000Z", Query: selected},
+	}
+
+	assert.Equal(t, "2026-04-01T06:00:00.000Z", state.Since("development-permit", selected, true, 24*time.Hour, now))
+	// The last full fetch is too old, so the whole window is fetched to catch deletions
+	assert.Equal(t, "", state.Since("rezoning-application", selected, true, 24*time.Hour, now))
+	assert.Equal(t, "", state.Since("never-full", selected, true, 24*time.Hour, now))
+	assert.Equal(t, "", state.Since("development-permit", selected, false, 24*time.Hour, now))
+	assert.Equal(t, "", state.Since("unknown", selected, true, 24*time.Hour, now))
+	// Another selection's rows have not been fetched before, changes since the mark would miss them
+	assert.Equal(t, "", state.Since("development-permit", "communitycode IN ('KIL','RIC')", true, 24*time.Hour, now))
+}
+
+func Test_Record(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	state := State{}
+
+	state.Record("development-permit", selected, "2026-04-01T06:00:00.000Z", true, now)
+	assert.Equal(t, Dataset{UpdatedAt: "2026-04-01T06:00:00.000Z", Query: selected, LastFull: &now}, state["development-permit"])
+
+	// An incremental fetch with no newer rows keeps the mark and the last full fetch
+	state.Record("development-permit", selected, "", false, now.Add(time.Hour))
+	state.Record("development-permit", selected, "2026-03-01T00:00:00.000Z", false, now.Add(time.Hour))
+	assert.Equal(t, Dataset{UpdatedAt: "2026-04-01T06:00:00.000Z", Query: selected, LastFull: &now}, state["development-permit"])
+
+	// A full fetch with another selection starts again from what it saw
+	later := now.Add(2 * time.Hour)
+	state.Record("development-permit", "1=1", "2026-03-15T00:00:00.000Z", true, later)
+	assert.Equal(t, Dataset{UpdatedAt: "2026-03-15T00:00:00.000Z", Query: "1=1", LastFull: &later}, state["development-permit"])
+}
+
+func Test_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fetch-state.json")
+
+	missing, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	state := State{}
+	state.Record("rezoning-application", selected, "2026-04-01T06:00:00.000Z", true, now)
+	require.NoError(t, state.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-04-01T06:00:00.000Z", loaded["rezoning-application"].UpdatedAt)
+	require.NotNil(t, loaded["rezoning-application"].LastFull)
+	assert.True(t, now.Equal(*loaded["rezoning-application"].LastFull))
+	assert.Equal(t, selected, loaded["rezoning-application"].Query)
+}
+
+func Test_Save_LeavesOutMissingFullFetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fetch-state.json")
+	state := State{"development-permit": {UpdatedAt: "2026-04-01T06:00:00.000Z"}}
+	require.NoError(t, state.Save(path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "last_full")
+}
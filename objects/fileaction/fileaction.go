@@ -1,7 +1,17 @@
 package fileaction
 
+import "github.com/jeffadavidson/development-bot/objects/activity"
+
 type FileAction struct {
 	PermitNum string
 	Action    string
 	Message   string
+
+	// Describes the permit or application acted on, filled in when the action is applied to the feed
+	Title          string
+	Link           string
+	Description    string
+	GUID           string
+	PreviousStatus string
+	Record         activity.Record
 }
@@ -0,0 +1,354 @@
+package landuse
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/jeffadavidson/development-bot/utilities/templates"
+)
+
+// Aspects of a district that a rezoning can change
+const (
+	AspectDistrict   = "district"
+	AspectMaxHeight  = "max-height"
+	AspectMaxFAR     = "max-far"
+	AspectMaxDensity = "max-density"
+)
+
+// limitAliases - accepted names for the limits filters and alert rules can compare, and the aspect they select
+var limitAliases = map[string]string{
+	"height":      AspectMaxHeight,
+	"max-height":  AspectMaxHeight,
+	"far":         AspectMaxFAR,
+	"max-far":     AspectMaxFAR,
+	"density":     AspectMaxDensity,
+	"max-density": AspectMaxDensity,
+}
+
+// glossaryFile - the Land Use Bylaw districts, with a version that is bumped whenever an entry changes
+//
+//go:embed glossary/districts.json
+var glossaryFile []byte
+
+// glossary - the parsed glossary, districts are keyed by their upper case code
+var glossary = mustLoadGlossary()
+
+// modifierPattern - district modifiers such as f3.0, h16 or d75, several can be written together as f3.0h16
+var modifierPattern = regexp.MustCompile(`^(?:[a-z]\d+(?:\.\d+)?)+$`)
+
+// modifierPartPattern - one modifier within a run of modifiers
+var modifierPartPattern = regexp.MustCompile(`([a-z])(\d+(?:\.\d+)?)`)
+
+// bylawPattern - a Direct Control bylaw number such as 85Z2008 or 123D2019, written after DC with or without brackets
+var bylawPattern = regexp.MustCompile(`^\(?(\d+[A-Z]\d{4})\)?$`)
+
+// basePattern - a base district code such as R-CG, MU-1, C-COR1 or R-C1s
+var basePattern = regexp.MustCompile(`^[A-Za-z]+(?:-[A-Za-z0-9]+)*$`)
+
+// Entry - a district in the glossary
+type Entry struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+	// MaxHeight - the height in metres the district allows when no h modifier is given, zero when it has no single default
+	MaxHeight float64 `json:"max_height_m,omitempty"`
+}
+
+// District - a land use district as written upstream, parsed into its base district and modifiers
+// Limits are zero when they are not known, Explain fills in the name, summary and default height from the glossary
+type District struct {
+	// Code - the district as written, such as MU-1 f3.0h16
+	Code string `json:"code"`
+	// Base - the district without its modifiers, such as MU-1
+	Base    string `json:"base"`
+	Name    string `json:"name,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	// MaxHeight - the maximum building height in metres, from the h modifier or the district's default
+	MaxHeight float64 `json:"max_height_m,omitempty"`
+	// MaxFAR - the maximum floor area ratio from the f modifier, building floor area divided by parcel area
+	MaxFAR float64 `json:"max_far,omitempty"`
+	// MaxDensity - the maximum density from the d modifier, in homes per hectare
+	MaxDensity float64 `json:"max_density_uph,omitempty"`
+	// Bylaw - the bylaw that sets the rules of a Direct Control district, such as 85Z2008
+	Bylaw string `json:"bylaw,omitempty"`
+}
+
+// Change - one way a rezoning changes what can be built, an empty value means the district rules apply
+type Change struct {
+	// Aspect - district, max-height or max-far
+	Aspect string `json:"aspect"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+type glossaryData struct {
+	Version   string  `json:"version"`
+	Bylaw     string  `json:"bylaw"`
+	Districts []Entry `json:"districts"`
+	byCode    map[string]Entry
+}
+
+// Version - Returns the version of the embedded glossary
+func Version() string {
+	return glossary.Version
+}
+
+// Bylaw - Returns the bylaw the glossary describes
+func Bylaw() string {
+	return glossary.Bylaw
+}
+
+// Lookup - Finds a base district such as R-CG in the glossary, ignoring case
+func Lookup(code string) (Entry, bool) {
+	entry, found := glossary.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return entry, found
+}
+
+// Parse - Parses a single district such as MU-1 f3.0h16, M-C1 d75 or DC (85Z2008) into its base district and modifiers
+func Parse(code string) (District, error) {
+	fields := strings.Fields(code)
+	if len(fields) == 0 {
+		return District{}, fmt.Errorf("land use district is empty")
+	}
+	district := District{Code: strings.Join(fields, " "), Base: strings.ToUpper(fields[0])}
+
+	// DC(85Z2008) is sometimes written without a space
+	if base, bylaw, found := strings.Cut(fields[0], "("); found && strings.EqualFold(base, "DC") {
+		district.Base = "DC"
+		fields = append([]string{base, "(" + bylaw}, fields[1:]...)
+	}
+	if !basePattern.MatchString(district.Base) {
+		return district, fmt.Errorf("land use district '%s' does not start with a district code", district.Code)
+	}
+
+	for _, field := range fields[1:] {
+		if district.Base == "DC" && district.Bylaw == "" {
+			if match := bylawPattern.FindStringSubmatch(strings.ToUpper(field)); match != nil {
+				district.Bylaw = match[1]
+				continue
+			}
+		}
+		if !modifierPattern.MatchString(field) {
+			return district, fmt.Errorf("land use district '%s' has an unknown modifier '%s'", district.Code, field)
+		}
+		for _, match := range modifierPartPattern.FindAllStringSubmatch(field, -1) {
+			amount, err := strconv.ParseFloat(match[2], 64)
+			if err != nil || amount <= 0 {
+				return district, fmt.Errorf("land use district '%s' has an invalid modifier '%s'", district.Code, match[0])
+			}
+			var limit *float64
+			switch match[1] {
+			case "f":
+				limit = &district.MaxFAR
+			case "h":
+				limit = &district.MaxHeight
+			case "d":
+				limit = &district.MaxDensity
+			default:
+				return district, fmt.Errorf("land use district '%s' has an unknown modifier '%s'", district.Code, match[0])
+			}
+			if *limit != 0 {
+				return district, fmt.Errorf("land use district '%s' repeats the modifier '%s'", district.Code, match[1])
+			}
+			*limit = amount
+		}
+	}
+
+	return district, nil
+}
+
+// ParseValue - Parses every district in a land use value, a parcel split between districts is written "MU-1 f2.0h22; S-R"
+func ParseValue(value string) ([]District, error) {
+	var districts []District
+	for _, part := range strings.Split(value, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		district, err := Parse(part)
+		if err != nil {
+			return nil, err
+		}
+		districts = append(districts, district)
+	}
+	return districts, nil
+}
+
+// Explain - Parses and explains each district in a land use value from the glossary
+// Districts that cannot be parsed are kept with their code so they are still shown
+func Explain(value string) []District {
+	var districts []District
+	for _, part := range strings.Split(value, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		district, err := Parse(part)
+		if err != nil {
+			district = District{Code: strings.Join(strings.Fields(part), " "), Base: strings.ToUpper(strings.Fields(part)[0])}
+		}
+		if entry, found := Lookup(district.Base); found {
+			district.Name = entry.Name
+			district.Summary = entry.Summary
+			if district.MaxHeight == 0 {
+				district.MaxHeight = entry.MaxHeight
+			}
+		}
+		districts = append(districts, district)
+	}
+
+	return districts
+}
+
+// ParseLimit - Looks up the aspect selected by a limit name such as height, far or density
+func ParseLimit(value string) (string, error) {
+	aspect, found := limitAliases[strings.ToLower(strings.TrimSpace(value))]
+	if !found {
+		return "", fmt.Errorf("unknown land use limit '%s', expected height, far or density", value)
+	}
+	return aspect, nil
+}
+
+// Increases - Checks if going from one set of districts to another raises a limit, both limits must be known
+func Increases(aspect string, from []District, to []District) bool {
+	fromLimit, toLimit := largest(aspect, from), largest(aspect, to)
+	return fromLimit > 0 && toLimit > fromLimit
+}
+
+// Limits - Describes the height and floor area limits the district's modifiers set, empty when it has none
+func (d District) Limits(language string) string {
+	var limits []string
+	if d.MaxHeight > 0 {
+		limits = append(limits, i18n.Tf(language, "limit-max-height", formatHeight(d.MaxHeight)))
+	}
+	if d.MaxFAR > 0 {
+		limits = append(limits, i18n.Tf(language, "limit-max-far", formatFAR(d.MaxFAR)))
+	}
+	if d.MaxDensity > 0 {
+		limits = append(limits, i18n.Tf(language, "limit-max-density", formatNumber(d.MaxDensity)))
+	}
+	if d.Bylaw != "" {
+		limits = append(limits, i18n.Tf(language, "limit-bylaw", d.Bylaw))
+	}
+	return strings.Join(limits, ", ")
+}
+
+// Compare - Lists what a rezoning from one land use value to another changes
+func Compare(from string, to string) []Change {
+	fromDistricts := Explain(from)
+	toDistricts := Explain(to)
+
+	var changes []Change
+	if fromNames, toNames := names(fromDistricts), names(toDistricts); fromNames != toNames {
+		changes = append(changes, Change{Aspect: AspectDistrict, From: fromNames, To: toNames})
+	}
+	if fromHeight, toHeight := largest(AspectMaxHeight, fromDistricts), largest(AspectMaxHeight, toDistricts); fromHeight != toHeight {
+		changes = append(changes, Change{Aspect: AspectMaxHeight, From: formatHeight(fromHeight), To: formatHeight(toHeight)})
+	}
+	if fromFAR, toFAR := largest(AspectMaxFAR, fromDistricts), largest(AspectMaxFAR, toDistricts); fromFAR != toFAR {
+		changes = append(changes, Change{Aspect: AspectMaxFAR, From: formatFAR(fromFAR), To: formatFAR(toFAR)})
+	}
+	if fromDensity, toDensity := largest(AspectMaxDensity, fromDistricts), largest(AspectMaxDensity, toDistricts); fromDensity != toDensity {
+		changes = append(changes, Change{Aspect: AspectMaxDensity, From: formatNumber(fromDensity), To: formatNumber(toDensity)})
+	}
+
+	return changes
+}
+
+// TemplateDistricts - Explains the districts in a land use value for the title, description and message templates
+func TemplateDistricts(language string, value string) []templates.District {
+	var districts []templates.District
+	for _, district := range Explain(value) {
+		districts = append(districts, templates.District{
+			Code:    district.Code,
+			Name:    district.Name,
+			Summary: district.Summary,
+			Limits:  district.Limits(language),
+		})
+	}
+	return districts
+}
+
+// TemplateChanges - Lists what a rezoning changes for the templates, labelled in the language
+func TemplateChanges(language string, from string, to string) []templates.LandUseChange {
+	var changes []templates.LandUseChange
+	for _, change := range Compare(from, to) {
+		changes = append(changes, templates.LandUseChange{Label: i18n.T(language, change.Aspect), From: change.From, To: change.To})
+	}
+	return changes
+}
+
+// names - the glossary names of districts, the code for districts the glossary does not have
+func names(districts []District) string {
+	var names []string
+	for _, district := range districts {
+		if district.Name != "" {
+			names = append(names, district.Name)
+		} else {
+			names = append(names, district.Base)
+		}
+	}
+	return strings.Join(names, "; ")
+}
+
+// largest - the largest limit of an aspect any of the districts allow, zero when none set one
+func largest(aspect string, districts []District) float64 {
+	result := 0.0
+	for _, district := range districts {
+		switch aspect {
+		case AspectMaxHeight:
+			result = max(result, district.MaxHeight)
+		case AspectMaxFAR:
+			result = max(result, district.MaxFAR)
+		case AspectMaxDensity:
+			result = max(result, district.MaxDensity)
+		}
+	}
+	return result
+}
+
+// formatHeight - a height in metres such as "16 m", empty for no limit
+func formatHeight(metres float64) string {
+	if metres <= 0 {
+		return ""
+	}
+	return formatNumber(metres) + " m"
+}
+
+// formatNumber - a limit without trailing zeros, empty for no limit
+func formatNumber(value float64) string {
+	if value <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatFAR - a floor area ratio written the way the bylaw does, such as "3.0", empty for no limit
+func formatFAR(ratio float64) string {
+	if ratio <= 0 {
+		return ""
+	}
+	formatted := strconv.FormatFloat(ratio, 'f', -1, 64)
+	if !strings.Contains(formatted, ".") {
+		formatted += ".0"
+	}
+	return formatted
+}
+
+// mustLoadGlossary - loads the built-in glossary, which is tested so any failure is a programming error
+func mustLoadGlossary() glossaryData {
+	var loaded glossaryData
+	if err := json.Unmarshal(glossaryFile, &loaded); err != nil {
+		panic(fmt.Errorf("failed to parse land use glossary: %v", err))
+	}
+
+	loaded.byCode = make(map[string]Entry, len(loaded.Districts))
+	for _, entry := range loaded.Districts {
+		loaded.byCode[strings.ToUpper(entry.Code)] = entry
+	}
+	return loaded
+}
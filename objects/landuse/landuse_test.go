@@ -0,0 +1,190 @@
+package landuse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Glossary(t *testing.T) {
+	assert.NotEmpty(t, Version())
+	assert.Equal(t, "Land Use Bylaw 1P2007", Bylaw())
+
+	seen := make(map[string]bool)
+	for _, entry := range glossary.Districts {
+		assert.False(t, seen[entry.Code], "duplicate district %s", entry.Code)
+		seen[entry.Code] = true
+		assert.NotEmpty(t, entry.Name, entry.Code)
+		assert.NotEmpty(t, entry.Summary, entry.Code)
+	}
+}
+
+func Test_Lookup(t *testing.T) {
+	entry, found := Lookup("h-go")
+	assert.True(t, found)
+	assert.Equal(t, "Housing – Grade Oriented", entry.Name)
+
+	_, found = Lookup("X-99")
+	assert.False(t, found)
+}
+
+func Test_Explain(t *testing.T) {
+	districts := Explain("MU-1 f3.0h16")
+	assert.Len(t, districts, 1)
+	assert.Equal(t, "MU-1 f3.0h16", districts[0].Code)
+	assert.Equal(t, "MU-1", districts[0].Base)
+	assert.Equal(t, "Mixed Use – General", districts[0].Name)
+	assert.Equal(t, 3.0, districts[0].MaxFAR)
+	assert.Equal(t, 16.0, districts[0].MaxHeight)
+	assert.Equal(t, "max height 16 m, max FAR 3.0", districts[0].Limits("en"))
+	assert.Equal(t, "hauteur max. 16 m, COS max. 3.0", districts[0].Limits("fr"))
+}
+
+func Test_ParseValue_RealValues(t *testing.T) {
+	// Every land use value in data/development-permits.json and data/rezoning-applications.json
+	tests := []struct {
+		value    string
+		expected []District
+	}{
+		{value: "R-CG", expected: []District{{Code: "R-CG", Base: "R-CG"}}},
+		{value: "H-GO", expected: []District{{Code: "H-GO", Base: "H-GO"}}},
+		{value: "DC", expected: []District{{Code: "DC", Base: "DC"}}},
+		{value: "C-C1", expected: []District{{Code: "C-C1", Base: "C-C1"}}},
+		{value: "S-CI", expected: []District{{Code: "S-CI", Base: "S-CI"}}},
+		{value: "M-C1", expected: []District{{Code: "M-C1", Base: "M-C1"}}},
+		{value: "M-C1 d75", expected: []District{{Code: "M-C1 d75", Base: "M-C1", MaxDensity: 75}}},
+		{value: "MU-1 h14", expected: []District{{Code: "MU-1 h14", Base: "MU-1", MaxHeight: 14}}},
+		{value: "MU-1 f3.0h16", expected: []District{{Code: "MU-1 f3.0h16", Base: "MU-1", MaxFAR: 3.0, MaxHeight: 16}}},
+		{value: "MU-1 f4.5h23", expected: []District{{Code: "MU-1 f4.5h23", Base: "MU-1", MaxFAR: 4.5, MaxHeight: 23}}},
+		{value: "MU-1 f2.0h22; S-R", expected: []District{
+			{Code: "MU-1 f2.0h22", Base: "MU-1", MaxFAR: 2.0, MaxHeight: 22},
+			{Code: "S-R", Base: "S-R"},
+		}},
+	}
+
+	for _, test := range tests {
+		districts, err := ParseValue(test.value)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.expected, districts, test.value)
+
+		for _, district := range Explain(test.value) {
+			assert.NotEmpty(t, district.Name, test.value)
+		}
+	}
+}
+
+func Test_Parse_OtherForms(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected District
+	}{
+		{code: "DC (85Z2008)", expected: District{Code: "DC (85Z2008)", Base: "DC", Bylaw: "85Z2008"}},
+		{code: "DC 123D2019", expected: District{Code: "DC 123D2019", Base: "DC", Bylaw: "123D2019"}},
+		{code: "DC(12D2015)", expected: District{Code: "DC(12D2015)", Base: "DC", Bylaw: "12D2015"}},
+		{code: "  m-cg   d111 ", expected: District{Code: "m-cg d111", Base: "M-CG", MaxDensity: 111}},
+		{code: "C-COR1 f4.0 h20", expected: District{Code: "C-COR1 f4.0 h20", Base: "C-COR1", MaxFAR: 4.0, MaxHeight: 20}},
+		{code: "R-C1s", expected: District{Code: "R-C1s", Base: "R-C1S"}},
+	}
+
+	for _, test := range tests {
+		district, err := Parse(test.code)
+		assert.NoError(t, err, test.code)
+		assert.Equal(t, test.expected, district, test.code)
+	}
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":             "land use district is empty",
+		"MU-1 x5":      "land use district 'MU-1 x5' has an unknown modifier 'x5'",
+		"MU-1 h16h20":  "land use district 'MU-1 h16h20' repeats the modifier 'h'",
+		"MU-1 h0":      "land use district 'MU-1 h0' has an invalid modifier 'h0'",
+		"MU-1 tall":    "land use district 'MU-1 tall' has an unknown modifier 'tall'",
+		"R-CG 85Z2008": "land use district 'R-CG 85Z2008' has an unknown modifier '85Z2008'",
+		"(DC)":         "land use district '(DC)' does not start with a district code",
+	}
+
+	for code, expected := range tests {
+		_, err := Parse(code)
+		assert.EqualError(t, err, expected, code)
+	}
+
+	_, err := ParseValue("R-CG; MU-1 x5")
+	assert.Error(t, err)
+}
+
+func Test_Explain_UnknownAndEmpty(t *testing.T) {
+	assert.Empty(t, Explain(""))
+	assert.Empty(t, Explain(" ; "))
+
+	districts := Explain("X-99 h10")
+	assert.Equal(t, "X-99", districts[0].Base)
+	assert.Empty(t, districts[0].Name)
+	assert.Equal(t, 10.0, districts[0].MaxHeight)
+
+	// A district that cannot be parsed is still explained from its base district
+	districts = Explain("H-GO ???")
+	assert.Equal(t, "H-GO ???", districts[0].Code)
+	assert.Equal(t, "Housing – Grade Oriented", districts[0].Name)
+}
+
+func Test_Explain_DefaultHeight(t *testing.T) {
+	assert.Equal(t, 11.0, Explain("R-CG")[0].MaxHeight)
+	assert.Equal(t, 20.0, Explain("M-C2 h20")[0].MaxHeight)
+	assert.Equal(t, 0.0, Explain("DC")[0].MaxHeight)
+	assert.Equal(t, "max height 11 m", Explain("R-CG")[0].Limits("en"))
+	assert.Equal(t, "max height 14 m, max 75 homes/ha", Explain("M-C1 d75")[0].Limits("en"))
+	assert.Equal(t, "bylaw 85Z2008", Explain("DC (85Z2008)")[0].Limits("en"))
+}
+
+func Test_Increases(t *testing.T) {
+	assert.True(t, Increases(AspectMaxHeight, Explain("R-CG"), Explain("H-GO")))
+	assert.True(t, Increases(AspectMaxHeight, Explain("MU-1 f3.0h16"), Explain("MU-1 f3.0h22; S-R")))
+	assert.False(t, Increases(AspectMaxHeight, Explain("H-GO"), Explain("R-CG")))
+	assert.False(t, Increases(AspectMaxFAR, Explain("MU-1 f3.0h16"), Explain("MU-1 f3.0h22")))
+	assert.True(t, Increases(AspectMaxDensity, Explain("M-C1 d75"), Explain("M-C1 d100")))
+
+	// A Direct Control district's limits are in its bylaw, so a rezoning from one is never known to increase them
+	assert.False(t, Increases(AspectMaxHeight, Explain("DC"), Explain("H-GO")))
+}
+
+func Test_ParseLimit(t *testing.T) {
+	aspect, err := ParseLimit("Height")
+	assert.NoError(t, err)
+	assert.Equal(t, AspectMaxHeight, aspect)
+
+	aspect, err = ParseLimit("far")
+	assert.NoError(t, err)
+	assert.Equal(t, AspectMaxFAR, aspect)
+
+	_, err = ParseLimit("width")
+	assert.EqualError(t, err, "unknown land use limit 'width', expected height, far or density")
+}
+
+func Test_Compare(t *testing.T) {
+	changes := Compare("MU-1 f3.0h16", "MU-1 f4.5h23")
+	assert.Equal(t, []Change{
+		{Aspect: AspectMaxHeight, From: "16 m", To: "23 m"},
+		{Aspect: AspectMaxFAR, From: "3.0", To: "4.5"},
+	}, changes)
+
+	changes = Compare("DC", "H-GO")
+	assert.Equal(t, []Change{
+		{Aspect: AspectDistrict, From: "Direct Control District", To: "Housing – Grade Oriented"},
+		{Aspect: AspectMaxHeight, From: "", To: "12 m"},
+	}, changes)
+
+	changes = Compare("M-C1 d75", "M-C1 d100")
+	assert.Equal(t, []Change{{Aspect: AspectMaxDensity, From: "75", To: "100"}}, changes)
+
+	assert.Empty(t, Compare("R-CG", "r-cg"))
+}
+
+func Test_TemplateChanges(t *testing.T) {
+	changes := TemplateChanges("en", "R-CG", "MU-1 h14")
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "District", changes[0].Label)
+	assert.Equal(t, "Maximum Height", changes[1].Label)
+	assert.Equal(t, "11 m", changes[1].From)
+	assert.Equal(t, "14 m", changes[1].To)
+}
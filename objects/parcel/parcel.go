@@ -0,0 +1,140 @@
+package parcel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/address"
+	"github.com/jeffadavidson/development-bot/utilities/fileio"
+)
+
+// IndexPath - where the parcel index is stored, it keeps applications after they leave the fetched data
+const IndexPath = "./data/parcels.json"
+
+// Application - an application filed at a parcel, as it was when last seen
+type Application struct {
+	// Dataset - development-permit or rezoning-application
+	Dataset   string `json:"dataset"`
+	PermitNum string `json:"permitnum"`
+	Address   string `json:"address"`
+	Status    string `json:"status"`
+	Summary   string `json:"summary,omitempty"`
+	// AppliedDate - the date the application was made, as YYYY-MM-DD
+	AppliedDate string `json:"applieddate,omitempty"`
+}
+
+// Parcel - every application filed at one address, suites included
+type Parcel struct {
+	Address      string        `json:"address"`
+	Applications []Application `json:"applications"`
+}
+
+// Index - the applications filed at each parcel, by parcel address
+type Index map[string][]Application
+
+// Load - Loads the parcel index, an index that has not been saved yet is empty
+func Load(path string) (Index, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Index{}, nil
+	}
+
+	content, err := fileio.GetFileContents(path)
+	if err != nil {
+		return nil, err
+	}
+	index := Index{}
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse parcel index: %v", err)
+	}
+	return index, nil
+}
+
+// Save - Saves the parcel index
+func (index Index) Save(path string) error {
+	content, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileio.WriteFileContents(path, content)
+}
+
+// Add - Adds or refreshes the records at each of their parcels, returns the number of parcels that changed
+func (index Index) Add(records []activity.Record) int {
+	changed := make(map[string]bool)
+	for _, record := range records {
+		application := Application{
+			Dataset:   record.Dataset,
+			PermitNum: record.PermitNum,
+			Address:   address.Normalize(record.Address),
+			Status:    record.Status,
+			Summary:   record.Summary,
+		}
+		if !record.AppliedDate.IsZero() {
+			application.AppliedDate = record.AppliedDate.Format("2006-01-02")
+		}
+
+		for _, parcel := range recordParcels(record) {
+			if index.upsert(parcel, application) {
+				changed[parcel] = true
+			}
+		}
+	}
+	return len(changed)
+}
+
+// Lookup - Finds the parcel an address is on, any suite in the address is ignored
+func (index Index) Lookup(value string) (Parcel, bool) {
+	parcel := address.ParcelOf(value)
+	applications, found := index[parcel]
+	if !found {
+		return Parcel{}, false
+	}
+	return Parcel{Address: parcel, Applications: applications}, true
+}
+
+// Parcels - Lists every parcel, ordered by address
+func (index Index) Parcels() []Parcel {
+	parcels := make([]Parcel, 0, len(index))
+	for parcel, applications := range index {
+		parcels = append(parcels, Parcel{Address: parcel, Applications: applications})
+	}
+	sort.Slice(parcels, func(i, j int) bool {
+		return parcels[i].Address < parcels[j].Address
+	})
+	return parcels
+}
+
+// upsert - adds or replaces an application at a parcel, newest applied first, returns true if the parcel changed
+func (index Index) upsert(parcel string, application Application) bool {
+	applications := index[parcel]
+	for i := range applications {
+		if applications[i].Dataset == application.Dataset && applications[i].PermitNum == application.PermitNum {
+			if applications[i] == application {
+				return false
+			}
+			applications[i] = application
+			return true
+		}
+	}
+
+	applications = append(applications, application)
+	sort.SliceStable(applications, func(i, j int) bool {
+		if applications[i].AppliedDate != applications[j].AppliedDate {
+			return applications[i].AppliedDate > applications[j].AppliedDate
+		}
+		return applications[i].PermitNum < applications[j].PermitNum
+	})
+	index[parcel] = applications
+	return true
+}
+
+// recordParcels - the parcels a record covers, from its address when it lists none
+func recordParcels(record activity.Record) []string {
+	if len(record.Parcels) > 0 {
+		return record.Parcels
+	}
+	return address.Parcels(record.Address)
+}
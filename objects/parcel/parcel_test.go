@@ -0,0 +1,75 @@
+package parcel
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Add_GroupsByParcel(t *testing.T) {
+	index := Index{}
+	changed := index.Add([]activity.Record{
+		{Dataset: activity.RezoningApplication, PermitNum: "LOC2026-0009", Address: "2823 30 ST SW", Status: "Miscellaneous",
+			AppliedDate: time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00867", Address: "2823 30 st sw", Status: "Hold",
+			AppliedDate: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-01738", Address: "#A 3214 28 ST SW", Status: "New",
+			Parcels: []string{"3214 28 ST SW"}},
+	})
+	assert.Equal(t, 2, changed)
+
+	found, ok := index.Lookup("2823 30 ST SW")
+	require.True(t, ok)
+	assert.Equal(t, "2823 30 ST SW", found.Address)
+	require.Len(t, found.Applications, 2)
+	assert.Equal(t, "DP2026-00867", found.Applications[0].PermitNum)
+	assert.Equal(t, "2823 30 ST SW", found.Applications[0].Address)
+	assert.Equal(t, "2026-02-03", found.Applications[0].AppliedDate)
+	assert.Equal(t, "LOC2026-0009", found.Applications[1].PermitNum)
+
+	// Any unit finds the parcel
+	found, ok = index.Lookup("#1 3214 28 st sw")
+	require.True(t, ok)
+	assert.Equal(t, "#A 3214 28 ST SW", found.Applications[0].Address)
+
+	_, ok = index.Lookup("1 MAIN ST SW")
+	assert.False(t, ok)
+
+	assert.Equal(t, "2823 30 ST SW", index.Parcels()[0].Address)
+}
+
+func Test_Add_RefreshesAndKeeps(t *testing.T) {
+	index := Index{}
+	record := activity.Record{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00867", Address: "2823 30 ST SW", Status: "Hold"}
+	index.Add([]activity.Record{record})
+
+	assert.Equal(t, 0, index.Add([]activity.Record{record}))
+
+	record.Status = "Released"
+	assert.Equal(t, 1, index.Add([]activity.Record{record}))
+
+	// Applications that are no longer fetched stay at their parcel
+	index.Add(nil)
+	found, _ := index.Lookup("2823 30 ST SW")
+	require.Len(t, found.Applications, 1)
+	assert.Equal(t, "Released", found.Applications[0].Status)
+}
+
+func Test_LoadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "parcels.json")
+
+	index, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, index)
+
+	index.Add([]activity.Record{{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00867", Address: "2823 30 ST SW", Status: "Hold"}})
+	require.NoError(t, index.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, index, loaded)
+}
@@ -0,0 +1,165 @@
+package related
+
+import (
+	"math"
+	"sort"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/address"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/jeffadavidson/development-bot/utilities/sanitize"
+	"github.com/jeffadavidson/development-bot/utilities/templates"
+	"golang.org/x/exp/slices"
+)
+
+// Reasons an application is related to another
+const (
+	ReasonSameAddress = "same-address"
+	ReasonNearby      = "nearby"
+)
+
+// DefaultRadiusMeters - applications this close are treated as the same parcel even when their addresses differ
+const DefaultRadiusMeters = 15
+
+// Link - another application related to a record, stored with the record
+type Link struct {
+	// Dataset - development-permit or rezoning-application
+	Dataset   string `json:"dataset"`
+	PermitNum string `json:"permitnum"`
+	Address   string `json:"address,omitempty"`
+	Status    string `json:"status,omitempty"`
+	// Reason - same-address or nearby
+	Reason string `json:"reason"`
+	// DistanceMeters - how far apart the closest points of the two applications are, rounded to the metre
+	DistanceMeters float64 `json:"distance_m"`
+}
+
+// Find - Finds the candidates on the same parcel as the record or within the radius of any of its points
+func Find(record activity.Record, candidates []activity.Record, radiusMeters float64) []Link {
+	recordParcels := parcels(record)
+
+	var links []Link
+	for _, candidate := range candidates {
+		if candidate.Dataset == record.Dataset && candidate.PermitNum == record.PermitNum {
+			continue
+		}
+
+		distance, located := closestDistance(record, candidate)
+		link := Link{
+			Dataset:   candidate.Dataset,
+			PermitNum: candidate.PermitNum,
+			Address:   candidate.Address,
+			Status:    candidate.Status,
+		}
+		if located {
+			link.DistanceMeters = math.Round(distance)
+		}
+
+		switch {
+		case sharesParcel(recordParcels, parcels(candidate)):
+			link.Reason = ReasonSameAddress
+		case located && distance <= radiusMeters:
+			link.Reason = ReasonNearby
+		default:
+			continue
+		}
+		links = append(links, link)
+	}
+
+	// Same address first, then the closest, so the most likely match leads
+	sort.SliceStable(links, func(i, j int) bool {
+		if links[i].Reason != links[j].Reason {
+			return links[i].Reason == ReasonSameAddress
+		}
+		if links[i].DistanceMeters != links[j].DistanceMeters {
+			return links[i].DistanceMeters < links[j].DistanceMeters
+		}
+		return links[i].PermitNum < links[j].PermitNum
+	})
+
+	return links
+}
+
+// FindAll - Finds the related candidates of every record, by the record's permit number, records with none are left out
+func FindAll(records []activity.Record, candidates []activity.Record, radiusMeters float64) map[string][]Link {
+	links := make(map[string][]Link)
+	for _, record := range records {
+		if found := Find(record, candidates, radiusMeters); len(found) > 0 {
+			links[record.PermitNum] = found
+		}
+	}
+	return links
+}
+
+// Equal - Checks if two lists of links are the same
+func Equal(a []Link, b []Link) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TemplateItems - Describes related applications for the title, description and message templates
+func TemplateItems(language string, links []Link) []templates.RelatedItem {
+	var items []templates.RelatedItem
+	for _, link := range links {
+		item := templates.RelatedItem{
+			Title:   i18n.T(language, link.Dataset) + " " + link.PermitNum,
+			Address: link.Address,
+			Status:  link.Status,
+			Reason:  i18n.T(language, link.Reason),
+			Link:    sanitize.URL("https://developmentmap.calgary.ca/?find=" + sanitize.QueryValue(link.PermitNum)),
+		}
+		if link.Reason == ReasonNearby {
+			item.Reason = i18n.Tf(language, "distance-away", link.DistanceMeters)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// closestDistance - the distance between the closest points of two records, false if either has no location
+func closestDistance(a activity.Record, b activity.Record) (float64, bool) {
+	closest := math.Inf(1)
+	for _, pointA := range points(a) {
+		for _, pointB := range points(b) {
+			closest = math.Min(closest, geo.DistanceMeters(pointA, pointB))
+		}
+	}
+	return closest, !math.IsInf(closest, 1)
+}
+
+// points - every point a record covers, its single location when it has no others
+func points(record activity.Record) []geo.Point {
+	if len(record.Locations) > 0 {
+		return record.Locations
+	}
+	if record.Location != nil {
+		return []geo.Point{*record.Location}
+	}
+	return nil
+}
+
+// parcels - the parcels a record covers, from its address when it lists none
+func parcels(record activity.Record) []string {
+	if len(record.Parcels) > 0 {
+		return record.Parcels
+	}
+	return address.Parcels(record.Address)
+}
+
+// sharesParcel - true if any parcel is in both lists
+func sharesParcel(a []string, b []string) bool {
+	for _, parcel := range a {
+		if slices.Contains(b, parcel) {
+			return true
+		}
+	}
+	return false
+}
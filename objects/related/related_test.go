@@ -0,0 +1,95 @@
+package related
+
+import (
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+// Records from data/development-permits.json and data/rezoning-applications.json
+var (
+	loc20260009 = activity.Record{Dataset: activity.RezoningApplication, PermitNum: "LOC2026-0009", Address: "2823 30 ST SW", Status: "Miscellaneous",
+		Locations: []geo.Point{{Latitude: 51.02919670018869, Longitude: -114.13142364827681}}}
+	loc20260023 = activity.Record{Dataset: activity.RezoningApplication, PermitNum: "LOC2026-0023", Address: "3214 29 ST SW", Status: "Under Review",
+		Locations: []geo.Point{{Latitude: 51.02656816016038, Longitude: -114.12936552510145}}}
+	dp202600867 = activity.Record{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00867", Address: "2823 30 ST SW", Status: "Hold",
+		Location: &geo.Point{Latitude: 51.02919670018869, Longitude: -114.13142364827681}}
+	dp202600868 = activity.Record{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00868", Address: "2823 30 ST SW", Status: "Hold",
+		Location: &geo.Point{Latitude: 51.02919670018869, Longitude: -114.13142364827681}}
+	dp202601738 = activity.Record{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-01738", Address: "#A 3214 28 ST SW", Status: "New",
+		Location: &geo.Point{Latitude: 51.02616576239821, Longitude: -114.12780304757428}}
+)
+
+func Test_Find_IgnoresSuites(t *testing.T) {
+	rezoning := activity.Record{Dataset: activity.RezoningApplication, PermitNum: "LOC2026-0001", Address: "3214 28 st sw"}
+
+	links := Find(rezoning, []activity.Record{dp202601738}, DefaultRadiusMeters)
+	assert.Len(t, links, 1)
+	assert.Equal(t, ReasonSameAddress, links[0].Reason)
+
+	// A permit on several parcels is related to a rezoning of any of them
+	rezoning.Address = "1909 26A ST SW"
+	permit := activity.Record{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00002", Address: "#101 2719 17 AV SW",
+		Parcels: []string{"2719 17 AV SW", "1909 26A ST SW"}}
+	assert.Len(t, Find(rezoning, []activity.Record{permit}, DefaultRadiusMeters), 1)
+}
+
+func Test_Find_RealRecords(t *testing.T) {
+	permits := []activity.Record{dp202601738, dp202600868, dp202600867}
+
+	links := Find(loc20260009, permits, DefaultRadiusMeters)
+	assert.Equal(t, []Link{
+		{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00867", Address: "2823 30 ST SW", Status: "Hold", Reason: ReasonSameAddress},
+		{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00868", Address: "2823 30 ST SW", Status: "Hold", Reason: ReasonSameAddress},
+	}, links)
+
+	// A different street, over 100 m away
+	assert.Empty(t, Find(loc20260023, permits, DefaultRadiusMeters))
+
+	links = Find(dp202600867, []activity.Record{loc20260009, loc20260023}, DefaultRadiusMeters)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "LOC2026-0009", links[0].PermitNum)
+}
+
+func Test_Find_Nearby(t *testing.T) {
+	// A corner lot can be addressed on either street
+	corner := activity.Record{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00001", Address: "3001 26 AV SW",
+		Location: &geo.Point{Latitude: 51.02925, Longitude: -114.13142364827681}}
+
+	links := Find(loc20260009, []activity.Record{corner}, DefaultRadiusMeters)
+	assert.Len(t, links, 1)
+	assert.Equal(t, ReasonNearby, links[0].Reason)
+	assert.Equal(t, 6.0, links[0].DistanceMeters)
+
+	assert.Empty(t, Find(loc20260009, []activity.Record{corner}, 5))
+
+	// Without a location only the address can match
+	corner.Location = nil
+	assert.Empty(t, Find(loc20260009, []activity.Record{corner}, DefaultRadiusMeters))
+}
+
+func Test_Find_SkipsItself(t *testing.T) {
+	assert.Empty(t, Find(dp202600867, []activity.Record{dp202600867}, DefaultRadiusMeters))
+}
+
+func Test_FindAll(t *testing.T) {
+	links := FindAll([]activity.Record{loc20260009, loc20260023}, []activity.Record{dp202600867, dp202601738}, DefaultRadiusMeters)
+	assert.Len(t, links, 1)
+	assert.Len(t, links["LOC2026-0009"], 1)
+}
+
+func Test_TemplateItems(t *testing.T) {
+	items := TemplateItems("en", []Link{
+		{Dataset: activity.RezoningApplication, PermitNum: "LOC2026-0009", Address: "2823 30 ST SW", Status: "Miscellaneous", Reason: ReasonSameAddress},
+		{Dataset: activity.RezoningApplication, PermitNum: "LOC2026-0010", Reason: ReasonNearby, DistanceMeters: 12},
+	})
+	assert.Equal(t, "Rezoning Application LOC2026-0009", items[0].Title)
+	assert.Equal(t, "same address", items[0].Reason)
+	assert.Equal(t, "https://developmentmap.calgary.ca/?find=LOC2026-0009", items[0].Link)
+	assert.Equal(t, "12 m away", items[1].Reason)
+
+	items = TemplateItems("fr", []Link{{Dataset: activity.DevelopmentPermit, PermitNum: "DP2026-00867", Reason: ReasonNearby, DistanceMeters: 3}})
+	assert.Equal(t, "à 3 m", items[0].Reason)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -542,8 +543,8 @@ func TestGetMostRecentTimestamp_WithoutStateHistory(t *testing.T) {
 
 	mostRecent := ra.getMostRecentTimestamp()
 
-	// Should use the completed date as the most recent
-	expected, _ := time.Parse("2006-01-02T15:04:05.000", "2025-01-20T16:45:00.000")
+	// Should use the completed date as the most recent, upstream dates are Calgary local time
+	expected, _ := time.Parse(time.RFC3339, "2025-01-20T16:45:00-07:00")
 	assert.Equal(t, expected.Unix(), mostRecent.Unix())
 }
 
@@ -656,3 +657,111 @@ func TestGetRezoningApplicationUpdates_NoStateHistoryChange(t *testing.T) {
 	assert.False(t, hasUpdate, "Should not detect update when state history is identical")
 	assert.Empty(t, message, "Should have empty message when no changes")
 }
+
+func TestDescribeAction_SummarizesRezoning(t *testing.T) {
+	fromLud := "DC"
+	proposedLud := "H-GO"
+	address := "3214 29 ST SW"
+	ra := RezoningApplication{PermitNum: "LOC2026-0023", StatusCurrent: "Under Review", FromLud: &fromLud, ProposedLud: &proposedLud, Address: &address}
+
+	action := fileaction.FileAction{PermitNum: "LOC2026-0023", Action: "CREATE"}
+	ra.DescribeAction(&action)
+
+	assert.Equal(t, "🏛️ Rezoning Application: LOC2026-0023 - 3214 29 ST SW", action.Title)
+	assert.Equal(t, "https://developmentmap.calgary.ca/?find=LOC2026-0023", action.Link)
+	assert.Equal(t, "DC → H-GO", action.Record.Summary)
+	assert.Equal(t, "3214 29 ST SW", action.Record.Address)
+}
+
+func TestGenerateRSSDescription_EscapesHostileInput(t *testing.T) {
+	address := `3214 29 ST SW"><iframe src=//evil.example.com>`
+	applicant := "O'BRIEN & CO"
+	fromLud := "R-C1</li><li>Injected"
+	proposedLud := "H-GO <script>alert(1)</script>"
+	appliedDate := "2026-03-02T00:00:00.000"
+	ra := RezoningApplication{
+		PermitNum:     "LOC2026-0023",
+		StatusCurrent: "Approved <b>now</b>",
+		Address:       &address,
+		Applicant:     &applicant,
+		FromLud:       &fromLud,
+		ProposedLud:   &proposedLud,
+		AppliedDate:   &appliedDate,
+	}
+
+	rssDesc := ra.generateRSSDescription()
+
+	assert.NotContains(t, rssDesc, "<iframe")
+	assert.NotContains(t, rssDesc, "<script>")
+	assert.NotContains(t, rssDesc, "<b>")
+	assert.NotContains(t, rssDesc, "<li>Injected")
+	assert.Contains(t, rssDesc, "<li><strong>From:</strong> R-C1&lt;/li&gt;&lt;li&gt;Injected</li>")
+	assert.Contains(t, rssDesc, "<p>👤 <strong>Applicant:</strong> O&#39;BRIEN &amp; CO</p>")
+	assert.Contains(t, rssDesc, "<strong>✅ STATUS:</strong> Approved &lt;b&gt;now&lt;/b&gt;</div>")
+	assert.Contains(t, rssDesc, "<li>Applied: March 2, 2026</li>")
+	assert.Contains(t, rssDesc, "href='https://maps.google.com/?q=3214%2029%20ST%20SW%22%3E%3Ciframe%20src%3D%2F%2Fevil.example.com%3E%2C%20Calgary%2C%20Alberta'")
+}
+
+func TestCreateInformationMessage_EscapesMarkdown(t *testing.T) {
+	address := "3214 29 ST SW"
+	description := "Rezone to ![tracker](https://evil.example.com/pixel.png)"
+	proposedLud := "H-GO"
+	ra := RezoningApplication{
+		PermitNum:     "LOC2026-0023",
+		StatusCurrent: "Under Review",
+		Address:       &address,
+		Description:   &description,
+		ProposedLud:   &proposedLud,
+	}
+
+	message := ra.CreateInformationMessage()
+
+	assert.Contains(t, message, `**Description:** Rezone to !\[tracker\](https://evil.example.com/pixel.png)`)
+	assert.Contains(t, message, "**Proposed Land Use District:** H-GO\n**Permit Status:** Under Review\n")
+	assert.Contains(t, message, "[Development Map](https://developmentmap.calgary.ca/?find=LOC2026-0023)")
+}
+
+func TestNormalizeStateHistory(t *testing.T) {
+	applications := []RezoningApplication{{
+		PermitNum: "LOC2025-12345",
+		StateHistory: []StateChange{
+			{Status: "submitted", Timestamp: "2025-01-10T17:00:00Z"},
+			{Status: "under review", Timestamp: "2025-01-15T10:00:00-07:00"},
+		},
+	}}
+
+	assert.Equal(t, 1, normalizeStateHistory(applications))
+	assert.Equal(t, "2025-01-10T10:00:00-07:00", applications[0].StateHistory[0].Timestamp)
+	assert.Equal(t, "2025-01-15T10:00:00-07:00", applications[0].StateHistory[1].Timestamp)
+	assert.Equal(t, 0, normalizeStateHistory(applications))
+}
+
+func TestGenerateRSSDescription_ExplainsLandUseChange(t *testing.T) {
+	fromLud := "DC"
+	proposedLud := "MU-1 f3.0h16"
+	ra := RezoningApplication{PermitNum: "LOC2026-0023", StatusCurrent: "Under Review", FromLud: &fromLud, ProposedLud: &proposedLud}
+
+	rssDesc := ra.generateRSSDescription()
+
+	assert.Contains(t, rssDesc, "<li>District: Direct Control District → Mixed Use – General</li>")
+	assert.Contains(t, rssDesc, "<li>Maximum Height: set by the district → 16 m</li>")
+	assert.Contains(t, rssDesc, "<li>Maximum Floor Area Ratio: set by the district → 3.0</li>")
+	assert.Contains(t, rssDesc, "<p>ℹ️ <strong>MU-1 f3.0h16</strong> – Mixed Use – General (max height 16 m, max FAR 3.0): ")
+
+	message := ra.CreateInformationMessage()
+	assert.Contains(t, message, "## What Changes\n\n- **District:** Direct Control District → Mixed Use – General\n")
+}
+
+func TestParseRezoningApplications_ExplainsLandUse(t *testing.T) {
+	applications, err := parseRezoningApplications([]byte(`[{"permitnum": "LOC2026-0023", "fromlud": "DC (85Z2008)", "proposedlud": "H-GO"}]`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Direct Control District", applications[0].FromLandUse[0].Name)
+	assert.Equal(t, "85Z2008", applications[0].FromLandUse[0].Bylaw)
+	assert.Equal(t, 12.0, applications[0].ProposedLandUse[0].MaxHeight)
+	assert.Equal(t, "Housing – Grade Oriented", applications[0].ProposedLandUse[0].Name)
+	assert.Equal(t, []landuse.Change{
+		{Aspect: landuse.AspectDistrict, From: "Direct Control District", To: "Housing – Grade Oriented"},
+		{Aspect: landuse.AspectMaxHeight, From: "", To: "12 m"},
+	}, applications[0].LandUseChanges)
+}
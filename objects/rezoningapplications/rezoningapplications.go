@@ -1,18 +1,31 @@
 package rezoningapplications
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/jeffadavidson/development-bot/interactions/calgaryopendata"
 	"github.com/jeffadavidson/development-bot/interactions/rssfeed"
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/address"
+	"github.com/jeffadavidson/development-bot/objects/community"
 	"github.com/jeffadavidson/development-bot/objects/fileaction"
+	"github.com/jeffadavidson/development-bot/objects/landuse"
+	"github.com/jeffadavidson/development-bot/objects/related"
+	"github.com/jeffadavidson/development-bot/objects/selection"
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
+	"github.com/jeffadavidson/development-bot/utilities/config"
 	"github.com/jeffadavidson/development-bot/utilities/fileio"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/jeffadavidson/development-bot/utilities/sanitize"
+	"github.com/jeffadavidson/development-bot/utilities/templates"
 	"github.com/jeffadavidson/development-bot/utilities/toolbox"
 	"golang.org/x/exp/slices"
 )
@@ -33,7 +46,7 @@ func updateStateHistory(fetchedApp *RezoningApplication, storedApp *RezoningAppl
 
 	// Check if this is a new state we haven't seen before
 	currentStatus := strings.ToLower(strings.TrimSpace(fetchedApp.StatusCurrent))
-	timestamp := time.Now().Format(time.RFC3339)
+	timestamp := citytime.FormatTimestamp(citytime.Now())
 
 	// If this is the first time we're seeing this application, add initial state
 	if len(fetchedApp.StateHistory) == 0 {
@@ -54,6 +67,22 @@ func updateStateHistory(fetchedApp *RezoningApplication, storedApp *RezoningAppl
 	}
 }
 
+// normalizeStateHistory rewrites stored state history timestamps in the city's timezone
+// Older runs recorded them in the runner's zone, so history mixed Z and -06:00. Returns the number rewritten
+func normalizeStateHistory(permits []RezoningApplication) int {
+	normalized := 0
+	for i := range permits {
+		for j := range permits[i].StateHistory {
+			timestamp, ok := citytime.NormalizeTimestamp(permits[i].StateHistory[j].Timestamp)
+			if ok && timestamp != permits[i].StateHistory[j].Timestamp {
+				permits[i].StateHistory[j].Timestamp = timestamp
+				normalized++
+			}
+		}
+	}
+	return normalized
+}
+
 // GetStateHistorySummary returns a human-readable summary of the application's lifecycle
 func (ra RezoningApplication) GetStateHistorySummary() string {
 	if len(ra.StateHistory) == 0 {
@@ -62,7 +91,7 @@ func (ra RezoningApplication) GetStateHistorySummary() string {
 
 	summary := fmt.Sprintf("Application %s lifecycle:\n", ra.PermitNum)
 	for i, state := range ra.StateHistory {
-		timestamp, _ := time.Parse(time.RFC3339, state.Timestamp)
+		timestamp, _ := citytime.ParseTimestamp(state.Timestamp)
 		summary += fmt.Sprintf("  %d. %s - %s\n", i+1,
 			strings.Title(state.Status),
 			timestamp.Format("Jan 2, 2006 3:04 PM"))
@@ -88,6 +117,17 @@ type RezoningApplication struct {
 	Multipoint        Multipoint    `json:"multipoint"`
 	RSSGuid           string        `json:"rss_guid"`
 	StateHistory      []StateChange `json:"state_history"`
+	// FromLandUse, ProposedLandUse, LandUseChanges - the districts explained from the glossary and what the rezoning changes, filled in when applications are parsed
+	FromLandUse     []landuse.District `json:"from_land_use,omitempty"`
+	ProposedLandUse []landuse.District `json:"proposed_land_use,omitempty"`
+	LandUseChanges  []landuse.Change   `json:"land_use_changes,omitempty"`
+	// Addresses, Parcels - the address and location addresses normalized without duplicates, and the parcels they are on
+	Addresses []string `json:"addresses,omitempty"`
+	Parcels   []string `json:"parcels,omitempty"`
+	// Position - the community the item is in and where it is from the reference point, worked out when items are parsed
+	Position *community.Position `json:"position,omitempty"`
+	// Related - development permits at the same address or parcel, linked after each run
+	Related []related.Link `json:"related,omitempty"`
 }
 
 type StateChange struct {
@@ -100,165 +140,172 @@ type Multipoint struct {
 	Coordinates [][]float64 `json:"coordinates"`
 }
 
-// CreateInformationMessage - Builds an information message from the development permit
-func (ra RezoningApplication) CreateInformationMessage() string {
-	var lineSeparator string = "\n"
-	var message string = ""
-
-	// Markdown
-	message += "## About\n\n"
-	message += fmt.Sprintf("**Permit Number:** %v", ra.PermitNum)
-	if ra.AppliedDate != nil {
-		appliedDate, err := time.Parse("2006-01-02T15:04:05.000", *ra.AppliedDate)
-		if err != nil {
-			message += fmt.Sprintf("%v**Date Applied:** %v", lineSeparator, *ra.AppliedDate)
-		} else {
-			dateStr := appliedDate.Format("2006-01-02")
-			message += fmt.Sprintf("%v**Date Applied:** %v", lineSeparator, dateStr)
-		}
+// templateItem - collects the values the title, description and message templates show, an empty language uses the default
+func (ra RezoningApplication) templateItem(language string) templates.Item {
+	data := templates.Item{
+		Language:        language,
+		Dataset:         activity.RezoningApplication,
+		PermitNum:       ra.PermitNum,
+		Status:          ra.StatusCurrent,
+		Approved:        strings.Contains(strings.ToLower(ra.StatusCurrent), "approv"),
+		AppliedDate:     toolbox.StringValue(ra.AppliedDate),
+		Address:         toolbox.StringValue(ra.Address),
+		Applicant:       toolbox.StringValue(ra.Applicant),
+		Description:     toolbox.StringValue(ra.Description),
+		CurrentLandUse:  toolbox.StringValue(ra.FromLud),
+		ProposedLandUse: toolbox.StringValue(ra.ProposedLud),
+		Position:        community.TemplatePosition(language, ra.ActivityRecord().Position),
+		Related:         related.TemplateItems(language, ra.Related),
+		Links:           templates.Links{DevelopmentMap: ra.rssLink()},
 	}
-	if ra.Address != nil {
-		message += fmt.Sprintf("%v**Address:** %v", lineSeparator, *ra.Address)
-	}
-	if ra.Applicant != nil {
-		message += fmt.Sprintf("%v**Applicant:** %v", lineSeparator, *ra.Applicant)
+	data.CurrentDistricts = landuse.TemplateDistricts(language, data.CurrentLandUse)
+	data.ProposedDistricts = landuse.TemplateDistricts(language, data.ProposedLandUse)
+	if data.CurrentLandUse != "" && data.ProposedLandUse != "" {
+		data.LandUseChanges = landuse.TemplateChanges(language, data.CurrentLandUse, data.ProposedLandUse)
 	}
-	if ra.Description != nil {
-		message += fmt.Sprintf("%v**Description:** %v", lineSeparator, *ra.Description)
-	}
-	if ra.ProposedLud != nil {
-		message += fmt.Sprintf("%v**Proposed Land Use District:** %v", lineSeparator, *ra.ProposedLud)
+
+	if data.Address != "" {
+		data.Links.GoogleMaps = sanitize.URL("https://maps.google.com/?q=" + sanitize.QueryValue(data.Address+", Calgary, Alberta"))
 	}
-	if ra.FromLud != nil {
-		message += fmt.Sprintf("%v**Current Land Use District:** %v", lineSeparator, *ra.FromLud)
+
+	if appliedDate, err := citytime.ParseFloating(data.AppliedDate); err == nil {
+		data.AppliedDate = appliedDate.Format("2006-01-02")
+		data.Timeline = append(data.Timeline, templates.TimelineEntry{Label: i18n.T(language, "applied"), Date: i18n.FormatDate(language, appliedDate)})
 	}
-	if ra.StatusCurrent != "" {
-		message += fmt.Sprintf("%v**Permit Status:** %v", lineSeparator, ra.StatusCurrent)
+	if completedDate, err := citytime.ParseFloating(toolbox.StringValue(ra.CompletedDate)); err == nil {
+		data.Timeline = append(data.Timeline, templates.TimelineEntry{Label: i18n.T(language, "completed"), Date: i18n.FormatDate(language, completedDate)})
 	}
 
-	// Add google maps link
-	message += "\n## Links\n\n"
-	message += lineSeparator + lineSeparator
-	message += fmt.Sprintf("%v[Development Map](https://dmap.calgary.ca/?find=%v)", lineSeparator, ra.PermitNum)
-	if ra.Address != nil {
-		message += fmt.Sprintf("%v [Google Maps](https://maps.google.com/?q=%v)", lineSeparator, url.QueryEscape(fmt.Sprintf("%v, Calgary, Alberta", *ra.Address)))
-	}
-	message += lineSeparator
+	return data
+}
 
-	return message
+// CreateInformationMessage - Builds an information message from the rezoning application
+func (ra RezoningApplication) CreateInformationMessage() string {
+	return templates.Message(ra.templateItem(""))
 }
 
 // generateRSSDescription creates a self-contained HTML description for RSS feeds
 func (ra *RezoningApplication) generateRSSDescription() string {
-	var html strings.Builder
+	return templates.Description(ra.templateItem(""))
+}
 
-	// Header with application number and status
-	html.WriteString(fmt.Sprintf("<h3>🏛️ REZONING APPLICATION %s</h3>", ra.PermitNum))
-	html.WriteString(fmt.Sprintf("<p><strong>Status:</strong> %s</p>", ra.StatusCurrent))
+// rssTitle - a consistent title, without the status, so the feed item is updated in place as the rezoning application changes
+func (ra RezoningApplication) rssTitle() string {
+	return templates.Title(ra.templateItem(""))
+}
 
-	// Address and location details with map links
-	if ra.Address != nil {
-		html.WriteString(fmt.Sprintf("<p>📍 <strong>Address:</strong> %s</p>", *ra.Address))
+// rssLink - the rezoning application on the City of Calgary development map
+func (ra RezoningApplication) rssLink() string {
+	return sanitize.URL("https://developmentmap.calgary.ca/?find=" + sanitize.QueryValue(ra.PermitNum))
+}
 
-		// Map links right under address
-		html.WriteString("<ul style='margin-top: 5px; margin-bottom: 15px;'>")
-		googleMapsURL := fmt.Sprintf("https://maps.google.com/?q=%s", url.QueryEscape(fmt.Sprintf("%s, Calgary, Alberta", *ra.Address)))
-		html.WriteString(fmt.Sprintf("<li>📍 <a href='%s' target='_blank'>View on Google Maps</a></li>", googleMapsURL))
-		dmapURL := "https://developmentmap.calgary.ca/?find=" + ra.PermitNum
-		html.WriteString(fmt.Sprintf("<li>📋 <a href='%s' target='_blank'>View on Calgary Development Map</a></li>", dmapURL))
-		html.WriteString("</ul>")
-	}
+// DescribeAction - Fills in the details notifications need about the rezoning application an action applies to
+func (ra RezoningApplication) DescribeAction(action *fileaction.FileAction) {
+	action.Title = ra.rssTitle()
+	action.Link = ra.rssLink()
+	action.Description = ra.generateRSSDescription()
+	action.GUID = ra.RSSGuid
+	action.Record = ra.ActivityRecord()
+}
 
-	// Project details
-	if ra.Description != nil {
-		html.WriteString(fmt.Sprintf("<p>🏗️ <strong>Project:</strong> %s</p>", *ra.Description))
-	}
+// AddToRSSFeed - Adds the application to an RSS feed or refreshes its existing item, returns true if the feed changed
+func (ra *RezoningApplication) AddToRSSFeed(rss *rssfeed.RSS) bool {
+	// Use the most recent timestamp from application data
+	pubDate := ra.getMostRecentTimestamp()
 
-	// Land use change details
-	html.WriteString("<div style='background-color: #fff3cd; padding: 10px; margin: 10px 0; border-left: 4px solid #ffc107;'>")
-	html.WriteString("<h4>🏘️ LAND USE CHANGE:</h4>")
-	html.WriteString("<ul>")
+	// Render in the feed's language so each language's feed can be built from the same records
+	item := ra.templateItem(i18n.FromFeedLanguage(rss.Channel.Language))
+	title := templates.Title(item)
+	link := ra.rssLink()
 
-	if ra.FromLud != nil {
-		html.WriteString(fmt.Sprintf("<li><strong>From:</strong> %s</li>", *ra.FromLud))
+	// Enhanced RSS metadata
+	category := "Land Use Rezoning"
+	author := "Unknown"
+	if ra.Applicant != nil {
+		author = *ra.Applicant
 	}
+	source := "City of Calgary Open Data"
+	comments := link + "#comments"
 
-	if ra.ProposedLud != nil {
-		html.WriteString(fmt.Sprintf("<li><strong>To:</strong> %s</li>", *ra.ProposedLud))
-	}
-	html.WriteString("</ul></div>")
+	// Use full content in both description and content:encoded for maximum compatibility
+	fullContent := templates.Description(item)
+
+	return rss.UpdateItem(title, fullContent, link, ra.RSSGuid, pubDate, category, author, source, comments, fullContent)
+}
 
-	// Applicant information
+// ActivityRecord - Builds the dataset independent view of the application used for filtering
+func (ra RezoningApplication) ActivityRecord() activity.Record {
+	record := activity.Record{
+		Dataset:     activity.RezoningApplication,
+		PermitNum:   ra.PermitNum,
+		Status:      ra.StatusCurrent,
+		Category:    ra.PermitType,
+		LastUpdated: ra.lastRecordedTimestamp(),
+	}
+	if ra.Address != nil {
+		record.Address = *ra.Address
+	}
+	record.Parcels = address.Parcels(toolbox.StringValue(ra.Address), toolbox.StringValue(ra.LocationAddresses))
+	if ra.Description != nil {
+		record.Description = *ra.Description
+	}
 	if ra.Applicant != nil {
-		html.WriteString(fmt.Sprintf("<p>👤 <strong>Applicant:</strong> %s</p>", *ra.Applicant))
+		record.Applicant = *ra.Applicant
+	}
+	if ra.ProposedLud != nil {
+		record.ProposedLandUse = *ra.ProposedLud
+	}
+	record.CurrentDistricts = landuse.Explain(toolbox.StringValue(ra.FromLud))
+	record.ProposedDistricts = landuse.Explain(toolbox.StringValue(ra.ProposedLud))
+	if ra.FromLud != nil && ra.ProposedLud != nil {
+		record.Summary = fmt.Sprintf("%s → %s", *ra.FromLud, *ra.ProposedLud)
+	} else if ra.Description != nil {
+		record.Summary = *ra.Description
 	}
-
-	// Timeline information
-	html.WriteString("<h4>📅 TIMELINE:</h4><ul>")
-
 	if ra.AppliedDate != nil {
-		if parsedDate, err := time.Parse("2006-01-02T15:04:05.000", *ra.AppliedDate); err == nil {
-			html.WriteString(fmt.Sprintf("<li>Applied: %s</li>", parsedDate.Format("January 2, 2006")))
+		if appliedDate, err := citytime.ParseFloating(*ra.AppliedDate); err == nil {
+			record.AppliedDate = appliedDate
 		}
 	}
 
-	if ra.CompletedDate != nil && *ra.CompletedDate != "" {
-		if parsedDate, err := time.Parse("2006-01-02T15:04:05.000", *ra.CompletedDate); err == nil {
-			html.WriteString(fmt.Sprintf("<li>Completed: %s</li>", parsedDate.Format("January 2, 2006")))
+	if ra.Latitude != nil && ra.Longitude != nil {
+		if point, err := geo.ParsePoint(*ra.Latitude + "," + *ra.Longitude); err == nil {
+			record.Location = &point
 		}
+	} else if len(ra.Multipoint.Coordinates) > 0 && len(ra.Multipoint.Coordinates[0]) == 2 {
+		record.Location = &geo.Point{Latitude: ra.Multipoint.Coordinates[0][1], Longitude: ra.Multipoint.Coordinates[0][0]}
 	}
-	html.WriteString("</ul>")
-
-	// Status information
-	if ra.StatusCurrent != "" && strings.Contains(strings.ToLower(ra.StatusCurrent), "approv") {
-		html.WriteString("<div style='background-color: #d4edda; padding: 10px; margin: 10px 0; border-left: 4px solid #28a745;'>")
-		html.WriteString(fmt.Sprintf("<strong>✅ STATUS:</strong> %s", ra.StatusCurrent))
-		html.WriteString("</div>")
+	for _, coordinates := range ra.Multipoint.Coordinates {
+		if len(coordinates) == 2 {
+			record.Locations = append(record.Locations, geo.Point{Latitude: coordinates[1], Longitude: coordinates[0]})
+		}
+	}
+	if len(record.Locations) == 0 && record.Location != nil {
+		record.Locations = []geo.Point{*record.Location}
+	}
+	record.Position = community.Annotate(record.Location)
+	// The dataset has no community, so it comes from the built-in boundaries
+	if record.Position != nil {
+		record.CommunityCode = record.Position.CommunityCode
 	}
 
-	return html.String()
+	return record
 }
 
-// EvaluateRezoningApplications - Evaluates rezoning applications and generates RSS feed
-func EvaluateRezoningApplications(rss *rssfeed.RSS) ([]fileaction.FileAction, error) {
-	// Load rezoning applications
-	fetchedPermits, storedPermits, err := loadRezoningApplications()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load rezoning applications: %v", err)
-	}
+// EvaluateRezoningApplications - Evaluates fetched rezoning applications against the stored ones into the RSS feed, returns the actions taken
+// The fetched applications are not saved, so the caller saves them once the feed is written and a failed run is retried in full
+func EvaluateRezoningApplications(rss *rssfeed.RSS, fetchedPermits []RezoningApplication, storedPermits []RezoningApplication) []fileaction.FileAction {
 	fileActions := getRezoningApplicationActions(fetchedPermits, storedPermits)
 
 	// Process actions for Rezoning Applications
-	for _, val := range fileActions {
+	for i, val := range fileActions {
 		if val.Action == "CREATE" {
 			// Add new RSS item
 			ra := findRezoningApplicationByID(fetchedPermits, val.PermitNum)
 			if ra != nil {
-				// Use the most recent timestamp from application data
-				pubDate := ra.getMostRecentTimestamp()
-
-				// Generate consistent title without status
-				title := fmt.Sprintf("🏛️ Rezoning Application: %s", val.PermitNum)
-				if ra.Address != nil {
-					title = fmt.Sprintf("🏛️ Rezoning Application: %s - %s", val.PermitNum, *ra.Address)
-				}
-
-				link := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s", val.PermitNum)
-
-				// Enhanced RSS metadata
-				category := "Land Use Rezoning"
-				author := "Unknown"
-				if ra.Applicant != nil {
-					author = *ra.Applicant
-				}
-				source := "City of Calgary Open Data"
-				comments := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s#comments", val.PermitNum)
-
-				// Use full content in both description and content:encoded for maximum compatibility
-				fullContent := ra.generateRSSDescription()
-
-				// Only update RSS and print messages if actual changes were made
-				wasUpdated := rss.UpdateItem(title, fullContent, link, ra.RSSGuid, pubDate, category, author, source, comments, fullContent)
+				ra.DescribeAction(&fileActions[i])
+				// Only print messages if actual changes were made
+				wasUpdated := ra.AddToRSSFeed(rss)
 				if wasUpdated {
 					fmt.Printf("Rezoning Application %s:\n\tCreating RSS feed entry...\n", val.PermitNum)
 					fmt.Printf("\tCreated RSS feed entry!\n")
@@ -270,31 +317,12 @@ func EvaluateRezoningApplications(rss *rssfeed.RSS) ([]fileaction.FileAction, er
 			// Update existing RSS item
 			ra := findRezoningApplicationByID(fetchedPermits, val.PermitNum)
 			if ra != nil {
-				// Use the most recent timestamp from application data
-				pubDate := ra.getMostRecentTimestamp()
-
-				// Generate consistent title without status
-				title := fmt.Sprintf("🏛️ Rezoning Application: %s", val.PermitNum)
-				if ra.Address != nil {
-					title = fmt.Sprintf("🏛️ Rezoning Application: %s - %s", val.PermitNum, *ra.Address)
-				}
-
-				link := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s", val.PermitNum)
-
-				// Enhanced RSS metadata
-				category := "Land Use Rezoning"
-				author := "Unknown"
-				if ra.Applicant != nil {
-					author = *ra.Applicant
+				ra.DescribeAction(&fileActions[i])
+				if stored := findRezoningApplicationByID(storedPermits, val.PermitNum); stored != nil {
+					fileActions[i].PreviousStatus = stored.StatusCurrent
 				}
-				source := "City of Calgary Open Data"
-				comments := fmt.Sprintf("https://developmentmap.calgary.ca/?find=%s#comments", val.PermitNum)
-
-				// Use full content in both description and content:encoded for maximum compatibility
-				fullContent := ra.generateRSSDescription()
-
-				// Only update RSS and print messages if actual changes were made
-				wasUpdated := rss.UpdateItem(title, fullContent, link, ra.RSSGuid, pubDate, category, author, source, comments, fullContent)
+				// Only print messages if actual changes were made
+				wasUpdated := ra.AddToRSSFeed(rss)
 				if wasUpdated {
 					fmt.Printf("Rezoning Application %s:\n\tUpdating RSS feed entry...\n", val.PermitNum)
 					fmt.Printf("\tUpdated RSS feed entry!\n")
@@ -303,32 +331,59 @@ func EvaluateRezoningApplications(rss *rssfeed.RSS) ([]fileaction.FileAction, er
 		}
 	}
 
-	// Save Rezoning Applications (save the fetched data so we can compare next time)
-	saveRezoningApplications(fetchedPermits)
+	return fileActions
+}
+
+// SetRelatedApplications - Sets the related applications of each rezoning application by permit number and refreshes the feed items of applications whose links changed
+// Returns the number of applications whose links changed
+func SetRelatedApplications(applications []RezoningApplication, links map[string][]related.Link, rss *rssfeed.RSS) int {
+	changed := 0
+	for i := range applications {
+		if related.Equal(applications[i].Related, links[applications[i].PermitNum]) {
+			continue
+		}
+		applications[i].Related = links[applications[i].PermitNum]
+		changed++
+
+		// Only applications already in the feed are refreshed, linking alone does not add an item
+		if rss.FindItemByGUID(applications[i].RSSGuid) != nil {
+			applications[i].AddToRSSFeed(rss)
+		}
+	}
 
-	return fileActions, nil
+	return changed
 }
 
-// loadRezoningApplications - Loads existing rezoning applications and fetches new ones from Calgary Open Data
-func loadRezoningApplications() ([]RezoningApplication, []RezoningApplication, error) {
+// LoadRezoningApplications - Fetches rezoning applications from Calgary Open Data and loads the stored applications they are compared against
+// With updatedSince only the applications changed since then are fetched and the rest are kept as stored. Also returns the latest :updated_at fetched
+// Applications are ordered newest applied first, then by permit number, so runs add feed items in the same order whatever order they arrive in
+func LoadRezoningApplications(ctx context.Context, updatedSince string) ([]RezoningApplication, []RezoningApplication, string, error) {
 	// Load existing rezoning applications
-	storedPermitsBytes, loadErr := fileio.GetFileContents("./data/rezoning-applications.json")
-	if loadErr != nil {
-		return nil, nil, loadErr
-	}
-	storedPermits, parseErr := parseRezoningApplications(storedPermitsBytes)
+	storedPermits, parseErr := LoadStoredRezoningApplications()
 	if parseErr != nil {
-		return nil, nil, parseErr
+		return nil, nil, "", parseErr
+	}
+	// Saved with the fetched data at the end of the run, so this only rewrites anything once
+	if normalized := normalizeStateHistory(storedPermits); normalized > 0 {
+		fmt.Printf("Normalized %d stored state history timestamps to %s\n", normalized, citytime.Location())
 	}
 
 	// Get rezoning applications from Calgary Open Data
-	fetchedRezoningApplicationsRaw, fetchErr := calgaryopendata.GetRezoningApplications()
+	// The dataset has no community or ward columns, so communities are fetched by the box around them and narrowed here
+	selected := selection.FromConfig(config.Config.Neighborhood)
+	fetchedRezoningApplicationsRaw, fetchErr := calgaryopendata.GetRezoningApplications(ctx, selected.Where(false), updatedSince)
 	if fetchErr != nil {
-		return nil, nil, fetchErr
+		return nil, nil, "", fetchErr
 	}
-	fetchedRezoningApplications, parseErr2 := parseRezoningApplications(fetchedRezoningApplicationsRaw)
+	parsedRezoningApplications, parseErr2 := parseRezoningApplications(fetchedRezoningApplicationsRaw)
 	if parseErr2 != nil {
-		return nil, nil, parseErr
+		return nil, nil, "", parseErr2
+	}
+	var fetchedRezoningApplications []RezoningApplication
+	for _, application := range parsedRezoningApplications {
+		if selected.Matches(application.ActivityRecord()) {
+			fetchedRezoningApplications = append(fetchedRezoningApplications, application)
+		}
 	}
 
 	// Ensure all fetched permits have GUIDs (generate if new, preserve if existing)
@@ -342,15 +397,43 @@ func loadRezoningApplications() ([]RezoningApplication, []RezoningApplication, e
 			fetchedRezoningApplications[i].RSSGuid = generateUniqueGUID(fetchedRezoningApplications[i].PermitNum, "rezoning-application")
 		}
 
+		// Keep the links from the last run, they are refreshed once both datasets are loaded
+		if storedPermit != nil {
+			fetchedRezoningApplications[i].Related = storedPermit.Related
+		}
+
 		// Update state history if status changed
 		updateStateHistory(&fetchedRezoningApplications[i], storedPermit)
 	}
 
-	return fetchedRezoningApplications, storedPermits, nil
+	// Only changed applications were fetched, so the rest carry over as stored until the next full fetch
+	if updatedSince != "" {
+		fetchedRezoningApplications = mergeChangedRezoningApplications(storedPermits, fetchedRezoningApplications)
+	}
+
+	sort.SliceStable(fetchedRezoningApplications, func(i, j int) bool {
+		iApplied, jApplied := toolbox.StringValue(fetchedRezoningApplications[i].AppliedDate), toolbox.StringValue(fetchedRezoningApplications[j].AppliedDate)
+		if iApplied != jApplied {
+			return iApplied > jApplied
+		}
+		return fetchedRezoningApplications[i].PermitNum < fetchedRezoningApplications[j].PermitNum
+	})
+
+	return fetchedRezoningApplications, storedPermits, calgaryopendata.LatestUpdate(fetchedRezoningApplicationsRaw), nil
+}
+
+// LoadStoredRezoningApplications - Loads the rezoning applications saved by the last run
+func LoadStoredRezoningApplications() ([]RezoningApplication, error) {
+	storedPermitsBytes, loadErr := fileio.GetFileContents("./data/rezoning-applications.json")
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	return parseRezoningApplications(storedPermitsBytes)
 }
 
-// saveRezoningApplications - saves rezoning applications to file
-func saveRezoningApplications(applications []RezoningApplication) error {
+// SaveRezoningApplications - Saves the rezoning applications so the next run compares against them
+func SaveRezoningApplications(applications []RezoningApplication) error {
 	// Encode applications as JSON
 	applicationsBytes, encodeErr := json.MarshalIndent(applications, "", "  ")
 	if encodeErr != nil {
@@ -375,13 +458,21 @@ func findRezoningApplicationByID(searchSlice []RezoningApplication, id string) *
 	return &searchSlice[foundIndex]
 }
 
-// getMostRecentTimestamp finds the most recent timestamp from a rezoning application's data
+// getMostRecentTimestamp finds the most recent timestamp from a rezoning application's data, the current time when it has none
 func (ra *RezoningApplication) getMostRecentTimestamp() time.Time {
+	if mostRecent := ra.lastRecordedTimestamp(); !mostRecent.IsZero() {
+		return mostRecent
+	}
+	return citytime.Now()
+}
+
+// lastRecordedTimestamp - the most recent date or status change recorded for the application, zero when it has none
+func (ra *RezoningApplication) lastRecordedTimestamp() time.Time {
 	var mostRecent time.Time
 
 	// Check applied date
 	if ra.AppliedDate != nil {
-		if appliedDate, err := time.Parse("2006-01-02T15:04:05.000", *ra.AppliedDate); err == nil {
+		if appliedDate, err := citytime.ParseFloating(*ra.AppliedDate); err == nil {
 			if appliedDate.After(mostRecent) {
 				mostRecent = appliedDate
 			}
@@ -390,7 +481,7 @@ func (ra *RezoningApplication) getMostRecentTimestamp() time.Time {
 
 	// Check completed date
 	if ra.CompletedDate != nil {
-		if completedDate, err := time.Parse("2006-01-02T15:04:05.000", *ra.CompletedDate); err == nil {
+		if completedDate, err := citytime.ParseFloating(*ra.CompletedDate); err == nil {
 			if completedDate.After(mostRecent) {
 				mostRecent = completedDate
 			}
@@ -399,18 +490,13 @@ func (ra *RezoningApplication) getMostRecentTimestamp() time.Time {
 
 	// Check state history for most recent status change
 	for _, state := range ra.StateHistory {
-		if stateTime, err := time.Parse(time.RFC3339, state.Timestamp); err == nil {
+		if stateTime, err := citytime.ParseTimestamp(state.Timestamp); err == nil {
 			if stateTime.After(mostRecent) {
 				mostRecent = stateTime
 			}
 		}
 	}
 
-	// If no valid timestamp found, use current time
-	if mostRecent.IsZero() {
-		mostRecent = time.Now()
-	}
-
 	return mostRecent
 }
 
@@ -451,6 +537,15 @@ func getRezoningApplicationActions(fetchedRezoningApplications []RezoningApplica
 	return fileActions
 }
 
+// mergeChangedRezoningApplications - Copies the stored applications with the changed applications upserted into them, the stored applications are left as they were
+func mergeChangedRezoningApplications(stored []RezoningApplication, changed []RezoningApplication) []RezoningApplication {
+	merged := append([]RezoningApplication(nil), stored...)
+	for _, application := range changed {
+		merged = upsertRezoningApplication(merged, application)
+	}
+	return merged
+}
+
 // upsertRezoningApplication - updates or inserts a rezoning application to a list of applications
 func upsertRezoningApplication(applications []RezoningApplication, theApplication RezoningApplication) []RezoningApplication {
 	// Search the applications for the index of the application to add. If found update, if not append
@@ -471,6 +566,18 @@ func parseRezoningApplications(raw []byte) ([]RezoningApplication, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse rezoning application json. Error: %s", err.Error())
 	}
+	for i := range applications {
+		from := toolbox.StringValue(applications[i].FromLud)
+		proposed := toolbox.StringValue(applications[i].ProposedLud)
+		applications[i].FromLandUse = landuse.Explain(from)
+		applications[i].Addresses = address.NormalizeList(toolbox.StringValue(applications[i].Address), toolbox.StringValue(applications[i].LocationAddresses))
+		applications[i].Parcels = address.Parcels(applications[i].Addresses...)
+		applications[i].Position = applications[i].ActivityRecord().Position
+		applications[i].ProposedLandUse = landuse.Explain(proposed)
+		if from != "" && proposed != "" {
+			applications[i].LandUseChanges = landuse.Compare(from, proposed)
+		}
+	}
 	return applications, nil
 }
 
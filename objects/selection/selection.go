@@ -0,0 +1,106 @@
+package selection
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/community"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"golang.org/x/exp/slices"
+)
+
+// Selection - the items the bot follows, by bounding box and by community and ward names
+type Selection struct {
+	BoundingBox    config.BoundingBox
+	CommunityCodes []string
+	Wards          []string
+	// All - items must be in the box and in a listed community or ward, otherwise being in either is enough
+	All bool
+}
+
+// FromConfig - Builds the selection from the neighborhood settings
+func FromConfig(neighborhood config.Neighborhood) Selection {
+	return Selection{
+		BoundingBox:    neighborhood.BoundingBox,
+		CommunityCodes: neighborhood.CommunityCodes,
+		Wards:          neighborhood.Wards,
+		All:            neighborhood.Selection == "all",
+	}
+}
+
+// named - true if communities or wards are listed
+func (s Selection) named() bool {
+	return len(s.CommunityCodes) > 0 || len(s.Wards) > 0
+}
+
+// Where - Writes the selection as a SoQL condition. Datasets with communitycode and ward columns are selected by them,
+// others by the box around each listed community with a built-in boundary, falling back to the configured bounding box for
+// communities without one, and then narrowed with Matches
+func (s Selection) Where(hasCommunityColumns bool) string {
+	var names []string
+	if hasCommunityColumns {
+		if len(s.CommunityCodes) > 0 {
+			names = append(names, fmt.Sprintf("communitycode IN (%s)", quoteList(s.CommunityCodes)))
+		}
+		if len(s.Wards) > 0 {
+			names = append(names, fmt.Sprintf("ward IN (%s)", quoteList(s.Wards)))
+		}
+	} else {
+		for _, code := range s.CommunityCodes {
+			if found, ok := community.Lookup(code); ok {
+				southWest, northEast := found.Bounds()
+				names = append(names, "("+boxClause(config.BoundingBox{
+					NorthLatitude: northEast.Latitude,
+					EastLongitude: northEast.Longitude,
+					SouthLatitude: southWest.Latitude,
+					WestLongitude: southWest.Longitude,
+				})+")")
+			}
+		}
+	}
+
+	// Without names the box is all there is, an empty box selects nothing
+	if len(names) == 0 {
+		return boxClause(s.BoundingBox)
+	}
+	if s.BoundingBox.IsZero() {
+		return "(" + strings.Join(names, " OR ") + ")"
+	}
+	if s.All {
+		return boxClause(s.BoundingBox) + " AND (" + strings.Join(names, " OR ") + ")"
+	}
+	return "((" + boxClause(s.BoundingBox) + ") OR " + strings.Join(names, " OR ") + ")"
+}
+
+// Matches - Checks if a record is selected, used for datasets the query could only narrow down by box
+func (s Selection) Matches(record activity.Record) bool {
+	// The query already selected by box, so there is nothing left to check
+	if !s.named() {
+		return true
+	}
+
+	inNames := slices.Contains(s.CommunityCodes, record.CommunityCode) || (record.Ward != "" && slices.Contains(s.Wards, record.Ward))
+	inBox := !s.BoundingBox.IsZero() && record.Location != nil &&
+		record.Location.Latitude >= s.BoundingBox.SouthLatitude && record.Location.Latitude <= s.BoundingBox.NorthLatitude &&
+		record.Location.Longitude >= s.BoundingBox.WestLongitude && record.Location.Longitude <= s.BoundingBox.EastLongitude
+
+	if s.All {
+		return (s.BoundingBox.IsZero() || inBox) && inNames
+	}
+	return inBox || inNames
+}
+
+// boxClause - selects the rows inside a box, latitude and longitude are text columns so the bounds are compared as text
+func boxClause(box config.BoundingBox) string {
+	return fmt.Sprintf("latitude BETWEEN '%f' AND '%f' AND longitude BETWEEN '%f' AND '%f'", box.SouthLatitude, box.NorthLatitude, box.EastLongitude, box.WestLongitude)
+}
+
+// quoteList - writes values as a list of SoQL strings, doubling any quotes
+func quoteList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(value, "'", "''")+"'")
+	}
+	return strings.Join(quoted, ", ")
+}
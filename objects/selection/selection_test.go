@@ -0,0 +1,76 @@
+package selection
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jeffadavidson/development-bot/objects/activity"
+	"github.com/jeffadavidson/development-bot/objects/community"
+	"github.com/jeffadavidson/development-bot/utilities/config"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// killarneyBox - the bounding box in config.yaml
+var killarneyBox = config.BoundingBox{NorthLatitude: 51.038912, EastLongitude: -114.117927, SouthLatitude: 51.022361, WestLongitude: -114.142638}
+
+const killarneyClause = "latitude BETWEEN '51.022361' AND '51.038912' AND longitude BETWEEN '-114.117927' AND '-114.142638'"
+
+func Test_Where_BoxOnly(t *testing.T) {
+	selected := Selection{BoundingBox: killarneyBox}
+	assert.Equal(t, killarneyClause, selected.Where(true))
+	assert.Equal(t, killarneyClause, selected.Where(false))
+}
+
+func Test_Where_CommunityColumns(t *testing.T) {
+	selected := Selection{BoundingBox: killarneyBox, CommunityCodes: []string{"KIL", "RIC"}, Wards: []string{"8"}}
+	assert.Equal(t, "(("+killarneyClause+") OR communitycode IN ('KIL', 'RIC') OR ward IN ('8'))", selected.Where(true))
+
+	selected.All = true
+	assert.Equal(t, killarneyClause+" AND (communitycode IN ('KIL', 'RIC') OR ward IN ('8'))", selected.Where(true))
+
+	selected = Selection{CommunityCodes: []string{"O'BRIEN"}}
+	assert.Equal(t, "(communitycode IN ('O''BRIEN'))", selected.Where(true))
+}
+
+func Test_Where_CommunityBoundaries(t *testing.T) {
+	boundaries, err := os.ReadFile("../community/testdata/communities.geojson")
+	require.NoError(t, err)
+	require.NoError(t, community.SetBoundaries(boundaries))
+	t.Cleanup(community.ResetBoundaries)
+
+	// Rezonings have no community or ward columns, so communities are fetched by the box around their boundary and wards are left out
+	selected := Selection{CommunityCodes: []string{"RUT", "XYZ"}, Wards: []string{"8"}}
+	assert.Equal(t, "((latitude BETWEEN '51.021500' AND '51.027200' AND longitude BETWEEN '-114.133000' AND '-114.140500'))", selected.Where(false))
+
+	// Without a boundary for any listed community only the box can select anything
+	selected = Selection{BoundingBox: killarneyBox, CommunityCodes: []string{"XYZ"}}
+	assert.Equal(t, killarneyClause, selected.Where(false))
+}
+
+func Test_Matches(t *testing.T) {
+	inBox := activity.Record{PermitNum: "LOC2026-0009", CommunityCode: "KIL", Location: &geo.Point{Latitude: 51.03, Longitude: -114.13}}
+	inRutland := activity.Record{PermitNum: "LOC2026-0100", CommunityCode: "RUT", Location: &geo.Point{Latitude: 51.024, Longitude: -114.137}}
+	outside := activity.Record{PermitNum: "LOC2026-0200", CommunityCode: "GBK", Location: &geo.Point{Latitude: 51.025, Longitude: -114.15}}
+	inWard := activity.Record{PermitNum: "DP2026-00100", CommunityCode: "SCA", Ward: "8"}
+
+	// Only a box was configured, which the query already applied
+	assert.True(t, Selection{BoundingBox: killarneyBox}.Matches(outside))
+
+	anySelection := Selection{BoundingBox: killarneyBox, CommunityCodes: []string{"RUT"}, Wards: []string{"8"}}
+	assert.True(t, anySelection.Matches(inBox))
+	assert.True(t, anySelection.Matches(inRutland))
+	assert.True(t, anySelection.Matches(inWard))
+	assert.False(t, anySelection.Matches(outside))
+
+	allSelection := Selection{BoundingBox: killarneyBox, CommunityCodes: []string{"KIL"}, All: true}
+	assert.True(t, allSelection.Matches(inBox))
+	assert.False(t, allSelection.Matches(inRutland))
+	assert.False(t, allSelection.Matches(outside))
+}
+
+func Test_FromConfig(t *testing.T) {
+	selected := FromConfig(config.Neighborhood{BoundingBox: killarneyBox, CommunityCodes: []string{"KIL"}, Selection: "all"})
+	assert.Equal(t, Selection{BoundingBox: killarneyBox, CommunityCodes: []string{"KIL"}, All: true}, selected)
+}
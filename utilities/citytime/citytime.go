@@ -0,0 +1,101 @@
+package citytime
+
+import (
+	"fmt"
+	"time"
+
+	// Embed the timezone database so the city's zone loads on runners without one
+	_ "time/tzdata"
+)
+
+// DefaultTimezone - the City of Calgary's timezone
+const DefaultTimezone = "America/Edmonton"
+
+// floatingLayouts - Socrata floating timestamps have no zone and are in the city's local time
+var floatingLayouts = []string{"2006-01-02T15:04:05.000", "2006-01-02T15:04:05", "2006-01-02"}
+
+// location - the city's timezone, set from the config at startup
+var location = mustLoadLocation(DefaultTimezone)
+
+// now - the current time, replaced in tests
+var now = time.Now
+
+// LoadLocation - Loads a timezone by its IANA name, such as America/Edmonton
+func LoadLocation(name string) (*time.Location, error) {
+	loaded, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone '%s': %v", name, err)
+	}
+	return loaded, nil
+}
+
+// SetTimezone - Sets the city's timezone by its IANA name
+func SetTimezone(name string) error {
+	loaded, err := LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	location = loaded
+	return nil
+}
+
+// Location - Returns the city's timezone
+func Location() *time.Location {
+	return location
+}
+
+// Now - Returns the current time in the city's timezone
+func Now() time.Time {
+	return now().In(location)
+}
+
+// Freeze - Stops the clock at a time, so a replayed run sends the same queries as the run it was recorded from
+func Freeze(at time.Time) {
+	now = func() time.Time { return at }
+}
+
+// Unfreeze - Starts the clock again
+func Unfreeze() {
+	now = time.Now
+}
+
+// ParseFloating - Parses a Socrata timestamp without a zone, such as 2026-03-28T00:00:00.000, as city local time
+func ParseFloating(value string) (time.Time, error) {
+	for _, layout := range floatingLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp '%s'", value)
+}
+
+// ParseTimestamp - Parses a stored timestamp in RFC 3339 with a Z or offset, or a floating timestamp, returning it in the city's timezone
+func ParseTimestamp(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.In(location), nil
+	}
+	return ParseFloating(value)
+}
+
+// FormatTimestamp - Formats a time as RFC 3339 with the city's offset, the format state history is stored in
+func FormatTimestamp(t time.Time) string {
+	return t.In(location).Format(time.RFC3339)
+}
+
+// NormalizeTimestamp - Rewrites a stored timestamp in the city's offset, returns false if it could not be parsed
+func NormalizeTimestamp(value string) (string, bool) {
+	parsed, err := ParseTimestamp(value)
+	if err != nil {
+		return value, false
+	}
+	return FormatTimestamp(parsed), true
+}
+
+// mustLoadLocation - loads a timezone from the embedded database, which always has the default
+func mustLoadLocation(name string) *time.Location {
+	loaded, err := LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+}
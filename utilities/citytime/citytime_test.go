@@ -0,0 +1,80 @@
+package citytime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseFloating_UsesCityTime(t *testing.T) {
+	// Mountain Daylight Time in summer
+	parsed, err := ParseFloating("2025-07-28T22:18:50.000")
+	require.NoError(t, err)
+	assert.Equal(t, "2025-07-28T22:18:50-06:00", parsed.Format(time.RFC3339))
+
+	// Mountain Standard Time in winter
+	parsed, err = ParseFloating("2026-01-15T00:00:00.000")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-15T00:00:00-07:00", parsed.Format(time.RFC3339))
+
+	parsed, err = ParseFloating("2026-01-15")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-15T00:00:00-07:00", parsed.Format(time.RFC3339))
+
+	_, err = ParseFloating("January 15")
+	assert.ErrorContains(t, err, "invalid timestamp 'January 15'")
+}
+
+func Test_ParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"2025-08-07T12:46:34Z", "2025-08-07T06:46:34-06:00"},
+		{"2025-08-14T12:43:16-07:00", "2025-08-14T13:43:16-06:00"},
+		{"2025-08-21T12:40:04.000", "2025-08-21T12:40:04-06:00"},
+		// Just after midnight UTC is still the previous day in Calgary
+		{"2025-08-22T03:00:00Z", "2025-08-21T21:00:00-06:00"},
+	}
+
+	for _, test := range tests {
+		parsed, err := ParseTimestamp(test.value)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, parsed.Format(time.RFC3339), test.value)
+	}
+}
+
+func Test_NormalizeTimestamp(t *testing.T) {
+	normalized, ok := NormalizeTimestamp("2025-08-07T12:46:34Z")
+	assert.True(t, ok)
+	assert.Equal(t, "2025-08-07T06:46:34-06:00", normalized)
+
+	normalized, ok = NormalizeTimestamp("2025-08-07T06:46:34-06:00")
+	assert.True(t, ok)
+	assert.Equal(t, "2025-08-07T06:46:34-06:00", normalized)
+
+	normalized, ok = NormalizeTimestamp("not a time")
+	assert.False(t, ok)
+	assert.Equal(t, "not a time", normalized)
+}
+
+func Test_Now_IsInCityTime(t *testing.T) {
+	previous := now
+	t.Cleanup(func() { now = previous })
+	now = func() time.Time { return time.Date(2026, 3, 28, 5, 0, 0, 0, time.UTC) }
+
+	assert.Equal(t, "2026-03-27T23:00:00-06:00", FormatTimestamp(Now()))
+}
+
+func Test_SetTimezone(t *testing.T) {
+	t.Cleanup(func() { location = mustLoadLocation(DefaultTimezone) })
+
+	require.NoError(t, SetTimezone("America/Toronto"))
+	parsed, err := ParseFloating("2026-01-15T00:00:00.000")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-15T00:00:00-05:00", parsed.Format(time.RFC3339))
+
+	assert.ErrorContains(t, SetTimezone("Mars/Olympus_Mons"), "unknown timezone 'Mars/Olympus_Mons'")
+}
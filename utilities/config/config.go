@@ -2,21 +2,57 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jeffadavidson/development-bot/utilities/citytime"
 	"github.com/jeffadavidson/development-bot/utilities/fileio"
+	"github.com/jeffadavidson/development-bot/utilities/geo"
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
 
 	"gopkg.in/yaml.v3"
 )
 
 var configFilePath string = "config.yaml"
 
+// communityCodePattern - City of Calgary community codes, such as KIL and 01B
+var communityCodePattern = regexp.MustCompile(`^[A-Z0-9]+$`)
+
 type DevBot struct {
-	Neighborhood Neighborhood `yaml:"neighborhood"`
+	Neighborhood  Neighborhood  `yaml:"neighborhood"`
+	Server        Server        `yaml:"server"`
+	WebSub        WebSub        `yaml:"websub"`
+	Notifications Notifications `yaml:"notifications"`
+	AlertRules    []AlertRule   `yaml:"alert-rules"`
+	Templates     Templates     `yaml:"templates"`
+	HTTP          HTTP          `yaml:"http"`
+	Fetch         Fetch         `yaml:"fetch"`
+	OpenData      OpenData      `yaml:"open-data"`
+	// Language - the language of the combined feed and notifications
+	Language string `yaml:"language"`
+	// FeedLanguages - other languages to write a copy of the combined feed in
+	FeedLanguages []string `yaml:"feed-languages"`
+	// Timezone - the city's IANA timezone, upstream dates without a zone are read in it and history is recorded in it
+	Timezone string `yaml:"timezone"`
 }
 
 type Neighborhood struct {
 	Name        string      `yaml:"name"`
 	BoundingBox BoundingBox `yaml:"bounding-box"`
+	// Community - the code of the home community, items are labeled as in it, adjacent to it or outside it
+	Community string `yaml:"community"`
+	// ReferencePoint, ReferenceName - a "latitude,longitude" point, such as the community centre, that items are measured from
+	ReferencePoint string `yaml:"reference-point"`
+	ReferenceName  string `yaml:"reference-name"`
+	// CommunityCodes, Wards - communities and wards to follow by name, alongside or instead of the bounding box
+	CommunityCodes []string `yaml:"community-codes"`
+	Wards          []string `yaml:"wards"`
+	// Selection - any to follow items in the box or a listed community or ward, all to follow only items in both
+	Selection string `yaml:"selection"`
 }
 
 type BoundingBox struct {
@@ -26,6 +62,129 @@ type BoundingBox struct {
 	WestLongitude float64 `yaml:"west-longitude"`
 }
 
+// Server - settings used when the bot runs as a long lived HTTP server
+type Server struct {
+	Address         string        `yaml:"address"`
+	RefreshInterval time.Duration `yaml:"refresh-interval"`
+}
+
+// WebSub - hub advertised in the feed so readers are pushed updates instead of polling
+type WebSub struct {
+	HubURL     string `yaml:"hub-url"`
+	SelfURL    string `yaml:"self-url"`
+	BuiltinHub bool   `yaml:"builtin-hub"`
+}
+
+// HTTP - how requests to Calgary Open Data are timed out, retried and paced
+type HTTP struct {
+	Timeout time.Duration `yaml:"timeout"`
+	// Retries - how many more times a fetch is tried after a network error, 429 or 5xx, 3 when left out and 0 to never retry
+	Retries        *int          `yaml:"retries"`
+	InitialBackoff time.Duration `yaml:"initial-backoff"`
+	MaxBackoff     time.Duration `yaml:"max-backoff"`
+	// RequestsPerSecond - the most requests sent to one host each second, 0 for no limit
+	RequestsPerSecond float64 `yaml:"requests-per-second"`
+	// Concurrency - how many datasets are fetched at once
+	Concurrency int `yaml:"concurrency"`
+}
+
+// Fetch - how much of each dataset is fetched each run
+type Fetch struct {
+	// Incremental - fetch only the rows changed since the last successful run, using Socrata's :updated_at
+	Incremental bool `yaml:"incremental"`
+	// FullEvery - how often the whole window is fetched again, which catches rows that were deleted or left the window
+	FullEvery time.Duration `yaml:"full-every"`
+}
+
+// OpenData - where the development permit and rezoning application datasets are fetched from
+type OpenData struct {
+	// BaseURL - the Socrata site, such as https://data.calgary.ca or a local fake for tests and demos
+	BaseURL string `yaml:"base-url"`
+}
+
+// Templates - a directory of templates that replace the built-in templates with the same file names
+type Templates struct {
+	Directory string `yaml:"directory"`
+}
+
+// Notifications - channels that tell people about new and changed activity after each run
+type Notifications struct {
+	Email    Email     `yaml:"email"`
+	Webhooks []Webhook `yaml:"webhooks"`
+	Mastodon Mastodon  `yaml:"mastodon"`
+	Bluesky  Bluesky   `yaml:"bluesky"`
+}
+
+// Email - SMTP server and subscribers for the email digest, credentials may reference environment variables like ${SMTP_PASSWORD}
+type Email struct {
+	Enabled     bool     `yaml:"enabled"`
+	SMTPHost    string   `yaml:"smtp-host"`
+	SMTPPort    int      `yaml:"smtp-port"`
+	Username    string   `yaml:"username"`
+	Password    string   `yaml:"password"`
+	From        string   `yaml:"from"`
+	Subscribers []string `yaml:"subscribers"`
+	Digest      string   `yaml:"digest"`
+}
+
+// Webhook - an endpoint that is POSTed each action, formatted for Slack, Discord or as raw JSON
+type Webhook struct {
+	Name      string `yaml:"name"`
+	URL       string `yaml:"url"`
+	Format    string `yaml:"format"`
+	Secret    string `yaml:"secret"`
+	MaxPerRun int    `yaml:"max-per-run"`
+	// Retries - how many more times a post is tried after a network error, 429 or 5xx, 3 when left out and 0 to never retry
+	Retries *int `yaml:"retries"`
+}
+
+// Mastodon - account that posts a status for each new or decided item
+type Mastodon struct {
+	Enabled       bool   `yaml:"enabled"`
+	InstanceURL   string `yaml:"instance-url"`
+	AccessToken   string `yaml:"access-token"`
+	Visibility    string `yaml:"visibility"`
+	MaxPerRun     int    `yaml:"max-per-run"`
+	MaxCharacters int    `yaml:"max-characters"`
+}
+
+// Bluesky - account that posts for each new or decided item
+type Bluesky struct {
+	Enabled     bool   `yaml:"enabled"`
+	ServiceURL  string `yaml:"service-url"`
+	Handle      string `yaml:"handle"`
+	AppPassword string `yaml:"app-password"`
+	MaxPerRun   int    `yaml:"max-per-run"`
+}
+
+// IsZero - Checks if the bounding box was left out of the config file
+func (b BoundingBox) IsZero() bool {
+	return b == BoundingBox{}
+}
+
+// AlertRule - sends actions that match every given criteria to named notifiers and a feed of their own
+type AlertRule struct {
+	Name   string     `yaml:"name"`
+	Match  AlertMatch `yaml:"match"`
+	Notify []string   `yaml:"notify"`
+	Feed   string     `yaml:"feed"`
+}
+
+// AlertMatch - the criteria of an alert rule, a list matches when any of its values match
+type AlertMatch struct {
+	Datasets          []string `yaml:"datasets"`
+	Actions           []string `yaml:"actions"`
+	Categories        []string `yaml:"categories"`
+	StatusFrom        []string `yaml:"status-from"`
+	StatusTo          []string `yaml:"status-to"`
+	ProposedLandUse   []string `yaml:"proposed-lud"`
+	Increases         []string `yaml:"increases"`
+	ApplicantContains []string `yaml:"applicant-contains"`
+	Keywords          []string `yaml:"keywords"`
+	Near              string   `yaml:"near"`
+	Radius            string   `yaml:"radius"`
+}
+
 var Config DevBot
 
 func ManualInit() error {
@@ -58,5 +217,191 @@ func parseConfig(configBytes []byte) error {
 		return fmt.Errorf("error unmarshalling YAML data: %v", err)
 	}
 
+	applyDefaults(&Config)
+	expandEnvironment(&Config)
+
+	return validate(Config)
+}
+
+// applyDefaults - fills in optional settings that were left out of the config file
+func applyDefaults(devBot *DevBot) {
+	if devBot.Language == "" {
+		devBot.Language = "en"
+	}
+	if devBot.Timezone == "" {
+		devBot.Timezone = citytime.DefaultTimezone
+	}
+	if devBot.Neighborhood.Selection == "" {
+		devBot.Neighborhood.Selection = "any"
+	}
+	for i, code := range devBot.Neighborhood.CommunityCodes {
+		devBot.Neighborhood.CommunityCodes[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+	for i, ward := range devBot.Neighborhood.Wards {
+		devBot.Neighborhood.Wards[i] = strings.TrimSpace(ward)
+	}
+	if devBot.Server.Address == "" {
+		devBot.Server.Address = ":8080"
+	}
+	if devBot.Server.RefreshInterval <= 0 {
+		devBot.Server.RefreshInterval = time.Hour
+	}
+	if devBot.HTTP.Timeout <= 0 {
+		devBot.HTTP.Timeout = 20 * time.Second
+	}
+	if devBot.HTTP.Retries == nil {
+		devBot.HTTP.Retries = intPointer(3)
+	}
+	if devBot.HTTP.InitialBackoff <= 0 {
+		devBot.HTTP.InitialBackoff = time.Second
+	}
+	if devBot.HTTP.MaxBackoff <= 0 {
+		devBot.HTTP.MaxBackoff = 30 * time.Second
+	}
+	if devBot.HTTP.Concurrency <= 0 {
+		devBot.HTTP.Concurrency = 2
+	}
+	if devBot.Fetch.FullEvery <= 0 {
+		devBot.Fetch.FullEvery = 24 * time.Hour
+	}
+	if devBot.OpenData.BaseURL == "" {
+		devBot.OpenData.BaseURL = "https://data.calgary.ca"
+	}
+	devBot.OpenData.BaseURL = strings.TrimRight(devBot.OpenData.BaseURL, "/")
+	if devBot.Notifications.Email.SMTPPort == 0 {
+		devBot.Notifications.Email.SMTPPort = 587
+	}
+	if devBot.Notifications.Email.Digest == "" {
+		devBot.Notifications.Email.Digest = "daily"
+	}
+	if devBot.Notifications.Mastodon.Visibility == "" {
+		devBot.Notifications.Mastodon.Visibility = "public"
+	}
+	if devBot.Notifications.Mastodon.MaxPerRun <= 0 {
+		devBot.Notifications.Mastodon.MaxPerRun = 5
+	}
+	if devBot.Notifications.Mastodon.MaxCharacters <= 0 {
+		devBot.Notifications.Mastodon.MaxCharacters = 500
+	}
+	if devBot.Notifications.Bluesky.ServiceURL == "" {
+		devBot.Notifications.Bluesky.ServiceURL = "https://bsky.social"
+	}
+	if devBot.Notifications.Bluesky.MaxPerRun <= 0 {
+		devBot.Notifications.Bluesky.MaxPerRun = 5
+	}
+	for i := range devBot.Notifications.Webhooks {
+		webhook := &devBot.Notifications.Webhooks[i]
+		if webhook.Format == "" {
+			webhook.Format = "json"
+		}
+		if webhook.MaxPerRun <= 0 {
+			webhook.MaxPerRun = 20
+		}
+		if webhook.Retries == nil {
+			webhook.Retries = intPointer(3)
+		}
+	}
+}
+
+// intPointer - points to a default for a setting that can be set to 0
+func intPointer(value int) *int {
+	return &value
+}
+
+// expandEnvironment - replaces ${VAR} references in secrets so they can be kept out of the config file
+func expandEnvironment(devBot *DevBot) {
+	devBot.Notifications.Email.Username = os.ExpandEnv(devBot.Notifications.Email.Username)
+	devBot.Notifications.Email.Password = os.ExpandEnv(devBot.Notifications.Email.Password)
+	devBot.Notifications.Mastodon.AccessToken = os.ExpandEnv(devBot.Notifications.Mastodon.AccessToken)
+	devBot.Notifications.Bluesky.AppPassword = os.ExpandEnv(devBot.Notifications.Bluesky.AppPassword)
+	for i := range devBot.Notifications.Webhooks {
+		devBot.Notifications.Webhooks[i].URL = os.ExpandEnv(devBot.Notifications.Webhooks[i].URL)
+		devBot.Notifications.Webhooks[i].Secret = os.ExpandEnv(devBot.Notifications.Webhooks[i].Secret)
+	}
+}
+
+// validate - checks settings that cannot be defaulted
+func validate(devBot DevBot) error {
+	if !i18n.IsSupported(devBot.Language) {
+		return fmt.Errorf("language must be one of %s, got %q", strings.Join(i18n.Supported(), ", "), devBot.Language)
+	}
+	for _, language := range devBot.FeedLanguages {
+		if !i18n.IsSupported(language) {
+			return fmt.Errorf("feed language must be one of %s, got %q", strings.Join(i18n.Supported(), ", "), language)
+		}
+		if language == devBot.Language {
+			return fmt.Errorf("feed language %q is already the language of the combined feed", language)
+		}
+	}
+	if _, err := citytime.LoadLocation(devBot.Timezone); err != nil {
+		return err
+	}
+	if devBot.Neighborhood.ReferencePoint != "" {
+		if _, err := geo.ParsePoint(devBot.Neighborhood.ReferencePoint); err != nil {
+			return fmt.Errorf("neighborhood reference-point: %v", err)
+		}
+	}
+	if err := validateSelection(devBot.Neighborhood); err != nil {
+		return err
+	}
+	if devBot.HTTP.MaxBackoff < devBot.HTTP.InitialBackoff {
+		return fmt.Errorf("http max-backoff %s is shorter than initial-backoff %s", devBot.HTTP.MaxBackoff, devBot.HTTP.InitialBackoff)
+	}
+	if *devBot.HTTP.Retries < 0 {
+		return fmt.Errorf("http retries cannot be negative, got %d", *devBot.HTTP.Retries)
+	}
+	if devBot.HTTP.RequestsPerSecond < 0 {
+		return fmt.Errorf("http requests-per-second cannot be negative, got %g", devBot.HTTP.RequestsPerSecond)
+	}
+	if baseURL, err := url.Parse(devBot.OpenData.BaseURL); err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return fmt.Errorf("open-data base-url must be an http or https URL, got %q", devBot.OpenData.BaseURL)
+	}
+	email := devBot.Notifications.Email
+	if email.Digest != "daily" && email.Digest != "weekly" {
+		return fmt.Errorf("email digest must be daily or weekly, got %q", email.Digest)
+	}
+	if email.Enabled && (email.SMTPHost == "" || email.From == "" || len(email.Subscribers) == 0) {
+		return fmt.Errorf("email notifications need an smtp-host, from address and at least one subscriber")
+	}
+	if devBot.Notifications.Mastodon.Enabled && (devBot.Notifications.Mastodon.InstanceURL == "" || devBot.Notifications.Mastodon.AccessToken == "") {
+		return fmt.Errorf("mastodon posting needs an instance-url and access-token")
+	}
+	if devBot.Notifications.Bluesky.Enabled && (devBot.Notifications.Bluesky.Handle == "" || devBot.Notifications.Bluesky.AppPassword == "") {
+		return fmt.Errorf("bluesky posting needs a handle and app-password")
+	}
+	for i, webhook := range devBot.Notifications.Webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("webhook %d (%s) needs a url", i+1, webhook.Name)
+		}
+		if webhook.Format != "slack" && webhook.Format != "discord" && webhook.Format != "json" {
+			return fmt.Errorf("webhook %d (%s) format must be slack, discord or json, got %q", i+1, webhook.Name, webhook.Format)
+		}
+		if *webhook.Retries < 0 {
+			return fmt.Errorf("webhook %d (%s) retries cannot be negative, got %d", i+1, webhook.Name, *webhook.Retries)
+		}
+	}
+
+	return nil
+}
+
+// validateSelection - checks the communities and wards can be written into a query and that rezonings can be selected
+func validateSelection(neighborhood Neighborhood) error {
+	if neighborhood.Selection != "any" && neighborhood.Selection != "all" {
+		return fmt.Errorf("neighborhood selection must be any or all, got %q", neighborhood.Selection)
+	}
+	for _, code := range neighborhood.CommunityCodes {
+		if !communityCodePattern.MatchString(code) {
+			return fmt.Errorf("neighborhood community code must be letters and digits, such as KIL, got %q", code)
+		}
+	}
+	for _, ward := range neighborhood.Wards {
+		if number, err := strconv.Atoi(ward); err != nil || number < 1 || number > 14 {
+			return fmt.Errorf("neighborhood ward must be a number from 1 to 14, got %q", ward)
+		}
+	}
+	// Rezoning applications have no ward, so wards alone would never select one
+	if len(neighborhood.Wards) > 0 && len(neighborhood.CommunityCodes) == 0 && neighborhood.BoundingBox.IsZero() {
+		return fmt.Errorf("neighborhood wards need a bounding-box or community-codes as well, rezoning applications have no ward")
+	}
 	return nil
 }
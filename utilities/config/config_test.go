@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -48,3 +49,248 @@ func Test_PaseConfig_InvalidLatitude(t *testing.T) {
 	err := parseConfig(configYaml)
 	assert.NotEqual(t, nil, err)
 }
+
+func Test_ParseConfig_ServerDefaults(t *testing.T) {
+	Config = DevBot{}
+	configYaml := []byte(`
+  neighborhood:
+    name: Killarney
+`)
+
+	err := parseConfig(configYaml)
+	assert.NoError(t, err)
+	assert.Equal(t, ":8080", Config.Server.Address)
+	assert.Equal(t, time.Hour, Config.Server.RefreshInterval)
+}
+
+func Test_ParseConfig_ServerSettings(t *testing.T) {
+	Config = DevBot{}
+	configYaml := []byte(`
+  neighborhood:
+    name: Killarney
+  server:
+    address: "127.0.0.1:9000"
+    refresh-interval: 15m
+`)
+
+	err := parseConfig(configYaml)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9000", Config.Server.Address)
+	assert.Equal(t, 15*time.Minute, Config.Server.RefreshInterval)
+}
+
+func Test_ParseConfig_EmailDigest(t *testing.T) {
+	Config = DevBot{}
+	t.Setenv("DEVBOT_TEST_SMTP_PASSWORD", "hunter2")
+	configYaml := []byte(`
+  notifications:
+    email:
+      enabled: true
+      smtp-host: smtp.example.com
+      username: bot@example.com
+      password: ${DEVBOT_TEST_SMTP_PASSWORD}
+      from: bot@example.com
+      subscribers:
+        - resident@example.com
+      digest: weekly
+`)
+
+	err := parseConfig(configYaml)
+	assert.NoError(t, err)
+	assert.Equal(t, 587, Config.Notifications.Email.SMTPPort)
+	assert.Equal(t, "hunter2", Config.Notifications.Email.Password)
+	assert.Equal(t, "weekly", Config.Notifications.Email.Digest)
+}
+
+func Test_ParseConfig_InvalidEmailDigest(t *testing.T) {
+	Config = DevBot{}
+	configYaml := []byte(`
+  notifications:
+    email:
+      digest: hourly
+`)
+
+	err := parseConfig(configYaml)
+	assert.ErrorContains(t, err, "email digest must be daily or weekly")
+}
+
+func Test_ParseConfig_EmailMissingSubscribers(t *testing.T) {
+	Config = DevBot{}
+	configYaml := []byte(`
+  notifications:
+    email:
+      enabled: true
+      smtp-host: smtp.example.com
+      from: bot@example.com
+`)
+
+	err := parseConfig(configYaml)
+	assert.Error(t, err)
+}
+
+func Test_ParseConfig_Webhooks(t *testing.T) {
+	Config = DevBot{}
+	t.Setenv("DEVBOT_TEST_SLACK_URL", "https://hooks.slack.com/services/T000/B000/XXXX")
+	configYaml := []byte(`
+  notifications:
+    webhooks:
+      - name: board
+        url: ${DEVBOT_TEST_SLACK_URL}
+        format: slack
+      - name: archive
+        url: https://example.com/hook
+        max-per-run: 5
+        retries: 1
+`)
+
+	err := parseConfig(configYaml)
+	assert.NoError(t, err)
+	assert.Len(t, Config.Notifications.Webhooks, 2)
+	assert.Equal(t, "https://hooks.slack.com/services/T000/B000/XXXX", Config.Notifications.Webhooks[0].URL)
+	assert.Equal(t, 20, Config.Notifications.Webhooks[0].MaxPerRun)
+	assert.Equal(t, 3, *Config.Notifications.Webhooks[0].Retries)
+	assert.Equal(t, "json", Config.Notifications.Webhooks[1].Format)
+	assert.Equal(t, 5, Config.Notifications.Webhooks[1].MaxPerRun)
+	assert.Equal(t, 1, *Config.Notifications.Webhooks[1].Retries)
+
+	// Retries can be turned off, but not made negative
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("notifications:\n  webhooks:\n    - url: https://example.com/hook\n      retries: 0\n")))
+	assert.Equal(t, 0, *Config.Notifications.Webhooks[0].Retries)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("notifications:\n  webhooks:\n    - url: https://example.com/hook\n      retries: -1\n")), "retries cannot be negative")
+}
+
+func Test_ParseConfig_InvalidWebhookFormat(t *testing.T) {
+	Config = DevBot{}
+	configYaml := []byte(`
+  notifications:
+    webhooks:
+      - name: board
+        url: https://example.com/hook
+        format: teams
+`)
+
+	err := parseConfig(configYaml)
+	assert.ErrorContains(t, err, "format must be slack, discord or json")
+}
+
+func Test_ParseConfig_Languages(t *testing.T) {
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte(`{}`)))
+	assert.Equal(t, "en", Config.Language)
+
+	Config = DevBot{}
+	configYaml := []byte(`
+  language: fr
+  feed-languages: [en]
+`)
+	assert.NoError(t, parseConfig(configYaml))
+	assert.Equal(t, "fr", Config.Language)
+	assert.Equal(t, []string{"en"}, Config.FeedLanguages)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte(`language: de`)), `language must be one of en, fr, got "de"`)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte(`feed-languages: [en]`)), `feed language "en" is already the language of the combined feed`)
+}
+
+func Test_ParseConfig_Timezone(t *testing.T) {
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte(`{}`)))
+	assert.Equal(t, "America/Edmonton", Config.Timezone)
+
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte(`timezone: America/Vancouver`)))
+	assert.Equal(t, "America/Vancouver", Config.Timezone)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte(`timezone: Mountain`)), "unknown timezone 'Mountain'")
+}
+
+func Test_ParseConfig_ReferencePoint(t *testing.T) {
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("neighborhood:\n  community: KIL\n  reference-point: \"51.0326,-114.13225\"\n")))
+	assert.Equal(t, "KIL", Config.Neighborhood.Community)
+	assert.Equal(t, "51.0326,-114.13225", Config.Neighborhood.ReferencePoint)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("neighborhood:\n  reference-point: community centre\n")), "neighborhood reference-point")
+}
+
+func Test_ParseConfig_CommunitiesAndWards(t *testing.T) {
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("neighborhood:\n  community-codes: [kil, ric]\n  wards: [\"8\"]\n")))
+	assert.Equal(t, []string{"KIL", "RIC"}, Config.Neighborhood.CommunityCodes)
+	assert.Equal(t, []string{"8"}, Config.Neighborhood.Wards)
+	assert.Equal(t, "any", Config.Neighborhood.Selection)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("neighborhood:\n  community-codes: [\"KIL' OR 1=1\"]\n")), "community code")
+
+	// Any community can be listed, permits are selected by their communitycode column without a boundary
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("neighborhood:\n  community-codes: [KIL, BRI]\n")))
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("neighborhood:\n  community-codes: [KIL]\n  wards: [\"15\"]\n")), "ward")
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("neighborhood:\n  wards: [\"8\"]\n")), "rezoning applications have no ward")
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("neighborhood:\n  community-codes: [KIL]\n  selection: either\n")), "selection")
+}
+
+func Test_ParseConfig_HTTP(t *testing.T) {
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("neighborhood:\n  name: Killarney\n")))
+	assert.Equal(t, HTTP{Timeout: 20 * time.Second, Retries: intPointer(3), InitialBackoff: time.Second, MaxBackoff: 30 * time.Second, Concurrency: 2}, Config.HTTP)
+
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("http:\n  retries: 5\n  initial-backoff: 2s\n  max-backoff: 1m\n  requests-per-second: 2\n  concurrency: 4\n")))
+	assert.Equal(t, 5, *Config.HTTP.Retries)
+	assert.Equal(t, 2*time.Second, Config.HTTP.InitialBackoff)
+	assert.Equal(t, time.Minute, Config.HTTP.MaxBackoff)
+	assert.Equal(t, 2.0, Config.HTTP.RequestsPerSecond)
+	assert.Equal(t, 4, Config.HTTP.Concurrency)
+
+	// 0 turns retries off rather than falling back to the default
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("http:\n  retries: 0\n")))
+	assert.Equal(t, 0, *Config.HTTP.Retries)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("http:\n  retries: -1\n")), "http retries cannot be negative")
+}
+
+func Test_ParseConfig_Fetch(t *testing.T) {
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("neighborhood:\n  name: Killarney\n")))
+	assert.Equal(t, Fetch{Incremental: false, FullEvery: 24 * time.Hour}, Config.Fetch)
+
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("fetch:\n  incremental: true\n  full-every: 6h\n")))
+	assert.Equal(t, Fetch{Incremental: true, FullEvery: 6 * time.Hour}, Config.Fetch)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("http:\n  initial-backoff: 1m\n  max-backoff: 10s\n")), "max-backoff")
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("http:\n  requests-per-second: -1\n")), "requests-per-second")
+}
+
+func Test_ParseConfig_OpenData(t *testing.T) {
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("neighborhood:\n  name: Killarney\n")))
+	assert.Equal(t, "https://data.calgary.ca", Config.OpenData.BaseURL)
+
+	Config = DevBot{}
+	assert.NoError(t, parseConfig([]byte("open-data:\n  base-url: http://localhost:8090/\n")))
+	assert.Equal(t, "http://localhost:8090", Config.OpenData.BaseURL)
+
+	Config = DevBot{}
+	assert.ErrorContains(t, parseConfig([]byte("open-data:\n  base-url: localhost:8090\n")), "base-url")
+}
@@ -13,3 +13,9 @@ func ExitError(err error) {
 	fmt.Println(err.Error())
 	os.Exit(-1)
 }
+
+// ExitPartialFailure - Exits with status 2 when some of the work failed and the rest was done, so schedulers can tell it from a total failure
+func ExitPartialFailure(err error) {
+	fmt.Println(err.Error())
+	os.Exit(2)
+}
@@ -0,0 +1,153 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusMeters - mean radius of the earth used for great circle distances
+const earthRadiusMeters = 6371008.8
+
+// Point - a WGS84 coordinate
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// DistanceMeters - great circle distance between two points in meters
+func DistanceMeters(a Point, b Point) float64 {
+	lat1 := toRadians(a.Latitude)
+	lat2 := toRadians(b.Latitude)
+	deltaLat := toRadians(b.Latitude - a.Latitude)
+	deltaLon := toRadians(b.Longitude - a.Longitude)
+
+	h := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// compassPoints - the eight directions a bearing is rounded to, clockwise from north
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// Polygon - a WGS84 polygon, an outer ring followed by any holes, rings need not repeat their first point
+type Polygon [][]Point
+
+// BearingDegrees - initial great circle bearing from one point to another, clockwise from north in [0, 360)
+func BearingDegrees(from Point, to Point) float64 {
+	lat1 := toRadians(from.Latitude)
+	lat2 := toRadians(to.Latitude)
+	deltaLon := toRadians(to.Longitude - from.Longitude)
+
+	y := math.Sin(deltaLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(deltaLon)
+
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// CompassDirection - rounds a bearing to one of the eight compass points, such as NE
+func CompassDirection(bearing float64) string {
+	return compassPoints[int(math.Round(math.Mod(bearing+360, 360)/45))%len(compassPoints)]
+}
+
+// Contains - checks if a point is inside the polygon's outer ring and outside its holes
+func (p Polygon) Contains(point Point) bool {
+	if len(p) == 0 || !ringContains(p[0], point) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+	return true
+}
+
+// DistanceToEdgeMeters - distance from a point to the nearest edge of the polygon, whether the point is inside it or not
+// Edges are short enough within a city that they are treated as straight lines on a local flat projection
+func (p Polygon) DistanceToEdgeMeters(point Point) float64 {
+	closest := math.Inf(1)
+	metersPerDegreeLat := earthRadiusMeters * math.Pi / 180
+	metersPerDegreeLon := metersPerDegreeLat * math.Cos(toRadians(point.Latitude))
+	project := func(vertex Point) (float64, float64) {
+		return (vertex.Longitude - point.Longitude) * metersPerDegreeLon, (vertex.Latitude - point.Latitude) * metersPerDegreeLat
+	}
+
+	for _, ring := range p {
+		for i := range ring {
+			ax, ay := project(ring[i])
+			bx, by := project(ring[(i+1)%len(ring)])
+			closest = math.Min(closest, distanceToSegment(ax, ay, bx, by))
+		}
+	}
+	return closest
+}
+
+// ringContains - even-odd ray casting test of a point against a ring
+func ringContains(ring []Point, point Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) &&
+			point.Longitude < (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// distanceToSegment - distance from the origin to the segment between two projected points
+func distanceToSegment(ax float64, ay float64, bx float64, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSquared))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// ParsePoint - parses a "latitude,longitude" pair
+func ParsePoint(value string) (Point, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return Point{}, fmt.Errorf("point '%s' must be in the form latitude,longitude", value)
+	}
+
+	latitude, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	longitude, lonErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if latErr != nil || lonErr != nil || math.IsNaN(latitude) || math.IsNaN(longitude) {
+		return Point{}, fmt.Errorf("point '%s' must be in the form latitude,longitude", value)
+	}
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return Point{}, fmt.Errorf("point '%s' is out of range", value)
+	}
+
+	return Point{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// ParseDistance - parses a distance such as "500m", "1.5km" or "300" (meters) into meters
+func ParseDistance(value string) (float64, error) {
+	trimmed := strings.ToLower(strings.TrimSpace(value))
+	multiplier := 1.0
+	if strings.HasSuffix(trimmed, "km") {
+		multiplier = 1000
+		trimmed = strings.TrimSuffix(trimmed, "km")
+	} else {
+		trimmed = strings.TrimSuffix(trimmed, "m")
+	}
+
+	// ParseFloat accepts NaN and Inf, which no distance is, and a huge number of kilometers overflows to Inf
+	distance, err := strconv.ParseFloat(strings.TrimSpace(trimmed), 64)
+	distance *= multiplier
+	if err != nil || math.IsNaN(distance) || math.IsInf(distance, 0) || distance < 0 {
+		return 0, fmt.Errorf("distance '%s' must be a positive number of meters (500m) or kilometers (1.5km)", value)
+	}
+
+	return distance, nil
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,92 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DistanceMeters_SamePoint(t *testing.T) {
+	point := Point{Latitude: 51.03, Longitude: -114.13}
+	assert.Equal(t, 0.0, DistanceMeters(point, point))
+}
+
+func Test_DistanceMeters_KnownDistance(t *testing.T) {
+	// One thousandth of a degree of latitude is roughly 111 meters
+	a := Point{Latitude: 51.030, Longitude: -114.13}
+	b := Point{Latitude: 51.031, Longitude: -114.13}
+	assert.InDelta(t, 111.2, DistanceMeters(a, b), 0.5)
+}
+
+func Test_ParsePoint_Valid(t *testing.T) {
+	point, err := ParsePoint("51.03, -114.13")
+	assert.NoError(t, err)
+	assert.Equal(t, Point{Latitude: 51.03, Longitude: -114.13}, point)
+}
+
+func Test_ParsePoint_Invalid(t *testing.T) {
+	for _, value := range []string{"", "51.03", "north,west", "91,-114", "51.03,-114.13,5", "NaN,-114.13", "51.03,nan", "Inf,-114.13"} {
+		_, err := ParsePoint(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_ParseDistance(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+	}{
+		{"500m", 500},
+		{"300", 300},
+		{"1.5km", 1500},
+		{" 2KM ", 2000},
+	}
+
+	for _, test := range tests {
+		distance, err := ParseDistance(test.value)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.expected, distance, test.value)
+	}
+}
+
+func Test_ParseDistance_Invalid(t *testing.T) {
+	for _, value := range []string{"", "far", "-5m", "5 miles", "NaN", "nanm", "Inf", "+infkm", "-Infm", "infinity", "1e308km"} {
+		_, err := ParseDistance(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_BearingAndCompassDirection(t *testing.T) {
+	origin := Point{Latitude: 51.03, Longitude: -114.13}
+
+	assert.InDelta(t, 0, BearingDegrees(origin, Point{Latitude: 51.04, Longitude: -114.13}), 0.01)
+	assert.InDelta(t, 90, BearingDegrees(origin, Point{Latitude: 51.03, Longitude: -114.12}), 0.01)
+	assert.Equal(t, "N", CompassDirection(BearingDegrees(origin, Point{Latitude: 51.04, Longitude: -114.13})))
+	assert.Equal(t, "SW", CompassDirection(BearingDegrees(origin, Point{Latitude: 51.02, Longitude: -114.145})))
+	assert.Equal(t, "N", CompassDirection(350))
+	assert.Equal(t, "NW", CompassDirection(330))
+}
+
+func Test_Polygon(t *testing.T) {
+	square := Polygon{{
+		{Latitude: 51.02, Longitude: -114.14},
+		{Latitude: 51.02, Longitude: -114.12},
+		{Latitude: 51.04, Longitude: -114.12},
+		{Latitude: 51.04, Longitude: -114.14},
+	}}
+
+	assert.True(t, square.Contains(Point{Latitude: 51.03, Longitude: -114.13}))
+	assert.False(t, square.Contains(Point{Latitude: 51.05, Longitude: -114.13}))
+
+	// One thousandth of a degree of latitude north of the edge
+	assert.InDelta(t, 111.2, square.DistanceToEdgeMeters(Point{Latitude: 51.041, Longitude: -114.13}), 0.5)
+	assert.InDelta(t, 111.2, square.DistanceToEdgeMeters(Point{Latitude: 51.039, Longitude: -114.13}), 0.5)
+
+	withHole := append(square, []Point{
+		{Latitude: 51.025, Longitude: -114.135},
+		{Latitude: 51.025, Longitude: -114.125},
+		{Latitude: 51.035, Longitude: -114.125},
+		{Latitude: 51.035, Longitude: -114.135},
+	})
+	assert.False(t, withHole.Contains(Point{Latitude: 51.03, Longitude: -114.13}))
+}
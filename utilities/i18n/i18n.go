@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fallbackLanguage - messages missing from a catalog are shown in English
+const fallbackLanguage = "en"
+
+// catalogFiles - one JSON message catalog per language, named by language code
+//
+//go:embed catalog/*.json
+var catalogFiles embed.FS
+
+// catalogs - messages by language and then message key
+var catalogs = mustLoadCatalogs()
+
+// feedLanguages - the RSS language code of each language, fixed to the local variant
+var feedLanguages = map[string]string{
+	"en": "en-us",
+	"fr": "fr-ca",
+}
+
+// frenchMonths - month names, French dates are written "2 janvier 2026"
+var frenchMonths = []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"}
+
+// defaultLanguage - the language used when none is given, set from the config at startup
+var defaultLanguage = fallbackLanguage
+
+// Supported - Lists the languages with a message catalog
+func Supported() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// IsSupported - Checks if a language has a message catalog
+func IsSupported(language string) bool {
+	_, found := catalogs[language]
+	return found
+}
+
+// SetDefault - Sets the language used when none is given
+func SetDefault(language string) error {
+	if !IsSupported(language) {
+		return fmt.Errorf("unsupported language '%s', expected one of %s", language, strings.Join(Supported(), ", "))
+	}
+	defaultLanguage = language
+	return nil
+}
+
+// T - Translates a message key, falling back to English and then to the key itself
+func T(language string, key string) string {
+	if message, found := catalogs[resolve(language)][key]; found {
+		return message
+	}
+	if message, found := catalogs[fallbackLanguage][key]; found {
+		return message
+	}
+	return key
+}
+
+// Tf - Translates a message key and formats it with the arguments
+func Tf(language string, key string, args ...any) string {
+	return fmt.Sprintf(T(language, key), args...)
+}
+
+// FormatDate - Formats a date the way it is written in the language, such as "January 2, 2026" or "2 janvier 2026"
+func FormatDate(language string, date time.Time) string {
+	if resolve(language) == "fr" {
+		day := fmt.Sprintf("%d", date.Day())
+		if date.Day() == 1 {
+			day = "1er"
+		}
+		return fmt.Sprintf("%s %s %d", day, frenchMonths[date.Month()-1], date.Year())
+	}
+
+	return date.Format("January 2, 2006")
+}
+
+// FeedLanguage - Returns the RSS channel language code for a language, such as en-us
+func FeedLanguage(language string) string {
+	return feedLanguages[resolve(language)]
+}
+
+// FromFeedLanguage - Returns the language of an RSS channel language code, the default language if it is not supported
+func FromFeedLanguage(code string) string {
+	language := strings.ToLower(strings.SplitN(strings.TrimSpace(code), "-", 2)[0])
+	if !IsSupported(language) {
+		return defaultLanguage
+	}
+	return language
+}
+
+// resolve - the language to use, the default language when none or an unsupported one is given
+func resolve(language string) string {
+	if !IsSupported(language) {
+		return defaultLanguage
+	}
+	return language
+}
+
+// mustLoadCatalogs - loads the built-in catalogs, which are tested so any failure is a programming error
+func mustLoadCatalogs() map[string]map[string]string {
+	loaded := make(map[string]map[string]string)
+	entries, err := fs.ReadDir(catalogFiles, "catalog")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		content, err := fs.ReadFile(catalogFiles, "catalog/"+entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(content, &messages); err != nil {
+			panic(fmt.Errorf("failed to parse message catalog %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+
+	return loaded
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Catalogs_HaveTheSameKeys(t *testing.T) {
+	keys := func(language string) []string {
+		var names []string
+		for key := range catalogs[language] {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	assert.Equal(t, []string{"en", "fr"}, Supported())
+	for _, language := range Supported() {
+		assert.Equal(t, keys(fallbackLanguage), keys(language), "catalog %s", language)
+		assert.NotEmpty(t, FeedLanguage(language), "feed language %s", language)
+	}
+}
+
+func Test_T(t *testing.T) {
+	assert.Equal(t, "Status", T("en", "status"))
+	assert.Equal(t, "Statut", T("fr", "status"))
+	assert.Equal(t, "Status", T("", "status"))
+	assert.Equal(t, "Status", T("de", "status"))
+	assert.Equal(t, "missing-key", T("fr", "missing-key"))
+	assert.Equal(t, "Activité de développement à Killarney", Tf("fr", "feed-title", "Killarney"))
+}
+
+func Test_SetDefault(t *testing.T) {
+	t.Cleanup(func() { defaultLanguage = fallbackLanguage })
+
+	require.NoError(t, SetDefault("fr"))
+	assert.Equal(t, "Statut", T("", "status"))
+	assert.Equal(t, "fr", FromFeedLanguage("de-de"))
+	assert.ErrorContains(t, SetDefault("de"), "unsupported language 'de', expected one of en, fr")
+}
+
+func Test_FormatDate(t *testing.T) {
+	date := time.Date(2026, 8, 18, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "August 18, 2026", FormatDate("en", date))
+	assert.Equal(t, "18 août 2026", FormatDate("fr", date))
+	assert.Equal(t, "1er février 2026", FormatDate("fr", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func Test_FeedLanguage(t *testing.T) {
+	assert.Equal(t, "en-us", FeedLanguage("en"))
+	assert.Equal(t, "fr-ca", FeedLanguage("fr"))
+	assert.Equal(t, "fr", FromFeedLanguage("fr-CA"))
+	assert.Equal(t, "en", FromFeedLanguage("en-us"))
+	assert.Equal(t, "en", FromFeedLanguage(""))
+}
@@ -0,0 +1,266 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets - histogram buckets in seconds suited to HTTP request latencies
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Default - the registry the bot records its metrics in
+var Default = NewRegistry()
+
+// Registry - a set of metrics that can be exposed in the Prometheus text format
+type Registry struct {
+	lock    sync.Mutex
+	metrics []*metric
+}
+
+// metric - a named family of series sharing a type and label names
+type metric struct {
+	registry   *Registry
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	buckets    []float64
+	series     map[string]*series
+}
+
+// series - the current value of a metric for one set of label values
+type series struct {
+	labelValues  []string
+	value        float64
+	bucketCounts []uint64
+	count        uint64
+}
+
+// Counter - a value that only goes up, such as the number of rows fetched
+type Counter struct{ metric *metric }
+
+// Gauge - a value that can go up and down, such as the number of feed items
+type Gauge struct{ metric *metric }
+
+// Histogram - counts observations, such as fetch latencies, into buckets
+type Histogram struct{ metric *metric }
+
+// NewRegistry - Creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounter - Registers a counter with the given label names
+func (r *Registry) NewCounter(name string, help string, labelNames ...string) *Counter {
+	return &Counter{metric: r.register(name, help, "counter", nil, labelNames)}
+}
+
+// NewGauge - Registers a gauge with the given label names
+func (r *Registry) NewGauge(name string, help string, labelNames ...string) *Gauge {
+	return &Gauge{metric: r.register(name, help, "gauge", nil, labelNames)}
+}
+
+// NewHistogram - Registers a histogram with the given upper bucket bounds and label names
+func (r *Registry) NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	sortedBuckets := append([]float64(nil), buckets...)
+	sort.Float64s(sortedBuckets)
+	return &Histogram{metric: r.register(name, help, "histogram", sortedBuckets, labelNames)}
+}
+
+func (r *Registry) register(name string, help string, kind string, buckets []float64, labelNames []string) *metric {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, existing := range r.metrics {
+		if existing.name == name {
+			panic(fmt.Sprintf("metric %s is already registered", name))
+		}
+	}
+
+	m := &metric{
+		registry:   r,
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*series),
+	}
+	r.metrics = append(r.metrics, m)
+	return m
+}
+
+// Inc - Adds one to the counter
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add - Adds a non negative amount to the counter
+func (c *Counter) Add(amount float64, labelValues ...string) {
+	if amount < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", c.metric.name))
+	}
+	c.metric.update(labelValues, func(s *series) { s.value += amount })
+}
+
+// Value - The current value of the counter
+func (c *Counter) Value(labelValues ...string) float64 {
+	return c.metric.value(labelValues)
+}
+
+// Set - Sets the gauge to a value
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.metric.update(labelValues, func(s *series) { s.value = value })
+}
+
+// Value - The current value of the gauge
+func (g *Gauge) Value(labelValues ...string) float64 {
+	return g.metric.value(labelValues)
+}
+
+// Observe - Records an observation in the histogram
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.metric.update(labelValues, func(s *series) {
+		if s.bucketCounts == nil {
+			s.bucketCounts = make([]uint64, len(h.metric.buckets))
+		}
+		for i, upperBound := range h.metric.buckets {
+			if value <= upperBound {
+				s.bucketCounts[i]++
+			}
+		}
+		s.value += value
+		s.count++
+	})
+}
+
+// Count - The number of observations recorded in the histogram
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.metric.registry.lock.Lock()
+	defer h.metric.registry.lock.Unlock()
+
+	if s, found := h.metric.series[h.metric.seriesKey(labelValues)]; found {
+		return s.count
+	}
+	return 0
+}
+
+func (m *metric) seriesKey(labelValues []string) string {
+	if len(labelValues) != len(m.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", m.name, len(m.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func (m *metric) update(labelValues []string, change func(s *series)) {
+	key := m.seriesKey(labelValues)
+
+	m.registry.lock.Lock()
+	defer m.registry.lock.Unlock()
+
+	s, found := m.series[key]
+	if !found {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		m.series[key] = s
+	}
+	change(s)
+}
+
+func (m *metric) value(labelValues []string) float64 {
+	key := m.seriesKey(labelValues)
+
+	m.registry.lock.Lock()
+	defer m.registry.lock.Unlock()
+
+	if s, found := m.series[key]; found {
+		return s.value
+	}
+	return 0
+}
+
+// WriteText - Writes every metric in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var builder strings.Builder
+	for _, m := range r.metrics {
+		fmt.Fprintf(&builder, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+		fmt.Fprintf(&builder, "# TYPE %s %s\n", m.name, m.kind)
+
+		keys := make([]string, 0, len(m.series))
+		for key := range m.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := m.series[key]
+			if m.kind != "histogram" {
+				fmt.Fprintf(&builder, "%s%s %s\n", m.name, formatLabels(m.labelNames, s.labelValues, "", ""), formatValue(s.value))
+				continue
+			}
+
+			for i, upperBound := range m.buckets {
+				var bucketCount uint64
+				if s.bucketCounts != nil {
+					bucketCount = s.bucketCounts[i]
+				}
+				fmt.Fprintf(&builder, "%s_bucket%s %d\n", m.name, formatLabels(m.labelNames, s.labelValues, "le", formatValue(upperBound)), bucketCount)
+			}
+			fmt.Fprintf(&builder, "%s_bucket%s %d\n", m.name, formatLabels(m.labelNames, s.labelValues, "le", "+Inf"), s.count)
+			fmt.Fprintf(&builder, "%s_sum%s %s\n", m.name, formatLabels(m.labelNames, s.labelValues, "", ""), formatValue(s.value))
+			fmt.Fprintf(&builder, "%s_count%s %d\n", m.name, formatLabels(m.labelNames, s.labelValues, "", ""), s.count)
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// ServeHTTP - Exposes the registry for Prometheus to scrape
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := r.WriteText(w); err != nil {
+		fmt.Printf("Failed to write metrics: %v\n", err)
+	}
+}
+
+func formatLabels(names []string, values []string, extraName string, extraValue string) string {
+	var pairs []string
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(values[i])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extraName, extraValue))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
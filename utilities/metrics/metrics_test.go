@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Counter_AddsPerLabel(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("rows_total", "Rows fetched", "dataset")
+
+	counter.Add(3, "permits")
+	counter.Inc("permits")
+	counter.Inc("rezonings")
+
+	assert.Equal(t, 4.0, counter.Value("permits"))
+	assert.Equal(t, 1.0, counter.Value("rezonings"))
+	assert.Equal(t, 0.0, counter.Value("unknown"))
+	assert.Panics(t, func() { counter.Add(-1, "permits") })
+	assert.Panics(t, func() { counter.Inc() })
+}
+
+func Test_Gauge_Set(t *testing.T) {
+	registry := NewRegistry()
+	gauge := registry.NewGauge("feed_items", "Items in the feed")
+
+	gauge.Set(10)
+	gauge.Set(7)
+
+	assert.Equal(t, 7.0, gauge.Value())
+}
+
+func Test_Registry_DuplicateNamePanics(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewGauge("feed_items", "Items in the feed")
+
+	assert.Panics(t, func() { registry.NewCounter("feed_items", "Again") })
+}
+
+func Test_WriteText_CountersAndGauges(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("actions_total", "Actions taken", "dataset", "action")
+	gauge := registry.NewGauge("feed_items", "Items in the feed")
+	counter.Add(2, "permits", "CREATE")
+	counter.Inc("permits", `quo"te`)
+	gauge.Set(1.5)
+
+	var builder strings.Builder
+	require.NoError(t, registry.WriteText(&builder))
+
+	expected := `# HELP actions_total Actions taken
+# TYPE actions_total counter
+actions_total{dataset="permits",action="CREATE"} 2
+actions_total{dataset="permits",action="quo\"te"} 1
+# HELP feed_items Items in the feed
+# TYPE feed_items gauge
+feed_items 1.5
+`
+	assert.Equal(t, expected, builder.String())
+}
+
+func Test_WriteText_Histogram(t *testing.T) {
+	registry := NewRegistry()
+	histogram := registry.NewHistogram("fetch_seconds", "Fetch latency", []float64{1, 0.1}, "dataset")
+	histogram.Observe(0.05, "permits")
+	histogram.Observe(0.5, "permits")
+	histogram.Observe(2, "permits")
+
+	var builder strings.Builder
+	require.NoError(t, registry.WriteText(&builder))
+
+	expected := `# HELP fetch_seconds Fetch latency
+# TYPE fetch_seconds histogram
+fetch_seconds_bucket{dataset="permits",le="0.1"} 1
+fetch_seconds_bucket{dataset="permits",le="1"} 2
+fetch_seconds_bucket{dataset="permits",le="+Inf"} 3
+fetch_seconds_sum{dataset="permits"} 2.55
+fetch_seconds_count{dataset="permits"} 3
+`
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, uint64(3), histogram.Count("permits"))
+}
+
+func Test_ServeHTTP(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewGauge("feed_items", "Items in the feed").Set(4)
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "feed_items 4\n")
+}
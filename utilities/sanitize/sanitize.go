@@ -0,0 +1,66 @@
+package sanitize
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// allowedSchemes - link schemes that are safe to put in front of subscribers
+var allowedSchemes = map[string]bool{"http": true, "https": true}
+
+// markdownEscaper - backslash escapes the characters that start emphasis, code, links or inline HTML wherever they appear in a line,
+// characters that only mean something at the start of a line, such as # and >, are left alone since values never start one
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"~", `\~`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+)
+
+// markdownEntity - an & that starts an HTML entity, which Markdown would show as the character it names
+var markdownEntity = regexp.MustCompile(`&(#[0-9]+;|#[xX][0-9A-Fa-f]+;|[A-Za-z][A-Za-z0-9]*;)`)
+
+// urlEscaper - percent encodes characters url.URL leaves in queries and fragments that could break out of HTML attributes or Markdown links
+var urlEscaper = strings.NewReplacer(
+	`"`, "%22",
+	"'", "%27",
+	"<", "%3C",
+	">", "%3E",
+	"`", "%60",
+	" ", "%20",
+	"(", "%28",
+	")", "%29",
+)
+
+// URL - Returns a normalized absolute http or https URL, or an empty string if the value is not a safe link
+func URL(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || strings.ContainsAny(trimmed, "\x00\r\n\t") {
+		return ""
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil || !allowedSchemes[strings.ToLower(parsed.Scheme)] || parsed.Host == "" || parsed.User != nil {
+		return ""
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+
+	return urlEscaper.Replace(parsed.String())
+}
+
+// QueryValue - Escapes a value for a URL query, encoding spaces as %20 since html/template entity encodes a +
+func QueryValue(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// Markdown - Escapes upstream text so it is shown literally in a Markdown document, newlines are collapsed to spaces
+// Only Markdown is escaped, each destination escapes the result for its own format
+func Markdown(text string) string {
+	escaped := markdownEscaper.Replace(strings.Join(strings.Fields(text), " "))
+	return markdownEntity.ReplaceAllString(escaped, `\&$1`)
+}
@@ -0,0 +1,157 @@
+package simplehttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// manifestFile - the file in a fixtures directory that says when it was recorded
+const manifestFile = "manifest.json"
+
+// unsafeNameCharacters - characters left out of fixture file names
+var unsafeNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Manifest - describes a recorded fixtures directory
+type Manifest struct {
+	// RecordedAt - the time the recording started, replays run at this time so their queries match the recorded ones
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Fixture - one recorded response and the request it answered
+type Fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// RecordingTransport - sends requests on and saves each response to a fixtures directory, a repeated request keeps its last response
+type RecordingTransport struct {
+	Directory string
+	// Hosts - the hosts whose responses are saved, requests to any other host are sent on without saving what may be credentials
+	Hosts []string
+	Next  http.RoundTripper
+}
+
+// ReplayTransport - answers requests from a fixtures directory without using the network
+type ReplayTransport struct {
+	Directory string
+}
+
+// ErrNotRecorded - a replayed request has no recorded response
+var ErrNotRecorded = errors.New("no recorded response")
+
+// transport - the transport requests are sent through, nil for the network
+var transport http.RoundTripper
+
+// SetTransport - Sends every request through the transport, such as a recording or replay, nil sends them over the network again
+func SetTransport(roundTripper http.RoundTripper) {
+	transport = roundTripper
+	simpleClient.Transport = roundTripper
+}
+
+// Record - Saves the responses from the hosts from now on to the directory, with a manifest of the time the recording started
+func Record(directory string, recordedAt time.Time, hosts ...string) error {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return fmt.Errorf("failed to create fixtures directory: %v", err)
+	}
+	content, err := json.MarshalIndent(Manifest{RecordedAt: recordedAt}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(directory, manifestFile), content, 0644); err != nil {
+		return fmt.Errorf("failed to write fixtures manifest: %v", err)
+	}
+
+	SetTransport(&RecordingTransport{Directory: directory, Hosts: hosts, Next: http.DefaultTransport})
+	return nil
+}
+
+// Replay - Answers every request from now on from the directory, returns the time it was recorded at
+func Replay(directory string) (time.Time, error) {
+	content, err := os.ReadFile(filepath.Join(directory, manifestFile))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read fixtures manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse fixtures manifest: %v", err)
+	}
+
+	SetTransport(&ReplayTransport{Directory: directory})
+	return manifest.RecordedAt, nil
+}
+
+// FixturePath - Returns the file a request's response is kept in, named for the dataset and a hash of the method and query
+// such as 6933-unw5-1a2b3c4d5e6f.json for https://data.calgary.ca/resource/6933-unw5.json?$query=...
+func FixturePath(directory string, req *http.Request) string {
+	dataset := strings.TrimSuffix(path.Base(req.URL.Path), path.Ext(req.URL.Path))
+	dataset = strings.Trim(unsafeNameCharacters.ReplaceAllString(dataset, "-"), "-")
+	hash := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	return filepath.Join(directory, fmt.Sprintf("%s-%s.json", dataset, hex.EncodeToString(hash[:])[:12]))
+}
+
+// RoundTrip - Sends the request on and saves its response if it is from one of the hosts
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil || !slices.Contains(t.Hosts, req.URL.Host) {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	fixture := Fixture{Method: req.Method, URL: req.URL.String(), Status: resp.StatusCode, Header: resp.Header, Body: string(body)}
+	content, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(FixturePath(t.Directory, req), content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to record %s %s: %v", req.Method, req.URL.String(), err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// RoundTrip - Answers the request with its recorded response, a request that was not recorded is an error
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fixturePath := FixturePath(t.Directory, req)
+	content, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s %s, expected %s, record it again with -record", ErrNotRecorded, req.Method, req.URL.String(), fixturePath)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %v", fixturePath, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        fixture.Header,
+		Body:          io.NopCloser(strings.NewReader(fixture.Body)),
+		ContentLength: int64(len(fixture.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package simplehttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTransport - restores the network transport after a test records or replays
+func useTransport(t *testing.T) {
+	t.Cleanup(func() { SetTransport(nil) })
+}
+
+func Test_RecordThenReplay(t *testing.T) {
+	useTransport(t)
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"permitnum":"%s"}]`, r.URL.Query().Get("permitnum"))
+	}))
+	defer ts.Close()
+
+	directory := t.TempDir()
+	recordedAt := time.Date(2026, 3, 28, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, Record(directory, recordedAt, strings.TrimPrefix(ts.URL, "http://")))
+	recorded, err := SimpleGet(ts.URL+"/resource/6933-unw5.json?permitnum=DP2026-00001", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"permitnum":"DP2026-00001"}]`, string(recorded.Body))
+
+	// Replays are answered from the directory without the server
+	ts.Close()
+	replayedAt, err := Replay(directory)
+	require.NoError(t, err)
+	assert.True(t, recordedAt.Equal(replayedAt))
+
+	replayed, err := SimpleGet(ts.URL+"/resource/6933-unw5.json?permitnum=DP2026-00001", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, replayed.StatusCode)
+	assert.Equal(t, recorded.Body, replayed.Body)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func Test_Record_OnlyTheHosts(t *testing.T) {
+	useTransport(t)
+	openData := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer openData.Close()
+	signIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"accessJwt": "jwt-123"}`))
+	}))
+	defer signIn.Close()
+
+	directory := t.TempDir()
+	require.NoError(t, Record(directory, time.Now(), strings.TrimPrefix(openData.URL, "http://")))
+	_, err := SimpleGet(openData.URL+"/resource/6933-unw5.json", nil)
+	require.NoError(t, err)
+	// Other hosts are still answered, without saving their responses
+	session, err := SimplePost(signIn.URL+"/xrpc/com.atproto.server.createSession", nil, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"accessJwt": "jwt-123"}`, string(session.Body))
+
+	entries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.Len(t, names, 2)
+	assert.Equal(t, "manifest.json", names[1])
+	assert.Regexp(t, `^6933-unw5-[0-9a-f]{12}\.json$`, names[0])
+}
+
+func Test_Replay_MissingFixtureNamesIt(t *testing.T) {
+	useTransport(t)
+	// Misses are not retried, so a replay with retries still fails straight away
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 3, InitialBackoff: time.Minute, MaxBackoff: time.Minute})
+	directory := t.TempDir()
+	require.NoError(t, Record(directory, time.Now()))
+	SetTransport(nil)
+
+	_, err := Replay(directory)
+	require.NoError(t, err)
+	_, err = SimpleGet("https://data.calgary.ca/resource/6933-unw5.json?permitnum=DP2026-00002", nil)
+	require.ErrorIs(t, err, ErrNotRecorded)
+	assert.Contains(t, err.Error(), directory+"/6933-unw5-")
+}
+
+func Test_Replay_RequiresManifest(t *testing.T) {
+	useTransport(t)
+	_, err := Replay(t.TempDir())
+	assert.Error(t, err)
+}
+
+func Test_FixturePath_DependsOnQuery(t *testing.T) {
+	first, _ := http.NewRequest("GET", "https://data.calgary.ca/resource/6933-unw5.json?$query=SELECT%20*", nil)
+	second, _ := http.NewRequest("GET", "https://data.calgary.ca/resource/6933-unw5.json?$query=SELECT%20permitnum", nil)
+	assert.Regexp(t, `^fixtures/6933-unw5-[0-9a-f]{12}\.json$`, FixturePath("fixtures", first))
+	assert.NotEqual(t, FixturePath("fixtures", first), FixturePath("fixtures", second))
+}
@@ -1,10 +1,17 @@
 package simplehttp
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -14,15 +21,51 @@ type SimpleHttpResponse struct {
 	Body       []byte
 }
 
+// Policy - how requests are timed out, retried and paced
+type Policy struct {
+	// Timeout - how long a single attempt may take
+	Timeout time.Duration
+	// Retries - how many more times a GET is tried after a network error, 429 or 5xx
+	Retries int
+	// InitialBackoff, MaxBackoff - the wait before the first retry, doubled for each retry after it up to the maximum
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RequestsPerSecond - the most requests sent to one host each second, 0 for no limit
+	RequestsPerSecond float64
+}
+
+// DefaultPolicy - the policy used until Configure is called
+var DefaultPolicy = Policy{Timeout: 20 * time.Second, Retries: 3, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second}
+
 var simpleClient http.Client
 
+// policy - the policy in use
+var policy Policy
+
+// jitter - a random fraction from 0 to 1, replaced in tests
+var jitter = rand.Float64
+
+// hostLimiter - when each host may next be sent a request
+var hostLimiter = &limiter{next: make(map[string]time.Time)}
+
 // init - Initilize client on startup
 func init() {
-	simpleClient = http.Client{Timeout: (20 * time.Second)}
+	Configure(DefaultPolicy)
+}
+
+// Configure - Sets the timeout, retry and rate limit policy, called once at startup before any requests
+func Configure(newPolicy Policy) {
+	policy = newPolicy
+	simpleClient = http.Client{Timeout: policy.Timeout, Transport: transport}
 }
 
 // SimpleGet - A simple get request
 func SimpleGet(uri string, headers map[string]string) (*SimpleHttpResponse, error) {
+	return SimpleGetContext(context.Background(), uri, headers)
+}
+
+// SimpleGetContext - A simple get request that is retried with backoff after network errors, 429 and 5xx, until it succeeds, the retries run out or the context is done
+func SimpleGetContext(ctx context.Context, uri string, headers map[string]string) (*SimpleHttpResponse, error) {
 	// Parse the URL to check if it contains query parameters
 	parsedURL, err := url.Parse(uri)
 	if err != nil {
@@ -35,7 +78,7 @@ func SimpleGet(uri string, headers map[string]string) (*SimpleHttpResponse, erro
 	}
 
 	// Create a new GET request
-	req, err := http.NewRequest("GET", parsedURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", parsedURL.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating HTTP request: %s", err.Error())
 	}
@@ -45,10 +88,98 @@ func SimpleGet(uri string, headers map[string]string) (*SimpleHttpResponse, erro
 		req.Header.Set(key, value)
 	}
 
-	// Send the request and get the response
+	// Send the request and get the response, GETs are safe to repeat
+	return withRetries(ctx, policy.Retries, func() (*http.Request, error) { return req, nil })
+}
+
+// SimplePost - A simple post request, the uri is sent as given
+func SimplePost(uri string, headers map[string]string, body []byte) (*SimpleHttpResponse, error) {
+	return SimpleRequest("POST", uri, headers, body)
+}
+
+// SimpleRequest - Sends a request with any method, the uri is sent as given without re-escaping its query
+func SimpleRequest(method string, uri string, headers map[string]string, body []byte) (*SimpleHttpResponse, error) {
+	return SimpleRequestContext(context.Background(), method, uri, headers, body)
+}
+
+// SimpleRequestContext - Sends a request with any method once, giving up when the context is done
+func SimpleRequestContext(ctx context.Context, method string, uri string, headers map[string]string, body []byte) (*SimpleHttpResponse, error) {
+	req, err := newRequest(ctx, method, uri, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	return send(req)
+}
+
+// SimplePostRetryContext - A post request retried like SimpleGetContext, but up to the given number of retries,
+// only for endpoints where receiving the same body twice does no harm
+func SimplePostRetryContext(ctx context.Context, uri string, headers map[string]string, body []byte, retries int) (*SimpleHttpResponse, error) {
+	// Each attempt reads the body from the start
+	return withRetries(ctx, retries, func() (*http.Request, error) { return newRequest(ctx, "POST", uri, headers, body) })
+}
+
+// newRequest - builds a request with the headers, the uri is sent as given
+func newRequest(ctx context.Context, method string, uri string, headers map[string]string, body []byte) (*http.Request, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating HTTP request: %s", err.Error())
+	}
+
+	//Add headers from map
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// withRetries - sends the request, and after network errors, 429 and 5xx sends it again with backoff, waiting out a Retry-After
+// that fits within the maximum backoff, until it succeeds, the retries run out or the context is done
+func withRetries(ctx context.Context, retries int, nextRequest func() (*http.Request, error)) (*SimpleHttpResponse, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := nextRequest()
+		if err != nil {
+			return nil, err
+		}
+		response, wait, err := sendWithRetryAfter(req)
+		if attempt >= retries || !retryable(response, err) || ctx.Err() != nil {
+			return response, err
+		}
+
+		backoff := backoffDelay(attempt)
+		if wait > policy.MaxBackoff {
+			// The server asked for a longer wait than the run will spend, so give it the answer it sent
+			return response, err
+		}
+		if wait > backoff {
+			backoff = wait
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return nil, fmt.Errorf("error sending HTTP request: %s", err.Error())
+		}
+	}
+}
+
+// send - Sends a request and reads the whole response
+func send(req *http.Request) (*SimpleHttpResponse, error) {
+	response, _, err := sendWithRetryAfter(req)
+	return response, err
+}
+
+// sendWithRetryAfter - sends a request once the host's rate limit allows, returning how long the server asked to wait before trying again
+func sendWithRetryAfter(req *http.Request) (*SimpleHttpResponse, time.Duration, error) {
+	if err := hostLimiter.wait(req.Context(), req.URL.Host, policy.RequestsPerSecond); err != nil {
+		return nil, 0, fmt.Errorf("error sending HTTP request: %s", err.Error())
+	}
+
 	resp, err := simpleClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error sending HTTP request: %s", err.Error())
+		return nil, 0, fmt.Errorf("error sending HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -57,9 +188,85 @@ func SimpleGet(uri string, headers map[string]string) (*SimpleHttpResponse, erro
 	response.StatusCode = resp.StatusCode
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	response.Body = body
 
-	return &response, nil
+	return &response, retryAfter(resp.Header.Get("Retry-After")), nil
+}
+
+// retryable - true for network errors, 429 Too Many Requests and 5xx server errors
+func retryable(response *SimpleHttpResponse, err error) bool {
+	if err != nil {
+		// A replay will not have the response next time either
+		return !errors.Is(err, ErrNotRecorded)
+	}
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+}
+
+// backoffDelay - the wait before a retry, doubling from the initial backoff up to the maximum with up to half of it randomized so clients spread out
+func backoffDelay(attempt int) time.Duration {
+	delay := policy.InitialBackoff
+	for i := 0; i < attempt && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	return delay/2 + time.Duration(jitter()*float64(delay/2))
+}
+
+// retryAfter - reads a Retry-After header given in seconds or as an HTTP date, 0 when there is none
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// sleep - waits for the duration, returning early with the context's error when it is done
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limiter - spaces out requests to each host, safe to use from several goroutines
+type limiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// wait - reserves the host's next free slot and waits for it, without waiting when there is no limit
+func (l *limiter) wait(ctx context.Context, host string, requestsPerSecond float64) error {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(time.Duration(float64(time.Second) / requestsPerSecond))
+	l.mu.Unlock()
+
+	return sleep(ctx, time.Until(slot))
 }
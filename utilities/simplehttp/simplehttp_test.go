@@ -1,14 +1,45 @@
 package simplehttp
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// usePolicy - sets a policy for one test, restoring the default afterwards
+func usePolicy(t *testing.T, testPolicy Policy) {
+	t.Cleanup(func() {
+		Configure(DefaultPolicy)
+		hostLimiter = &limiter{next: make(map[string]time.Time)}
+	})
+	Configure(testPolicy)
+	hostLimiter = &limiter{next: make(map[string]time.Time)}
+}
+
+// failingServer - answers with the status until it has failed the given number of times, then with OK
+func failingServer(failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	return ts, &requests
+}
+
 func Test_HttpGet_ProcessesBody(t *testing.T) {
 	// Create a test server
 	expectedBody := `{"name":"John", "age":30}`
@@ -53,3 +84,176 @@ func Test_HttpGet_404IsNotTreatedAsError(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNotFound, response.StatusCode)
 }
+
+func Test_SimplePost_SendsBodyAndHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, `{"ping":true}`, string(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	response, err := SimplePost(ts.URL, map[string]string{"Content-Type": "application/json"}, []byte(`{"ping":true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+}
+
+func Test_SimpleRequest_KeepsEncodedQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Query().Get("topic"))
+	}))
+	defer ts.Close()
+
+	response, err := SimpleRequest("GET", ts.URL+"?topic=https%3A%2F%2Fexample.com%2Ffeed.xml", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/feed.xml", string(response.Body))
+}
+
+func Test_SimpleGet_RetriesServerErrors(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	ts, requests := failingServer(2, http.StatusServiceUnavailable, nil)
+	defer ts.Close()
+
+	response, err := SimpleGet(ts.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "ok", string(response.Body))
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func Test_SimpleGet_GivesUpAfterRetries(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	ts, requests := failingServer(10, http.StatusBadGateway, nil)
+	defer ts.Close()
+
+	response, err := SimpleGet(ts.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, response.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func Test_SimpleGet_DoesNotRetryClientErrors(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	ts, requests := failingServer(10, http.StatusBadRequest, nil)
+	defer ts.Close()
+
+	response, err := SimpleGet(ts.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func Test_SimpleGet_HonorsRetryAfter(t *testing.T) {
+	usePolicy(t, Policy{Timeout: 5 * time.Second, Retries: 1, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Second})
+	ts, requests := failingServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"1"}})
+	defer ts.Close()
+
+	started := time.Now()
+	response, err := SimpleGet(ts.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+	assert.GreaterOrEqual(t, time.Since(started), time.Second)
+}
+
+func Test_SimpleGet_RetryAfterBeyondMaxBackoff(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	ts, requests := failingServer(10, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"120"}})
+	defer ts.Close()
+
+	response, err := SimpleGet(ts.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func Test_SimpleGet_RetriesNetworkErrors(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 1, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	_, err := SimpleGet(url, nil)
+	assert.ErrorContains(t, err, "error sending HTTP request")
+}
+
+func Test_SimpleGetContext_StopsWhenCancelled(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 5, InitialBackoff: time.Minute, MaxBackoff: time.Minute})
+	ts, requests := failingServer(10, http.StatusInternalServerError, nil)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := SimpleGetContext(ctx, ts.URL, nil)
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func Test_SimplePost_IsNotRetried(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	ts, requests := failingServer(10, http.StatusServiceUnavailable, nil)
+	defer ts.Close()
+
+	response, err := SimplePost(ts.URL, nil, []byte("{}"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func Test_SimplePostRetryContext_ResendsBody(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, Retries: 0, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	// The retries given are used rather than the policy's
+	response, err := SimplePostRetryContext(context.Background(), ts.URL, nil, []byte("{}"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"{}", "{}", "{}"}, bodies)
+}
+
+func Test_RateLimiter_SpacesRequestsToAHost(t *testing.T) {
+	usePolicy(t, Policy{Timeout: time.Second, RequestsPerSecond: 20})
+	ts, _ := failingServer(0, http.StatusOK, nil)
+	defer ts.Close()
+
+	started := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := SimpleGet(ts.URL, nil)
+		require.NoError(t, err)
+	}
+	// The first request goes straight away and each after it waits 50ms
+	assert.GreaterOrEqual(t, time.Since(started), 150*time.Millisecond)
+}
+
+func Test_BackoffDelay(t *testing.T) {
+	usePolicy(t, Policy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	previous := jitter
+	t.Cleanup(func() { jitter = previous })
+
+	jitter = func() float64 { return 1 }
+	assert.Equal(t, time.Second, backoffDelay(0))
+	assert.Equal(t, 4*time.Second, backoffDelay(2))
+	assert.Equal(t, 5*time.Second, backoffDelay(10))
+
+	jitter = func() float64 { return 0 }
+	assert.Equal(t, 500*time.Millisecond, backoffDelay(0))
+}
+
+func Test_RetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, retryAfter("3"))
+	assert.Equal(t, time.Duration(0), retryAfter(""))
+	assert.Equal(t, time.Duration(0), retryAfter("soon"))
+
+	wait := retryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.Greater(t, wait, 50*time.Second)
+}
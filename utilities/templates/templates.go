@@ -0,0 +1,308 @@
+package templates
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/jeffadavidson/development-bot/utilities/i18n"
+	"github.com/jeffadavidson/development-bot/utilities/sanitize"
+)
+
+// defaultFiles - the built-in templates, used for anything the override directory does not replace
+//
+//go:embed defaults/*.tmpl
+var defaultFiles embed.FS
+
+// functions - helpers available to every template
+var functions = map[string]any{
+	"markdown": sanitize.Markdown,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+}
+
+// Item - the values templates are given for a development permit or rezoning application
+// Values that are not set upstream are empty strings, so templates can test them with {{with}} or {{if}}
+type Item struct {
+	// Language - the language the item is rendered in, labels are looked up with {{.T "status"}}
+	Language string
+	// Dataset - development-permit or rezoning-application
+	Dataset   string
+	PermitNum string
+	Status    string
+	// Approved - the status says the application was approved
+	Approved    bool
+	Category    string
+	Address     string
+	Community   string
+	Ward        string
+	Applicant   string
+	Description string
+	// AppliedDate - the date the application was made, as YYYY-MM-DD in every language
+	AppliedDate string
+	// CurrentLandUse - the land use district today, a description for permits and the district code for rezonings
+	CurrentLandUse string
+	// ProposedLandUse - the district a rezoning asks for, empty for development permits
+	ProposedLandUse string
+	// ApplicationType - permitted or discretionary, development permits only
+	ApplicationType  string
+	MustCommenceDate string
+	Decision         string
+	DecisionBy       string
+	ReleaseDate      string
+	// CurrentDistricts, ProposedDistricts - the land use districts explained from the glossary, a parcel can be split between districts
+	CurrentDistricts  []District
+	ProposedDistricts []District
+	// LandUseChanges - what a rezoning changes, rezoning applications only
+	LandUseChanges []LandUseChange
+	// Position - where the item is by community and from the reference point, nil when it has no location
+	Position *Position
+	// Related - other applications at the same address or parcel
+	Related  []RelatedItem
+	Links    Links
+	Timeline []TimelineEntry
+}
+
+// District - a land use district such as MU-1 f3.0h16, with its glossary name and summary when the glossary has it
+type District struct {
+	Code    string
+	Name    string
+	Summary string
+	// Limits - the height and floor area limits set by the district's modifiers, such as "max height 16 m, max FAR 3.0"
+	Limits string
+}
+
+// Position - where an item is, empty values are not known or not configured
+type Position struct {
+	// Community - the community the item is in, from the built-in boundaries
+	Community string
+	// Placement - in the community, adjacent or outside it, translated
+	Placement string
+	// Distance, Direction, Reference - such as "350 m", "northeast" and the name of the reference point
+	Distance  string
+	Direction string
+	Reference string
+	// Summary - the position in one line, such as "Adjacent · RICHMOND · 350 m southeast of the community centre"
+	Summary string
+}
+
+// RelatedItem - another application at the same address or parcel
+type RelatedItem struct {
+	// Title - the application type and number, such as "Rezoning Application LOC2026-0009"
+	Title   string
+	Address string
+	Status  string
+	// Reason - why it is related, such as "same address" or "12 m away"
+	Reason string
+	Link   string
+}
+
+// LandUseChange - one thing a rezoning changes, an empty From or To is shown as set by the district
+type LandUseChange struct {
+	Label string
+	From  string
+	To    string
+}
+
+// Links - sanitized absolute links for the item, empty when there is no safe link
+type Links struct {
+	DevelopmentMap string
+	GoogleMaps     string
+}
+
+// TimelineEntry - one dated event in the item's history, with an optional detail such as the decision
+// The date is already written in the item's language
+type TimelineEntry struct {
+	Label  string
+	Date   string
+	Detail string
+}
+
+// Status - the values the social media status template is given for an action
+type Status struct {
+	Language string
+	// Action - CREATE, UPDATE or CLOSE
+	Action string
+	// Dataset - development-permit or rezoning-application
+	Dataset   string
+	PermitNum string
+	Address   string
+	// Summary - a one line summary, the project description for permits and the district change for rezonings
+	Summary  string
+	Decision string
+	Status   string
+}
+
+// T - Translates a label into the item's language
+func (i Item) T(key string) string {
+	return i18n.T(i.Language, key)
+}
+
+// T - Translates a label into the status's language
+func (s Status) T(key string) string {
+	return i18n.T(s.Language, key)
+}
+
+// executor - a parsed text or HTML template
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// builtin - the parsed built-in templates by file name
+var builtin = mustParseDefaults()
+
+// active - the templates in use, the built-in templates with any overrides loaded in their place
+var active = builtin
+
+// Load - Replaces built-in templates with files of the same name from the directory, an empty directory uses only the built-in templates
+func Load(directory string) error {
+	templates := make(map[string]executor, len(builtin))
+	for name, template := range builtin {
+		templates[name] = template
+	}
+
+	if directory != "" {
+		entries, err := os.ReadDir(directory)
+		if err != nil {
+			return fmt.Errorf("failed to read template directory: %v", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") {
+				continue
+			}
+			if _, found := builtin[entry.Name()]; !found {
+				return fmt.Errorf("unknown template '%s', expected one of %s", entry.Name(), strings.Join(Names(), ", "))
+			}
+
+			content, err := os.ReadFile(filepath.Join(directory, entry.Name()))
+			if err != nil {
+				return fmt.Errorf("failed to read template %s: %v", entry.Name(), err)
+			}
+			template, err := parse(entry.Name(), string(content))
+			if err != nil {
+				return err
+			}
+			// Fields that do not exist only fail when executed, so try the template before it is used
+			if err := template.Execute(io.Discard, sampleData(entry.Name())); err != nil {
+				return fmt.Errorf("template %s failed: %v", entry.Name(), err)
+			}
+			templates[entry.Name()] = template
+			fmt.Printf("Using template %s from %s\n", entry.Name(), directory)
+		}
+	}
+
+	active = templates
+	return nil
+}
+
+// Names - Lists the templates that can be overridden
+func Names() []string {
+	entries, _ := fs.ReadDir(defaultFiles, "defaults")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// Title - Renders the feed and notification title of an item
+func Title(item Item) string {
+	return strings.TrimSpace(render(item.Dataset+"-title.txt.tmpl", item))
+}
+
+// Description - Renders the HTML feed description of an item
+func Description(item Item) string {
+	return strings.TrimSpace(render(item.Dataset+"-description.html.tmpl", item))
+}
+
+// Message - Renders the Markdown message notifications include for a new item
+func Message(item Item) string {
+	return render(item.Dataset+"-message.md.tmpl", item)
+}
+
+// SocialStatus - Renders the text of a social media post, without its link
+func SocialStatus(status Status) string {
+	return strings.Join(strings.Fields(render("social-status.txt.tmpl", status)), " ")
+}
+
+// render - executes a template, falling back to the built-in template if an override fails
+func render(name string, data any) string {
+	template, found := active[name]
+	if !found {
+		return ""
+	}
+
+	var output strings.Builder
+	err := template.Execute(&output, data)
+	if err == nil {
+		return output.String()
+	}
+
+	fmt.Printf("Template %s failed, using the built-in template. Error: %s\n", name, err.Error())
+	output.Reset()
+	if err := builtin[name].Execute(&output, data); err != nil {
+		fmt.Printf("Built-in template %s failed. Error: %s\n", name, err.Error())
+	}
+	return output.String()
+}
+
+// parse - parses a template, HTML templates escape values for where they are used
+func parse(name string, content string) (executor, error) {
+	if strings.HasSuffix(name, ".html.tmpl") {
+		template, err := htmltemplate.New(name).Funcs(functions).Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %v", name, err)
+		}
+		return template, nil
+	}
+
+	template, err := texttemplate.New(name).Funcs(functions).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %v", name, err)
+	}
+	return template, nil
+}
+
+// sampleData - data of the right type for the named template, used to check overrides
+func sampleData(name string) any {
+	if strings.HasPrefix(name, "social-status") {
+		return Status{Action: "CREATE", Dataset: "development-permit", PermitNum: "DP2026-00001"}
+	}
+	return Item{
+		PermitNum:         "DP2026-00001",
+		CurrentDistricts:  []District{{Code: "R-CG", Name: "Residential – Grade-Oriented Infill", Summary: "Low-density homes"}},
+		ProposedDistricts: []District{{Code: "MU-1 f3.0h16", Name: "Mixed Use – General", Limits: "max height 16 m, max FAR 3.0"}},
+		LandUseChanges:    []LandUseChange{{Label: "Maximum Height", To: "16 m"}},
+		Position:          &Position{Community: "KILLARNEY/GLENGARRY", Placement: "In the community", Summary: "In the community · KILLARNEY/GLENGARRY"},
+		Related:           []RelatedItem{{Title: "Rezoning Application LOC2026-0009", Reason: "same address", Link: "https://developmentmap.calgary.ca/?find=LOC2026-0009"}},
+		Timeline:          []TimelineEntry{{Label: "Submitted", Date: "January 2, 2026"}},
+	}
+}
+
+// mustParseDefaults - parses the built-in templates, which are tested so any failure is a programming error
+func mustParseDefaults() map[string]executor {
+	templates := make(map[string]executor)
+	entries, err := fs.ReadDir(defaultFiles, "defaults")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		content, err := fs.ReadFile(defaultFiles, "defaults/"+entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		template, err := parse(entry.Name(), string(content))
+		if err != nil {
+			panic(err)
+		}
+		templates[entry.Name()] = template
+	}
+	return templates
+}
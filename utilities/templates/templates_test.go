@@ -0,0 +1,98 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testItem() Item {
+	return Item{
+		Dataset:         "rezoning-application",
+		PermitNum:       "LOC2026-0023",
+		Status:          "Under Review",
+		Address:         "3214 29 ST SW",
+		Applicant:       "SMITH & SONS",
+		CurrentLandUse:  "R-CG",
+		ProposedLandUse: "H-GO",
+		Links:           Links{DevelopmentMap: "https://developmentmap.calgary.ca/?find=LOC2026-0023"},
+		Timeline:        []TimelineEntry{{Label: "Applied", Date: "March 2, 2026"}},
+	}
+}
+
+// useDefaults - restores the built-in templates after a test loads overrides
+func useDefaults(t *testing.T) {
+	t.Cleanup(func() { active = builtin })
+}
+
+func Test_BuiltinTemplates(t *testing.T) {
+	item := testItem()
+
+	assert.Equal(t, "🏛️ Rezoning Application: LOC2026-0023 - 3214 29 ST SW", Title(item))
+	assert.Contains(t, Description(item), "<li><strong>From:</strong> R-CG</li><li><strong>To:</strong> H-GO</li>")
+	assert.Contains(t, Description(item), "<p>👤 <strong>Applicant:</strong> SMITH &amp; SONS</p>")
+	assert.Contains(t, Message(item), "**Permit Number:** LOC2026-0023\n**Address:** 3214 29 ST SW\n")
+	assert.Equal(t, "New rezoning LOC2026-0023 at 3214 29 ST SW: R-CG → H-GO", SocialStatus(Status{
+		Action:    "CREATE",
+		Dataset:   "rezoning-application",
+		PermitNum: "LOC2026-0023",
+		Address:   "3214 29 ST SW",
+		Summary:   "R-CG → H-GO",
+	}))
+	assert.Equal(t, "Decision on development permit DP2026-01776: Approved", SocialStatus(Status{
+		Action:    "CLOSE",
+		Dataset:   "development-permit",
+		PermitNum: "DP2026-01776",
+		Status:    "Approved",
+	}))
+}
+
+func Test_Load_OverridesTemplates(t *testing.T) {
+	useDefaults(t)
+	directory := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "rezoning-application-title.txt.tmpl"), []byte("Rezoning {{.PermitNum}} | {{upper .Status}}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "rezoning-application-description.html.tmpl"), []byte("<p>{{.Applicant}}</p>"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "notes.txt"), []byte("ignored"), 0644))
+
+	require.NoError(t, Load(directory))
+
+	item := testItem()
+	assert.Equal(t, "Rezoning LOC2026-0023 | UNDER REVIEW", Title(item))
+	// Overridden HTML templates still escape values
+	assert.Equal(t, "<p>SMITH &amp; SONS</p>", Description(item))
+	// Templates that are not overridden use the built-in version
+	assert.Contains(t, Message(item), "## About")
+
+	require.NoError(t, Load(""))
+	assert.Equal(t, "🏛️ Rezoning Application: LOC2026-0023 - 3214 29 ST SW", Title(item))
+}
+
+func Test_Load_Errors(t *testing.T) {
+	useDefaults(t)
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{"unknown template", "permit-title.txt.tmpl", "{{.PermitNum}}", "unknown template 'permit-title.txt.tmpl'"},
+		{"syntax error", "development-permit-title.txt.tmpl", "{{.PermitNum", "failed to parse template development-permit-title.txt.tmpl"},
+		{"unknown field", "development-permit-title.txt.tmpl", "{{.PermitNumber}}", "template development-permit-title.txt.tmpl failed"},
+		{"unknown status field", "social-status.txt.tmpl", "{{.Title}}", "template social-status.txt.tmpl failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(directory, test.file), []byte(test.content), 0644))
+
+			assert.ErrorContains(t, Load(directory), test.expected)
+			assert.Equal(t, "🏛️ Rezoning Application: LOC2026-0023 - 3214 29 ST SW", Title(testItem()))
+		})
+	}
+
+	assert.ErrorContains(t, Load(filepath.Join(t.TempDir(), "missing")), "failed to read template directory")
+}
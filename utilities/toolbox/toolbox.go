@@ -22,3 +22,17 @@ func ArePointersEqual(a, b interface{}) bool {
 
 	return false
 }
+
+func StringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func IntValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -49,3 +49,17 @@ func Test_ArePointersEqual_OneNil(t *testing.T) {
 	var p6 *string
 	assert.False(t, ArePointersEqual(p5, p6), "Expected ArePointersEqual(%v, %v) to be false, but got true", p5, p6)
 }
+
+func Test_StringValue(t *testing.T) {
+	value := "2807 27 ST SW"
+	assert.Equal(t, "2807 27 ST SW", StringValue(&value))
+	assert.Equal(t, "", StringValue(nil))
+}
+
+func Test_IntValue(t *testing.T) {
+	value := 0
+	assert.Equal(t, 0, IntValue(&value))
+	value = 3
+	assert.Equal(t, 3, IntValue(&value))
+	assert.Equal(t, 0, IntValue(nil))
+}
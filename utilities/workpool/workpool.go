@@ -0,0 +1,51 @@
+package workpool
+
+import "sync"
+
+// Group - runs tasks on a bounded number of goroutines, like an errgroup with a limit
+// A failing task does not cancel the others, so each dataset succeeds or fails on its own
+type Group struct {
+	slots  chan struct{}
+	wait   sync.WaitGroup
+	lock   sync.Mutex
+	errors []error
+}
+
+// New - Creates a group that runs at most limit tasks at once, at least one
+func New(limit int) *Group {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Group{slots: make(chan struct{}, limit)}
+}
+
+// Go - Starts a task once a slot is free, blocking until then
+func (g *Group) Go(task func() error) {
+	g.lock.Lock()
+	index := len(g.errors)
+	g.errors = append(g.errors, nil)
+	g.lock.Unlock()
+
+	g.slots <- struct{}{}
+	g.wait.Add(1)
+	go func() {
+		defer func() {
+			<-g.slots
+			g.wait.Done()
+		}()
+
+		err := task()
+		g.lock.Lock()
+		g.errors[index] = err
+		g.lock.Unlock()
+	}()
+}
+
+// Wait - Waits for every task, returns their errors in the order they were started, nil for tasks that succeeded
+func (g *Group) Wait() []error {
+	g.wait.Wait()
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return append([]error(nil), g.errors...)
+}
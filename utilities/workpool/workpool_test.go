@@ -0,0 +1,51 @@
+package workpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Group_LimitsConcurrency(t *testing.T) {
+	group := New(2)
+	var running, most int32
+	for i := 0; i < 6; i++ {
+		group.Go(func() error {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				previous := atomic.LoadInt32(&most)
+				if now <= previous || atomic.CompareAndSwapInt32(&most, previous, now) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+
+	assert.Equal(t, []error{nil, nil, nil, nil, nil, nil}, group.Wait())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&most))
+}
+
+func Test_Group_ErrorsInStartOrder(t *testing.T) {
+	group := New(3)
+	failure := errors.New("failed to fetch")
+	// The first task finishes last, its error still comes first
+	group.Go(func() error {
+		time.Sleep(20 * time.Millisecond)
+		return failure
+	})
+	group.Go(func() error { return nil })
+
+	assert.Equal(t, []error{failure, nil}, group.Wait())
+}
+
+func Test_New_AtLeastOneSlot(t *testing.T) {
+	group := New(0)
+	group.Go(func() error { return nil })
+	assert.Equal(t, []error{nil}, group.Wait())
+}